@@ -30,6 +30,7 @@ import (
 
 	"github.com/golang/glog"
 	apiv1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
@@ -48,6 +49,8 @@ import (
 	operatorConfig "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/scheduledsparkapplication"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/sparkapplication"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/sparkpipeline"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/ttlafterfinished"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/webhook"
@@ -73,6 +76,11 @@ var (
 	leaderElectionLeaseDuration    = flag.Duration("leader-election-lease-duration", 15*time.Second, "Leader election lease duration.")
 	leaderElectionRenewDeadline    = flag.Duration("leader-election-renew-deadline", 14*time.Second, "Leader election renew deadline.")
 	leaderElectionRetryPeriod      = flag.Duration("leader-election-retry-period", 4*time.Second, "Leader election retry period.")
+	submitterType                  = flag.String("submitter", "spark-submit", "How SparkApplications are submitted: \"spark-submit\" runs the spark-submit script, \"native\" creates the driver resources directly through the Kubernetes API.")
+	submissionThreads              = flag.Int("submission-threads", 0, "Number of worker threads submitting SparkApplications separately from the controller threads. Applications are submitted by the controller threads if zero.")
+	submissionTimeout              = flag.Duration("submission-timeout", 0, "Period of time a run of spark-submit may take before it is killed and the submission fails. No timeout applies if zero. Only supported by the spark-submit submitter.")
+	statusUpdateInterval           = flag.Duration("status-update-interval", 0, "Period of time changes to the pods of a SparkApplication are collected for before its status is updated once for all of them. Changes are processed immediately if zero.")
+	defaultTTLSecondsAfterFinished = flag.Int64("default-ttl-seconds-after-finished", -1, "Default number of seconds finished SparkApplications are kept for if they do not set spec.timeToLiveSecondsAfterFinished. They are kept forever if negative.")
 	enableBatchScheduler           = flag.Bool("enable-batch-scheduler", false,
 		fmt.Sprintf("Enable batch schedulers for pods' scheduling, the available batch schedulers are: (%s).", strings.Join(batchscheduler.GetRegisteredNames(), ",")))
 )
@@ -156,7 +164,16 @@ func main() {
 	}
 
 	if *installCRDs {
-		err = crd.CreateOrUpdateCRDs(apiExtensionsClient)
+		// Objects are only converted between versions by the webhook if it is enabled, and v1alpha1 is only served
+		// if they are.
+		var conversion *apiextensionsv1beta1.CustomResourceConversion
+		if *enableWebhook {
+			conversion, err = webhook.GetCRDConversion()
+			if err != nil {
+				glog.Fatal(err)
+			}
+		}
+		err = crd.CreateOrUpdateCRDs(apiExtensionsClient, conversion)
 		if err != nil {
 			glog.Fatal(err)
 		}
@@ -178,10 +195,30 @@ func main() {
 		util.InitializeMetrics(metricConfig)
 	}
 
+	var submitter sparkapplication.Submitter
+	switch *submitterType {
+	case "spark-submit":
+		submitter = sparkapplication.NewSparkSubmitter(*submissionTimeout)
+	case "native":
+		if *submissionTimeout != 0 {
+			glog.Fatal("-submission-timeout only applies to the spark-submit submitter")
+		}
+		submitter = sparkapplication.NewNativeSubmitter(kubeClient)
+	default:
+		glog.Fatalf("unsupported submitter %q", *submitterType)
+	}
+
 	applicationController := sparkapplication.NewController(
-		crClient, kubeClient, crInformerFactory, podInformerFactory, metricConfig, *namespace, *ingressURLFormat, batchSchedulerMgr)
+		crClient, kubeClient, crInformerFactory, podInformerFactory, metricConfig, *namespace, *ingressURLFormat, batchSchedulerMgr, submitter,
+		*submissionThreads, *statusUpdateInterval)
 	scheduledApplicationController := scheduledsparkapplication.NewController(
-		crClient, kubeClient, apiExtensionsClient, crInformerFactory, clock.RealClock{})
+		crClient, kubeClient, apiExtensionsClient, crInformerFactory, metricConfig, clock.RealClock{})
+	pipelineController := sparkpipeline.NewController(crClient, crInformerFactory, clock.RealClock{})
+	var defaultTTL *int64
+	if *defaultTTLSecondsAfterFinished >= 0 {
+		defaultTTL = defaultTTLSecondsAfterFinished
+	}
+	ttlController := ttlafterfinished.NewController(crClient, crInformerFactory, metricConfig, defaultTTL, clock.RealClock{})
 
 	// Start the informer factory that in turn starts the informer.
 	go crInformerFactory.Start(stopCh)
@@ -224,6 +261,12 @@ func main() {
 	if err = scheduledApplicationController.Start(*controllerThreads, stopCh); err != nil {
 		glog.Fatal(err)
 	}
+	if err = pipelineController.Start(*controllerThreads, stopCh); err != nil {
+		glog.Fatal(err)
+	}
+	if err = ttlController.Start(*controllerThreads, stopCh); err != nil {
+		glog.Fatal(err)
+	}
 
 	select {
 	case <-signalCh:
@@ -234,6 +277,8 @@ func main() {
 	glog.Info("Shutting down the Spark Operator")
 	applicationController.Stop()
 	scheduledApplicationController.Stop()
+	pipelineController.Stop()
+	ttlController.Stop()
 	if *enableWebhook {
 		if err := hook.Stop(); err != nil {
 			glog.Fatal(err)
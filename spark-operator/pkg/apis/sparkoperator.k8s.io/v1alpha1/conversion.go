@@ -0,0 +1,489 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// ConversionDataAnnotation is the annotation used to keep the fields of a v1beta1 object that have no v1alpha1
+// equivalent when the object is converted to v1alpha1, so the conversion back to v1beta1 is lossless. The
+// annotation is only set if any of such fields is set, and is removed when the object is converted to v1beta1.
+// The executors, executor failures, memory history, and conditions in the status of a SparkApplication are not
+// kept as they grow with the executors and attempts of the application and could exceed the size limit of the
+// annotations of an object.
+const ConversionDataAnnotation = "sparkoperator.k8s.io/v1beta1-conversion-data"
+
+// sparkApplicationConversionData is what is kept in the ConversionDataAnnotation of a SparkApplication, i.e., its
+// spec and status with the fields that have a v1alpha1 equivalent cleared.
+type sparkApplicationConversionData struct {
+	Spec   v1beta1.SparkApplicationSpec   `json:"spec"`
+	Status v1beta1.SparkApplicationStatus `json:"status"`
+}
+
+// scheduledSparkApplicationConversionData is what is kept in the ConversionDataAnnotation of a
+// ScheduledSparkApplication, i.e., its spec and status with the fields that have a v1alpha1 equivalent cleared.
+type scheduledSparkApplicationConversionData struct {
+	Spec   v1beta1.ScheduledSparkApplicationSpec   `json:"spec"`
+	Status v1beta1.ScheduledSparkApplicationStatus `json:"status"`
+}
+
+// ConvertTo converts the SparkApplication to a v1beta1 SparkApplication. The TypeMeta of dst is left untouched.
+func (src *SparkApplication) ConvertTo(dst *v1beta1.SparkApplication) error {
+	src = src.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.SparkApplicationSpec{}
+	dst.Status = v1beta1.SparkApplicationStatus{}
+
+	if data, ok := popConversionData(&dst.ObjectMeta); ok {
+		restored := &sparkApplicationConversionData{}
+		if err := json.Unmarshal([]byte(data), restored); err != nil {
+			return fmt.Errorf("failed to unmarshal annotation %s of SparkApplication %s/%s: %v",
+				ConversionDataAnnotation, src.Namespace, src.Name, err)
+		}
+		dst.Spec = restored.Spec
+		dst.Status = restored.Status
+	}
+
+	convertSparkApplicationSpecToV1beta1(&src.Spec, &dst.Spec)
+	convertSparkApplicationStatusToV1beta1(&src.Status, &dst.Status)
+	return nil
+}
+
+// ConvertFrom converts a v1beta1 SparkApplication to the SparkApplication. The TypeMeta of dst is left untouched.
+func (dst *SparkApplication) ConvertFrom(src *v1beta1.SparkApplication) error {
+	src = src.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = SparkApplicationSpec{}
+	dst.Status = SparkApplicationStatus{}
+	convertSparkApplicationSpecFromV1beta1(&src.Spec, &dst.Spec)
+	convertSparkApplicationStatusFromV1beta1(&src.Status, &dst.Status)
+
+	conversionData := &sparkApplicationConversionData{Spec: src.Spec, Status: src.Status}
+	clearSparkApplicationSpec(&conversionData.Spec)
+	clearSparkApplicationStatus(&conversionData.Status)
+	if equality.Semantic.DeepEqual(conversionData, &sparkApplicationConversionData{}) {
+		return nil
+	}
+
+	data, err := json.Marshal(conversionData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversion data of SparkApplication %s/%s: %v", src.Namespace, src.Name, err)
+	}
+	setConversionData(&dst.ObjectMeta, string(data))
+	return nil
+}
+
+// ConvertTo converts the ScheduledSparkApplication to a v1beta1 ScheduledSparkApplication. The TypeMeta of dst
+// is left untouched.
+func (src *ScheduledSparkApplication) ConvertTo(dst *v1beta1.ScheduledSparkApplication) error {
+	src = src.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.ScheduledSparkApplicationSpec{}
+	dst.Status = v1beta1.ScheduledSparkApplicationStatus{}
+
+	if data, ok := popConversionData(&dst.ObjectMeta); ok {
+		restored := &scheduledSparkApplicationConversionData{}
+		if err := json.Unmarshal([]byte(data), restored); err != nil {
+			return fmt.Errorf("failed to unmarshal annotation %s of ScheduledSparkApplication %s/%s: %v",
+				ConversionDataAnnotation, src.Namespace, src.Name, err)
+		}
+		dst.Spec = restored.Spec
+		dst.Status = restored.Status
+	}
+
+	convertScheduledSparkApplicationSpecToV1beta1(&src.Spec, &dst.Spec)
+	convertScheduledSparkApplicationStatusToV1beta1(&src.Status, &dst.Status)
+	return nil
+}
+
+// ConvertFrom converts a v1beta1 ScheduledSparkApplication to the ScheduledSparkApplication. The TypeMeta of dst
+// is left untouched.
+func (dst *ScheduledSparkApplication) ConvertFrom(src *v1beta1.ScheduledSparkApplication) error {
+	src = src.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = ScheduledSparkApplicationSpec{}
+	dst.Status = ScheduledSparkApplicationStatus{}
+	convertScheduledSparkApplicationSpecFromV1beta1(&src.Spec, &dst.Spec)
+	convertScheduledSparkApplicationStatusFromV1beta1(&src.Status, &dst.Status)
+
+	conversionData := &scheduledSparkApplicationConversionData{Spec: src.Spec, Status: src.Status}
+	clearScheduledSparkApplicationSpec(&conversionData.Spec)
+	convertScheduledSparkApplicationStatusToV1beta1(&ScheduledSparkApplicationStatus{}, &conversionData.Status)
+	if equality.Semantic.DeepEqual(conversionData, &scheduledSparkApplicationConversionData{}) {
+		return nil
+	}
+
+	data, err := json.Marshal(conversionData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversion data of ScheduledSparkApplication %s/%s: %v",
+			src.Namespace, src.Name, err)
+	}
+	setConversionData(&dst.ObjectMeta, string(data))
+	return nil
+}
+
+// clearScheduledSparkApplicationSpec clears the fields of the spec that have a v1alpha1 equivalent.
+func clearScheduledSparkApplicationSpec(spec *v1beta1.ScheduledSparkApplicationSpec) {
+	template := spec.Template
+	convertScheduledSparkApplicationSpecToV1beta1(&ScheduledSparkApplicationSpec{}, spec)
+	spec.Template = template
+	clearSparkApplicationSpec(&spec.Template)
+}
+
+// clearSparkApplicationSpec clears the fields of the spec that have a v1alpha1 equivalent. The monitoring
+// specification is only kept if it has fields without a v1alpha1 equivalent.
+func clearSparkApplicationSpec(spec *v1beta1.SparkApplicationSpec) {
+	empty := &SparkApplicationSpec{}
+	if spec.Monitoring != nil {
+		empty.Monitoring = &MonitoringSpec{}
+		if spec.Monitoring.Prometheus != nil {
+			empty.Monitoring.Prometheus = &PrometheusSpec{}
+		}
+	}
+	convertSparkApplicationSpecToV1beta1(empty, spec)
+	if spec.Monitoring == nil {
+		return
+	}
+	prometheus := spec.Monitoring.Prometheus
+	if prometheus != nil && equality.Semantic.DeepEqual(prometheus, &v1beta1.PrometheusSpec{}) {
+		spec.Monitoring.Prometheus = nil
+	}
+	if equality.Semantic.DeepEqual(spec.Monitoring, &v1beta1.MonitoringSpec{}) {
+		spec.Monitoring = nil
+	}
+}
+
+// clearSparkApplicationStatus clears the fields of the status that have a v1alpha1 equivalent, along with the
+// fields growing with the executors and attempts of the application.
+func clearSparkApplicationStatus(status *v1beta1.SparkApplicationStatus) {
+	convertSparkApplicationStatusToV1beta1(&SparkApplicationStatus{}, status)
+	status.Executors = nil
+	status.OmittedExecutors = 0
+	status.ExecutorFailures = nil
+	status.MemoryHistory = nil
+	status.Conditions = nil
+}
+
+func popConversionData(meta *metav1.ObjectMeta) (string, bool) {
+	data, ok := meta.Annotations[ConversionDataAnnotation]
+	if !ok {
+		return "", false
+	}
+	delete(meta.Annotations, ConversionDataAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	return data, true
+}
+
+func setConversionData(meta *metav1.ObjectMeta, data string) {
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[ConversionDataAnnotation] = data
+}
+
+// The functions below convert the fields that exist in both versions. The functions converting to v1beta1 set
+// every such field and leave the fields that only exist in v1beta1 untouched, so converting an empty v1alpha1 object
+// clears the fields of a v1beta1 object that have a v1alpha1 equivalent.
+
+func convertScheduledSparkApplicationSpecToV1beta1(in *ScheduledSparkApplicationSpec, out *v1beta1.ScheduledSparkApplicationSpec) {
+	out.Schedule = in.Schedule
+	convertSparkApplicationSpecToV1beta1(&in.Template, &out.Template)
+	out.Suspend = in.Suspend
+	out.ConcurrencyPolicy = v1beta1.ConcurrencyPolicy(in.ConcurrencyPolicy)
+	out.SuccessfulRunHistoryLimit = in.SuccessfulRunHistoryLimit
+	out.FailedRunHistoryLimit = in.FailedRunHistoryLimit
+}
+
+func convertScheduledSparkApplicationSpecFromV1beta1(in *v1beta1.ScheduledSparkApplicationSpec, out *ScheduledSparkApplicationSpec) {
+	out.Schedule = in.Schedule
+	convertSparkApplicationSpecFromV1beta1(&in.Template, &out.Template)
+	out.Suspend = in.Suspend
+	out.ConcurrencyPolicy = ConcurrencyPolicy(in.ConcurrencyPolicy)
+	out.SuccessfulRunHistoryLimit = in.SuccessfulRunHistoryLimit
+	out.FailedRunHistoryLimit = in.FailedRunHistoryLimit
+}
+
+func convertScheduledSparkApplicationStatusToV1beta1(in *ScheduledSparkApplicationStatus, out *v1beta1.ScheduledSparkApplicationStatus) {
+	out.LastRun = in.LastRun
+	out.NextRun = in.NextRun
+	out.LastRunName = in.LastRunName
+	out.PastSuccessfulRunNames = in.PastSuccessfulRunNames
+	out.PastFailedRunNames = in.PastFailedRunNames
+	out.ScheduleState = v1beta1.ScheduleState(in.ScheduleState)
+	out.Reason = in.Reason
+}
+
+func convertScheduledSparkApplicationStatusFromV1beta1(in *v1beta1.ScheduledSparkApplicationStatus, out *ScheduledSparkApplicationStatus) {
+	out.LastRun = in.LastRun
+	out.NextRun = in.NextRun
+	out.LastRunName = in.LastRunName
+	out.PastSuccessfulRunNames = in.PastSuccessfulRunNames
+	out.PastFailedRunNames = in.PastFailedRunNames
+	out.ScheduleState = ScheduleState(in.ScheduleState)
+	out.Reason = in.Reason
+}
+
+func convertSparkApplicationSpecToV1beta1(in *SparkApplicationSpec, out *v1beta1.SparkApplicationSpec) {
+	out.Type = v1beta1.SparkApplicationType(in.Type)
+	out.Mode = v1beta1.DeployMode(in.Mode)
+	out.Image = in.Image
+	out.InitContainerImage = in.InitContainerImage
+	out.ImagePullPolicy = in.ImagePullPolicy
+	out.ImagePullSecrets = in.ImagePullSecrets
+	out.MainClass = in.MainClass
+	out.MainApplicationFile = in.MainApplicationFile
+	out.Arguments = in.Arguments
+	out.SparkConf = in.SparkConf
+	out.HadoopConf = in.HadoopConf
+	out.SparkConfigMap = in.SparkConfigMap
+	out.HadoopConfigMap = in.HadoopConfigMap
+	out.Volumes = in.Volumes
+	convertSparkPodSpecToV1beta1(&in.Driver.SparkPodSpec, &out.Driver.SparkPodSpec)
+	out.Driver.PodName = in.Driver.PodName
+	out.Driver.ServiceAccount = in.Driver.ServiceAccount
+	out.Driver.JavaOptions = in.Driver.JavaOptions
+	convertSparkPodSpecToV1beta1(&in.Executor.SparkPodSpec, &out.Executor.SparkPodSpec)
+	out.Executor.Instances = in.Executor.Instances
+	out.Executor.CoreRequest = in.Executor.CoreRequest
+	out.Executor.JavaOptions = in.Executor.JavaOptions
+	out.Deps.Jars = in.Deps.Jars
+	out.Deps.Files = in.Deps.Files
+	out.Deps.PyFiles = in.Deps.PyFiles
+	out.Deps.JarsDownloadDir = in.Deps.JarsDownloadDir
+	out.Deps.FilesDownloadDir = in.Deps.FilesDownloadDir
+	out.Deps.DownloadTimeout = in.Deps.DownloadTimeout
+	out.Deps.MaxSimultaneousDownloads = in.Deps.MaxSimultaneousDownloads
+	out.RestartPolicy.Type = v1beta1.RestartPolicyType(in.RestartPolicy.Type)
+	out.RestartPolicy.OnSubmissionFailureRetries = in.RestartPolicy.OnSubmissionFailureRetries
+	out.RestartPolicy.OnFailureRetries = in.RestartPolicy.OnFailureRetries
+	out.RestartPolicy.OnSubmissionFailureRetryInterval = in.RestartPolicy.OnSubmissionFailureRetryInterval
+	out.RestartPolicy.OnFailureRetryInterval = in.RestartPolicy.OnFailureRetryInterval
+	out.NodeSelector = in.NodeSelector
+	out.PythonVersion = in.PythonVersion
+	out.MemoryOverheadFactor = in.MemoryOverheadFactor
+
+	if in.Monitoring == nil {
+		out.Monitoring = nil
+		return
+	}
+	if out.Monitoring == nil {
+		out.Monitoring = &v1beta1.MonitoringSpec{}
+	}
+	out.Monitoring.ExposeDriverMetrics = in.Monitoring.ExposeDriverMetrics
+	out.Monitoring.ExposeExecutorMetrics = in.Monitoring.ExposeExecutorMetrics
+	out.Monitoring.MetricsProperties = in.Monitoring.MetricsProperties
+	if in.Monitoring.Prometheus == nil {
+		out.Monitoring.Prometheus = nil
+		return
+	}
+	if out.Monitoring.Prometheus == nil {
+		out.Monitoring.Prometheus = &v1beta1.PrometheusSpec{}
+	}
+	out.Monitoring.Prometheus.JmxExporterJar = in.Monitoring.Prometheus.JmxExporterJar
+	out.Monitoring.Prometheus.Port = in.Monitoring.Prometheus.Port
+	out.Monitoring.Prometheus.Configuration = in.Monitoring.Prometheus.Configuration
+}
+
+func convertSparkApplicationSpecFromV1beta1(in *v1beta1.SparkApplicationSpec, out *SparkApplicationSpec) {
+	out.Type = SparkApplicationType(in.Type)
+	out.Mode = DeployMode(in.Mode)
+	out.Image = in.Image
+	out.InitContainerImage = in.InitContainerImage
+	out.ImagePullPolicy = in.ImagePullPolicy
+	out.ImagePullSecrets = in.ImagePullSecrets
+	out.MainClass = in.MainClass
+	out.MainApplicationFile = in.MainApplicationFile
+	out.Arguments = in.Arguments
+	out.SparkConf = in.SparkConf
+	out.HadoopConf = in.HadoopConf
+	out.SparkConfigMap = in.SparkConfigMap
+	out.HadoopConfigMap = in.HadoopConfigMap
+	out.Volumes = in.Volumes
+	convertSparkPodSpecFromV1beta1(&in.Driver.SparkPodSpec, &out.Driver.SparkPodSpec)
+	out.Driver.PodName = in.Driver.PodName
+	out.Driver.ServiceAccount = in.Driver.ServiceAccount
+	out.Driver.JavaOptions = in.Driver.JavaOptions
+	convertSparkPodSpecFromV1beta1(&in.Executor.SparkPodSpec, &out.Executor.SparkPodSpec)
+	out.Executor.Instances = in.Executor.Instances
+	out.Executor.CoreRequest = in.Executor.CoreRequest
+	out.Executor.JavaOptions = in.Executor.JavaOptions
+	out.Deps.Jars = in.Deps.Jars
+	out.Deps.Files = in.Deps.Files
+	out.Deps.PyFiles = in.Deps.PyFiles
+	out.Deps.JarsDownloadDir = in.Deps.JarsDownloadDir
+	out.Deps.FilesDownloadDir = in.Deps.FilesDownloadDir
+	out.Deps.DownloadTimeout = in.Deps.DownloadTimeout
+	out.Deps.MaxSimultaneousDownloads = in.Deps.MaxSimultaneousDownloads
+	out.RestartPolicy.Type = RestartPolicyType(in.RestartPolicy.Type)
+	out.RestartPolicy.OnSubmissionFailureRetries = in.RestartPolicy.OnSubmissionFailureRetries
+	out.RestartPolicy.OnFailureRetries = in.RestartPolicy.OnFailureRetries
+	out.RestartPolicy.OnSubmissionFailureRetryInterval = in.RestartPolicy.OnSubmissionFailureRetryInterval
+	out.RestartPolicy.OnFailureRetryInterval = in.RestartPolicy.OnFailureRetryInterval
+	out.NodeSelector = in.NodeSelector
+	out.PythonVersion = in.PythonVersion
+	out.MemoryOverheadFactor = in.MemoryOverheadFactor
+
+	if in.Monitoring == nil {
+		out.Monitoring = nil
+		return
+	}
+	out.Monitoring = &MonitoringSpec{
+		ExposeDriverMetrics:   in.Monitoring.ExposeDriverMetrics,
+		ExposeExecutorMetrics: in.Monitoring.ExposeExecutorMetrics,
+		MetricsProperties:     in.Monitoring.MetricsProperties,
+	}
+	if in.Monitoring.Prometheus != nil {
+		out.Monitoring.Prometheus = &PrometheusSpec{
+			JmxExporterJar: in.Monitoring.Prometheus.JmxExporterJar,
+			Port:           in.Monitoring.Prometheus.Port,
+			Configuration:  in.Monitoring.Prometheus.Configuration,
+		}
+	}
+}
+
+func convertSparkPodSpecToV1beta1(in *SparkPodSpec, out *v1beta1.SparkPodSpec) {
+	out.Cores = in.Cores
+	out.CoreLimit = in.CoreLimit
+	out.Memory = in.Memory
+	out.MemoryOverhead = in.MemoryOverhead
+	out.Image = in.Image
+	out.ConfigMaps = nil
+	if in.ConfigMaps != nil {
+		out.ConfigMaps = make([]v1beta1.NamePath, 0, len(in.ConfigMaps))
+		for _, configMap := range in.ConfigMaps {
+			out.ConfigMaps = append(out.ConfigMaps, v1beta1.NamePath{Name: configMap.Name, Path: configMap.Path})
+		}
+	}
+	out.Secrets = nil
+	if in.Secrets != nil {
+		out.Secrets = make([]v1beta1.SecretInfo, 0, len(in.Secrets))
+		for _, secret := range in.Secrets {
+			out.Secrets = append(out.Secrets, v1beta1.SecretInfo{
+				Name: secret.Name,
+				Path: secret.Path,
+				Type: v1beta1.SecretType(secret.Type),
+			})
+		}
+	}
+	out.EnvVars = in.EnvVars
+	out.EnvSecretKeyRefs = nil
+	if in.EnvSecretKeyRefs != nil {
+		out.EnvSecretKeyRefs = make(map[string]v1beta1.NameKey, len(in.EnvSecretKeyRefs))
+		for name, ref := range in.EnvSecretKeyRefs {
+			out.EnvSecretKeyRefs[name] = v1beta1.NameKey{Name: ref.Name, Key: ref.Key}
+		}
+	}
+	out.Labels = in.Labels
+	out.Annotations = in.Annotations
+	out.VolumeMounts = in.VolumeMounts
+	out.Affinity = in.Affinity
+	out.Tolerations = in.Tolerations
+}
+
+func convertSparkPodSpecFromV1beta1(in *v1beta1.SparkPodSpec, out *SparkPodSpec) {
+	out.Cores = in.Cores
+	out.CoreLimit = in.CoreLimit
+	out.Memory = in.Memory
+	out.MemoryOverhead = in.MemoryOverhead
+	out.Image = in.Image
+	out.ConfigMaps = nil
+	if in.ConfigMaps != nil {
+		out.ConfigMaps = make([]NamePath, 0, len(in.ConfigMaps))
+		for _, configMap := range in.ConfigMaps {
+			out.ConfigMaps = append(out.ConfigMaps, NamePath{Name: configMap.Name, Path: configMap.Path})
+		}
+	}
+	out.Secrets = nil
+	if in.Secrets != nil {
+		out.Secrets = make([]SecretInfo, 0, len(in.Secrets))
+		for _, secret := range in.Secrets {
+			out.Secrets = append(out.Secrets, SecretInfo{
+				Name: secret.Name,
+				Path: secret.Path,
+				Type: SecretType(secret.Type),
+			})
+		}
+	}
+	out.EnvVars = in.EnvVars
+	out.EnvSecretKeyRefs = nil
+	if in.EnvSecretKeyRefs != nil {
+		out.EnvSecretKeyRefs = make(map[string]NameKey, len(in.EnvSecretKeyRefs))
+		for name, ref := range in.EnvSecretKeyRefs {
+			out.EnvSecretKeyRefs[name] = NameKey{Name: ref.Name, Key: ref.Key}
+		}
+	}
+	out.Labels = in.Labels
+	out.Annotations = in.Annotations
+	out.VolumeMounts = in.VolumeMounts
+	out.Affinity = in.Affinity
+	out.Tolerations = in.Tolerations
+}
+
+func convertSparkApplicationStatusToV1beta1(in *SparkApplicationStatus, out *v1beta1.SparkApplicationStatus) {
+	out.SparkApplicationID = in.SparkApplicationID
+	out.LastSubmissionAttemptTime = in.LastSubmissionAttemptTime
+	out.TerminationTime = in.TerminationTime
+	out.DriverInfo.WebUIServiceName = in.DriverInfo.WebUIServiceName
+	out.DriverInfo.WebUIPort = in.DriverInfo.WebUIPort
+	out.DriverInfo.WebUIAddress = in.DriverInfo.WebUIAddress
+	out.DriverInfo.WebUIIngressName = in.DriverInfo.WebUIIngressName
+	out.DriverInfo.WebUIIngressAddress = in.DriverInfo.WebUIIngressAddress
+	out.DriverInfo.PodName = in.DriverInfo.PodName
+	out.AppState.State = v1beta1.ApplicationStateType(in.AppState.State)
+	out.AppState.ErrorMessage = in.AppState.ErrorMessage
+	out.ExecutorState = nil
+	if in.ExecutorState != nil {
+		out.ExecutorState = make(map[string]v1beta1.ExecutorState, len(in.ExecutorState))
+		for name, state := range in.ExecutorState {
+			out.ExecutorState[name] = v1beta1.ExecutorState(state)
+		}
+	}
+	out.ExecutionAttempts = in.ExecutionAttempts
+	out.SubmissionAttempts = in.SubmissionAttempts
+}
+
+func convertSparkApplicationStatusFromV1beta1(in *v1beta1.SparkApplicationStatus, out *SparkApplicationStatus) {
+	out.SparkApplicationID = in.SparkApplicationID
+	out.LastSubmissionAttemptTime = in.LastSubmissionAttemptTime
+	out.TerminationTime = in.TerminationTime
+	out.DriverInfo.WebUIServiceName = in.DriverInfo.WebUIServiceName
+	out.DriverInfo.WebUIPort = in.DriverInfo.WebUIPort
+	out.DriverInfo.WebUIAddress = in.DriverInfo.WebUIAddress
+	out.DriverInfo.WebUIIngressName = in.DriverInfo.WebUIIngressName
+	out.DriverInfo.WebUIIngressAddress = in.DriverInfo.WebUIIngressAddress
+	out.DriverInfo.PodName = in.DriverInfo.PodName
+	out.AppState.State = ApplicationStateType(in.AppState.State)
+	out.AppState.ErrorMessage = in.AppState.ErrorMessage
+	out.ExecutorState = nil
+	if in.ExecutorState != nil {
+		out.ExecutorState = make(map[string]ExecutorState, len(in.ExecutorState))
+		for name, state := range in.ExecutorState {
+			out.ExecutorState[name] = ExecutorState(state)
+		}
+	}
+	out.ExecutionAttempts = in.ExecutionAttempts
+	out.SubmissionAttempts = in.SubmissionAttempts
+}
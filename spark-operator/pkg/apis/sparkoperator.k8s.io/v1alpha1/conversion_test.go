@@ -0,0 +1,285 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const fuzzIterations = 200
+
+// newFuzzer returns a fuzzer generating values that survive a JSON round trip, which is what the conversion
+// data annotation relies on.
+func newFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.New().NilChance(0.3).NumElements(0, 2).RandSource(rand.NewSource(seed)).Funcs(
+		func(t *metav1.TypeMeta, c fuzz.Continue) {
+			// Conversions leave the TypeMeta untouched.
+		},
+		func(t *metav1.Time, c fuzz.Continue) {
+			// Times are serialized with a precision of seconds.
+			*t = metav1.Unix(c.Int63n(1<<32), 0)
+		},
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewQuantity(c.Int63n(1000), resource.DecimalSI)
+		},
+		func(i *intstr.IntOrString, c fuzz.Continue) {
+			if c.RandBool() {
+				*i = intstr.FromInt(c.Intn(1000))
+			} else {
+				*i = intstr.FromString(c.RandString())
+			}
+		},
+	)
+}
+
+func TestSparkApplicationRoundTrip(t *testing.T) {
+	seed := time.Now().UnixNano()
+	f := newFuzzer(seed)
+
+	for i := 0; i < fuzzIterations; i++ {
+		original := &v1beta1.SparkApplication{}
+		f.Fuzz(original)
+		alpha := &SparkApplication{}
+		if err := alpha.ConvertFrom(original); err != nil {
+			t.Fatalf("seed %d: failed to convert from v1beta1: %v", seed, err)
+		}
+		converted := &v1beta1.SparkApplication{}
+		if err := alpha.ConvertTo(converted); err != nil {
+			t.Fatalf("seed %d: failed to convert to v1beta1: %v", seed, err)
+		}
+		// The status fields growing with the executors and attempts are not kept.
+		original.Status.Executors = nil
+		original.Status.OmittedExecutors = 0
+		original.Status.ExecutorFailures = nil
+		original.Status.MemoryHistory = nil
+		original.Status.Conditions = nil
+		if !equality.Semantic.DeepEqual(original, converted) {
+			t.Fatalf("seed %d: v1beta1 round trip changed the object: %s", seed, diff.ObjectReflectDiff(original, converted))
+		}
+	}
+
+	for i := 0; i < fuzzIterations; i++ {
+		original := &SparkApplication{}
+		f.Fuzz(original)
+		delete(original.Annotations, ConversionDataAnnotation)
+		beta := &v1beta1.SparkApplication{}
+		if err := original.ConvertTo(beta); err != nil {
+			t.Fatalf("seed %d: failed to convert to v1beta1: %v", seed, err)
+		}
+		converted := &SparkApplication{}
+		if err := converted.ConvertFrom(beta); err != nil {
+			t.Fatalf("seed %d: failed to convert from v1beta1: %v", seed, err)
+		}
+		if !equality.Semantic.DeepEqual(original, converted) {
+			t.Fatalf("seed %d: v1alpha1 round trip changed the object: %s", seed, diff.ObjectReflectDiff(original, converted))
+		}
+	}
+}
+
+func TestScheduledSparkApplicationRoundTrip(t *testing.T) {
+	seed := time.Now().UnixNano()
+	f := newFuzzer(seed)
+
+	for i := 0; i < fuzzIterations; i++ {
+		original := &v1beta1.ScheduledSparkApplication{}
+		f.Fuzz(original)
+		alpha := &ScheduledSparkApplication{}
+		if err := alpha.ConvertFrom(original); err != nil {
+			t.Fatalf("seed %d: failed to convert from v1beta1: %v", seed, err)
+		}
+		converted := &v1beta1.ScheduledSparkApplication{}
+		if err := alpha.ConvertTo(converted); err != nil {
+			t.Fatalf("seed %d: failed to convert to v1beta1: %v", seed, err)
+		}
+		if !equality.Semantic.DeepEqual(original, converted) {
+			t.Fatalf("seed %d: v1beta1 round trip changed the object: %s", seed, diff.ObjectReflectDiff(original, converted))
+		}
+	}
+
+	for i := 0; i < fuzzIterations; i++ {
+		original := &ScheduledSparkApplication{}
+		f.Fuzz(original)
+		delete(original.Annotations, ConversionDataAnnotation)
+		beta := &v1beta1.ScheduledSparkApplication{}
+		if err := original.ConvertTo(beta); err != nil {
+			t.Fatalf("seed %d: failed to convert to v1beta1: %v", seed, err)
+		}
+		converted := &ScheduledSparkApplication{}
+		if err := converted.ConvertFrom(beta); err != nil {
+			t.Fatalf("seed %d: failed to convert from v1beta1: %v", seed, err)
+		}
+		if !equality.Semantic.DeepEqual(original, converted) {
+			t.Fatalf("seed %d: v1alpha1 round trip changed the object: %s", seed, diff.ObjectReflectDiff(original, converted))
+		}
+	}
+}
+
+func TestSparkApplicationConversionDataAnnotation(t *testing.T) {
+	beta := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Type:         v1beta1.ScalaApplicationType,
+			SparkVersion: "2.4.0",
+		},
+	}
+	alpha := &SparkApplication{}
+	if err := alpha.ConvertFrom(beta); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := alpha.Annotations[ConversionDataAnnotation]; !ok {
+		t.Errorf("expected annotation %s to be set", ConversionDataAnnotation)
+	}
+
+	beta.Spec.SparkVersion = ""
+	alpha = &SparkApplication{}
+	if err := alpha.ConvertFrom(beta); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := alpha.Annotations[ConversionDataAnnotation]; ok {
+		t.Errorf("expected annotation %s not to be set", ConversionDataAnnotation)
+	}
+}
+
+func TestSparkApplicationConversionDataAnnotationSize(t *testing.T) {
+	beta := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Type:         v1beta1.ScalaApplicationType,
+			SparkVersion: "2.4.0",
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:          v1beta1.ApplicationState{State: v1beta1.RunningState},
+			Executors:         make(map[string]v1beta1.ExecutorInfo),
+			ExecutorState:     make(map[string]v1beta1.ExecutorState),
+			OmittedExecutors:  10,
+			ExecutionAttempts: 10,
+		},
+	}
+	for i := 0; i < 200; i++ {
+		name := fmt.Sprintf("foo-1556676000000-exec-%d", i)
+		beta.Status.ExecutorState[name] = v1beta1.ExecutorRunningState
+		beta.Status.Executors[name] = v1beta1.ExecutorInfo{
+			ExecutorID: fmt.Sprintf("%d", i),
+			State:      v1beta1.ExecutorRunningState,
+			NodeName:   fmt.Sprintf("node-%d", i),
+			PodIP:      fmt.Sprintf("10.0.0.%d", i),
+			StartTime:  metav1.Unix(1556676000, 0),
+		}
+		beta.Status.ExecutorFailures = append(beta.Status.ExecutorFailures, v1beta1.ExecutorFailure{
+			PodName: name,
+			Time:    metav1.Unix(1556676000, 0),
+			Reason:  "OOMKilled",
+		})
+	}
+	for i := int32(1); i <= 10; i++ {
+		beta.Status.MemoryHistory = append(beta.Status.MemoryHistory,
+			v1beta1.AttemptMemory{Attempt: i, DriverMemory: "1g", ExecutorMemory: "4g"})
+		beta.Status.Conditions = append(beta.Status.Conditions, v1beta1.SparkApplicationCondition{
+			Type:    v1beta1.SparkApplicationConditionType(fmt.Sprintf("Condition%d", i)),
+			Status:  "True",
+			Message: "the application is running",
+		})
+	}
+
+	alpha := &SparkApplication{}
+	if err := alpha.ConvertFrom(beta); err != nil {
+		t.Fatal(err)
+	}
+	// Only the fields without a v1alpha1 equivalent are kept, so the annotation does not grow with the executors.
+	data, ok := alpha.Annotations[ConversionDataAnnotation]
+	if !ok {
+		t.Fatalf("expected annotation %s to be set", ConversionDataAnnotation)
+	}
+	if len(data) > 1024 {
+		t.Errorf("expected annotation %s to be at most 1024 bytes, got %d bytes: %s",
+			ConversionDataAnnotation, len(data), data)
+	}
+
+	converted := &v1beta1.SparkApplication{}
+	if err := alpha.ConvertTo(converted); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(beta.Spec, converted.Spec) {
+		t.Errorf("round trip changed the spec: %s", diff.ObjectReflectDiff(beta.Spec, converted.Spec))
+	}
+	if !equality.Semantic.DeepEqual(beta.Status.ExecutorState, converted.Status.ExecutorState) {
+		t.Errorf("round trip changed the executor state: %s",
+			diff.ObjectReflectDiff(beta.Status.ExecutorState, converted.Status.ExecutorState))
+	}
+}
+
+func TestScheduledSparkApplicationConversionDataAnnotation(t *testing.T) {
+	beta := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       v1beta1.ScheduledSparkApplicationSpec{Schedule: "@every 1h"},
+		Status: v1beta1.ScheduledSparkApplicationStatus{
+			LastRunName:          "foo-1",
+			LastRunScheduledTime: metav1.Unix(1556676000, 0),
+			MissedRuns: []v1beta1.MissedRun{
+				{ScheduledTime: metav1.Unix(1556672400, 0), Reason: v1beta1.MissedRunDeadlineExceeded},
+			},
+			MissedRunCount: 3,
+			Backfill: &v1beta1.BackfillStatus{
+				StartTime:      metav1.Unix(1556589600, 0),
+				EndTime:        metav1.Unix(1556676000, 0),
+				State:          v1beta1.BackfillRunningState,
+				TotalRuns:      2,
+				CreatedRuns:    1,
+				NextRunTime:    metav1.Unix(1556676000, 0),
+				ActiveRunNames: []string{"foo-backfill-1556589600-1556676100"},
+			},
+		},
+	}
+	alpha := &ScheduledSparkApplication{}
+	if err := alpha.ConvertFrom(beta); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := alpha.Annotations[ConversionDataAnnotation]; !ok {
+		t.Errorf("expected annotation %s to be set", ConversionDataAnnotation)
+	}
+	converted := &v1beta1.ScheduledSparkApplication{}
+	if err := alpha.ConvertTo(converted); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(beta.Status, converted.Status) {
+		t.Errorf("round trip changed the status: %s", diff.ObjectReflectDiff(beta.Status, converted.Status))
+	}
+
+	beta.Status.LastRunScheduledTime = metav1.Time{}
+	beta.Status.MissedRuns = nil
+	beta.Status.MissedRunCount = 0
+	beta.Status.Backfill = nil
+	alpha = &ScheduledSparkApplication{}
+	if err := alpha.ConvertFrom(beta); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := alpha.Annotations[ConversionDataAnnotation]; ok {
+		t.Errorf("expected annotation %s not to be set", ConversionDataAnnotation)
+	}
+}
@@ -56,8 +56,92 @@ type RestartPolicy struct {
 	// Interval to wait between successive retries of a failed application.
 	OnSubmissionFailureRetryInterval *int64 `json:"onSubmissionFailureRetryInterval,omitempty"`
 	OnFailureRetryInterval           *int64 `json:"onFailureRetryInterval,omitempty"`
+
+	// Backoff configures an exponential backoff between successive retries.
+	// If set, it takes precedence over the linear back-off of the retry intervals above.
+	// Optional.
+	Backoff *BackoffPolicy `json:"backoff,omitempty"`
+	// FailureRules decide how to handle a failed application based on how its driver terminated.
+	// The first rule matching the termination of the driver applies. Failures matching no rule are handled
+	// according to Type.
+	// Optional.
+	FailureRules []FailureRule `json:"failureRules,omitempty"`
+	// MemoryIncreaseOnOOM increases the memory of the driver or executors for the next attempt of a failed
+	// application if they were OOMKilled.
+	// Optional.
+	MemoryIncreaseOnOOM *MemoryIncreasePolicy `json:"memoryIncreaseOnOOM,omitempty"`
+}
+
+// MemoryIncreasePolicy configures how memory is increased for retries after OOMs.
+type MemoryIncreasePolicy struct {
+	// Factor is the factor the memory of the failed attempt is multiplied by.
+	Factor float64 `json:"factor"`
+	// MaxMemory caps the increased memory, e.g., 8g. It also caps increases by RetryWithMoreMemory failure rules.
+	// Optional.
+	MaxMemory *string `json:"maxMemory,omitempty"`
+}
+
+// BackoffPolicy configures an exponential backoff between retries of a failed application.
+type BackoffPolicy struct {
+	// InitialIntervalSeconds is the interval to wait before the first retry.
+	InitialIntervalSeconds int64 `json:"initialIntervalSeconds"`
+	// Multiplier is the factor the interval is multiplied by with each additional attempt.
+	// Optional. Defaults to 2.
+	Multiplier *float64 `json:"multiplier,omitempty"`
+	// MaxIntervalSeconds caps the interval between retries.
+	// Optional. Defaults to one day.
+	MaxIntervalSeconds *int64 `json:"maxIntervalSeconds,omitempty"`
+	// JitterFactor randomizes each interval by up to the given fraction of it, e.g., 0.1 for +/-10%.
+	// Optional.
+	JitterFactor *float64 `json:"jitterFactor,omitempty"`
+}
+
+// FailureRule maps the termination of the driver to the action to take.
+// A rule matches if both its reasons and exit codes match, where an empty list matches anything.
+type FailureRule struct {
+	// Reasons are the termination reasons of the driver container or pod the rule matches, e.g., OOMKilled,
+	// Evicted, or DeadlineExceeded.
+	// Optional.
+	Reasons []string `json:"reasons,omitempty"`
+	// ExitCodes are the ranges of exit codes of the driver container the rule matches.
+	// Optional.
+	ExitCodes []ExitCodeRange `json:"exitCodes,omitempty"`
+	// Action is the action to take on failures matching the rule.
+	Action FailureAction `json:"action"`
+	// MemoryIncreaseFactor is the factor the driver memory is multiplied by for the RetryWithMoreMemory action.
+	// Optional. Defaults to 1.5.
+	MemoryIncreaseFactor *float64 `json:"memoryIncreaseFactor,omitempty"`
 }
 
+// ExitCodeRange is an inclusive range of exit codes.
+type ExitCodeRange struct {
+	Min int32 `json:"min"`
+	// Max defaults to Min.
+	// Optional.
+	Max *int32 `json:"max,omitempty"`
+}
+
+// FailureAction is the action to take on a failure matching a FailureRule.
+type FailureAction string
+
+// Different actions to take on failures.
+// Retries are subject to OnFailureRetries and the retry interval or backoff, regardless of the restart policy type.
+const (
+	FailureActionRetry               FailureAction = "Retry"
+	FailureActionNeverRetry          FailureAction = "NeverRetry"
+	FailureActionRetryWithMoreMemory FailureAction = "RetryWithMoreMemory"
+)
+
+// Termination reasons of application attempts running into the deadlines in their spec. Failure rules match them
+// like the termination reasons of the driver.
+const (
+	PendingDeadlineExceededReason = "PendingDeadlineExceeded"
+	ActiveDeadlineExceededReason  = "ActiveDeadlineExceeded"
+	// ExecutorFailureBudgetExhaustedReason is the termination reason of application attempts failed because more
+	// executors failed than their executor failure budget allows.
+	ExecutorFailureBudgetExhaustedReason = "ExecutorFailureBudgetExhausted"
+)
+
 type RestartPolicyType string
 
 const (
@@ -67,7 +151,6 @@ const (
 )
 
 // +genclient
-// +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:defaulter-gen=true
 
@@ -90,11 +173,50 @@ const (
 	ConcurrencyReplace ConcurrencyPolicy = "Replace"
 )
 
+// MissedRunPolicy is the policy governing the runs of a ScheduledSparkApplication that were missed, i.e., that
+// could not start before the next run was due or within the starting deadline, e.g., while the operator was down.
+type MissedRunPolicy struct {
+	// Type specifies the MissedRunPolicyType.
+	Type MissedRunPolicyType `json:"type"`
+	// MaxRuns is the maximum number of the most recent due runs started when Type is RunAll. Older runs are missed.
+	// Optional.
+	// Defaults to 10.
+	MaxRuns *int32 `json:"maxRuns,omitempty"`
+}
+
+type MissedRunPolicyType string
+
+const (
+	// MissedRunSkip skips missed runs. Only runs that are not missed are started.
+	MissedRunSkip MissedRunPolicyType = "Skip"
+	// MissedRunRunOnce starts a single run for the missed runs if no run that is not missed is due.
+	MissedRunRunOnce MissedRunPolicyType = "RunOnce"
+	// MissedRunRunAll starts runs that are overtaken by later runs one after the other, up to a maximum number of
+	// runs. Runs past the starting deadline are still missed.
+	MissedRunRunAll MissedRunPolicyType = "RunAll"
+)
+
 type ScheduledSparkApplicationSpec struct {
-	// Schedule is a cron schedule on which the application should run.
+	// Schedule is a cron schedule on which the application should run. It may name the time zone it is evaluated in
+	// with a CRON_TZ= or TZ= prefix, e.g., "CRON_TZ=America/New_York 0 2 * * *".
 	Schedule string `json:"schedule"`
+	// TimeZone is the IANA name of the time zone the schedule is evaluated in, e.g., "America/New_York". It must
+	// match the time zone named by the prefix of the schedule if the schedule has one.
+	// Optional.
+	// Defaults to the local time zone of the operator.
+	TimeZone string `json:"timeZone,omitempty"`
 	// Template is a template from which SparkApplication instances can be created.
 	Template SparkApplicationSpec `json:"template"`
+	// PipelineTemplate is a template from which SparkPipeline instances are created instead of SparkApplication
+	// instances if set, in which case Template is ignored.
+	// Optional.
+	PipelineTemplate *SparkPipelineSpec `json:"pipelineTemplate,omitempty"`
+	// ExpandTemplates is a flag telling the controller to expand the Go templates in the arguments, the Spark and
+	// Hadoop configuration, and the environment variables and labels of the driver and executors of each run if set
+	// to true. Values are used as they are otherwise.
+	// Optional.
+	// Defaults to false.
+	ExpandTemplates *bool `json:"expandTemplates,omitempty"`
 	// Suspend is a flag telling the controller to suspend subsequent runs of the application if set to true.
 	// Optional.
 	// Defaults to false.
@@ -109,6 +231,35 @@ type ScheduledSparkApplicationSpec struct {
 	// Optional.
 	// Defaults to 1.
 	FailedRunHistoryLimit *int32 `json:"failedRunHistoryLimit,omitempty"`
+	// StartingDeadlineSeconds is the number of seconds after their scheduled time runs must start within. Runs that
+	// cannot start within it are missed.
+	// Optional.
+	// Runs are only missed when the next run is due before they could start if not set.
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// MissedRunPolicy is the policy governing runs that were missed.
+	// Optional.
+	// Defaults to skipping missed runs.
+	MissedRunPolicy *MissedRunPolicy `json:"missedRunPolicy,omitempty"`
+	// Backfill requests runs of the application for the times in a past time range the schedule was due at.
+	// Optional.
+	Backfill *BackfillSpec `json:"backfill,omitempty"`
+}
+
+// BackfillSpec is a request to run a ScheduledSparkApplication for the times in a past time range its schedule was
+// due at, e.g., to redo the runs of the range after fixing a bug.
+type BackfillSpec struct {
+	// StartTime is the start of the time range, inclusive.
+	StartTime metav1.Time `json:"startTime"`
+	// EndTime is the end of the time range, inclusive. Times after the backfill starts are left to the schedule.
+	EndTime metav1.Time `json:"endTime"`
+	// MaxParallel is the maximum number of runs of the backfill running at the same time.
+	// Optional.
+	// Defaults to 1.
+	MaxParallel *int32 `json:"maxParallel,omitempty"`
+	// RequestTime is the time the backfill was requested at. Changing it starts a new backfill of the same time
+	// range, whose runs are named after it so they do not collide with the runs of earlier backfills.
+	// Optional.
+	RequestTime metav1.Time `json:"requestTime,omitempty"`
 }
 
 type ScheduleState string
@@ -121,20 +272,87 @@ const (
 type ScheduledSparkApplicationStatus struct {
 	// LastRun is the time when the last run of the application started.
 	LastRun metav1.Time `json:"lastRun,omitempty"`
+	// LastRunScheduledTime is the time the last run of the application was scheduled at. The last run starts after
+	// it, by the time it takes the operator to notice the run is due.
+	LastRunScheduledTime metav1.Time `json:"lastRunScheduledTime,omitempty"`
 	// NextRun is the time when the next run of the application will start.
 	NextRun metav1.Time `json:"nextRun,omitempty"`
-	// LastRunName is the name of the SparkApplication for the most recent run of the application.
+	// LastRunName is the name of the SparkApplication, or SparkPipeline, for the most recent run of the application.
 	LastRunName string `json:"lastRunName,omitempty"`
 	// PastSuccessfulRunNames keeps the names of SparkApplications for past successful runs.
 	PastSuccessfulRunNames []string `json:"pastSuccessfulRunNames,omitempty"`
 	// PastFailedRunNames keeps the names of SparkApplications for past failed runs.
 	PastFailedRunNames []string `json:"pastFailedRunNames,omitempty"`
+	// MissedRuns are the most recent runs of the application that were missed, oldest first.
+	MissedRuns []MissedRun `json:"missedRuns,omitempty"`
+	// MissedRunCount is the total number of runs of the application that were missed.
+	MissedRunCount int32 `json:"missedRunCount,omitempty"`
+	// Backfill is the status of the backfill requested by the spec, if any.
+	Backfill *BackfillStatus `json:"backfill,omitempty"`
 	// ScheduleState is the current scheduling state of the application.
 	ScheduleState ScheduleState `json:"scheduleState,omitempty"`
 	// Reason tells why the ScheduledSparkApplication is in the particular ScheduleState.
 	Reason string `json:"reason,omitempty"`
 }
 
+// MissedRun is a run of a ScheduledSparkApplication that was missed.
+type MissedRun struct {
+	// ScheduledTime is the time the run was scheduled at.
+	ScheduledTime metav1.Time `json:"scheduledTime"`
+	// Reason tells why the run was missed.
+	Reason MissedRunReason `json:"reason"`
+}
+
+type MissedRunReason string
+
+const (
+	// MissedRunOvertaken means the next run was due before the run could start.
+	MissedRunOvertaken MissedRunReason = "Overtaken"
+	// MissedRunDeadlineExceeded means the run could not start within the starting deadline.
+	MissedRunDeadlineExceeded MissedRunReason = "DeadlineExceeded"
+	// MissedRunLimitExceeded means the run was not among the most recent runs started by the RunAll policy.
+	MissedRunLimitExceeded MissedRunReason = "LimitExceeded"
+	// MissedRunConcurrencyForbidden means the run could not start because the previous run was still running and
+	// the concurrency policy is Forbid.
+	MissedRunConcurrencyForbidden MissedRunReason = "ConcurrencyForbidden"
+)
+
+// BackfillStatus is the status of the backfill of a ScheduledSparkApplication.
+type BackfillStatus struct {
+	// StartTime is the start of the time range of the backfill.
+	StartTime metav1.Time `json:"startTime"`
+	// EndTime is the end of the time range of the backfill.
+	EndTime metav1.Time `json:"endTime"`
+	// RequestTime is the time the backfill was requested at.
+	RequestTime metav1.Time `json:"requestTime,omitempty"`
+	// State is the state of the backfill.
+	State BackfillState `json:"state"`
+	// TotalRuns is the number of runs of the backfill, one for each time in the range the schedule was due at.
+	TotalRuns int32 `json:"totalRuns"`
+	// CreatedRuns is the number of runs of the backfill created so far.
+	CreatedRuns int32 `json:"createdRuns,omitempty"`
+	// CompletedRuns is the number of runs of the backfill that completed.
+	CompletedRuns int32 `json:"completedRuns,omitempty"`
+	// FailedRuns is the number of runs of the backfill that failed, or were deleted before they finished.
+	FailedRuns int32 `json:"failedRuns,omitempty"`
+	// NextRunTime is the time the next run of the backfill to create is scheduled at.
+	NextRunTime metav1.Time `json:"nextRunTime,omitempty"`
+	// ActiveRunNames are the names of the SparkApplications, or SparkPipelines, of the runs of the backfill that have
+	// not finished yet.
+	ActiveRunNames []string `json:"activeRunNames,omitempty"`
+}
+
+type BackfillState string
+
+const (
+	// BackfillRunningState means runs of the backfill are running or still to be created.
+	BackfillRunningState BackfillState = "Running"
+	// BackfillCompletedState means all runs of the backfill completed.
+	BackfillCompletedState BackfillState = "Completed"
+	// BackfillFailedState means all runs of the backfill finished and some of them failed.
+	BackfillFailedState BackfillState = "Failed"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // ScheduledSparkApplicationList carries a list of ScheduledSparkApplication objects.
@@ -145,7 +363,6 @@ type ScheduledSparkApplicationList struct {
 }
 
 // +genclient
-// +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:defaulter-gen=true
 
@@ -244,6 +461,43 @@ type SparkApplicationSpec struct {
 	// BatchScheduler configures which batch scheduler will be used for scheduling
 	// Optional.
 	BatchScheduler *string `json:"batchScheduler,omitempty"`
+	// DynamicAllocation configures dynamic allocation of executors. If it is enabled, the number of executors
+	// scales between DynamicAllocation.MinExecutors and DynamicAllocation.MaxExecutors with the workload.
+	// Optional.
+	DynamicAllocation *DynamicAllocation `json:"dynamicAllocation,omitempty"`
+	// TimeToLiveBeforeRunning is the maximum number of seconds an attempt of the application can stay submitted
+	// before its driver starts running. The attempt fails with reason PendingDeadlineExceeded once it passes.
+	// Optional.
+	TimeToLiveBeforeRunning *int64 `json:"timeToLiveBeforeRunning,omitempty"`
+	// UnrecoverablePendingPolicy fails attempts of the application the driver of which stays pending for a reason
+	// that will not go away by itself, e.g., an image that cannot be pulled.
+	// Optional.
+	UnrecoverablePendingPolicy *UnrecoverablePendingPolicy `json:"unrecoverablePendingPolicy,omitempty"`
+	// ActiveDeadlineSeconds is the maximum number of seconds an attempt of the application can run for since its
+	// submission. The attempt fails with reason ActiveDeadlineExceeded once it passes.
+	// Optional.
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+	// TimeToLiveSecondsAfterFinished is the number of seconds the application is kept for after it completes or
+	// fails. The application is deleted once it passes. If unset, the operator-wide default applies.
+	// Optional.
+	TimeToLiveSecondsAfterFinished *int64 `json:"timeToLiveSecondsAfterFinished,omitempty"`
+	// Suspend is a flag telling the controller to kill the current run of the application and not to run it
+	// until the flag is unset, in which case the application is run again.
+	// Optional.
+	// Defaults to false.
+	Suspend *bool `json:"suspend,omitempty"`
+	// CompactExecutorStatus makes the status of the application count executors by state instead of recording
+	// every executor, which keeps the object small for applications with very many executors.
+	// Optional.
+	CompactExecutorStatus *CompactExecutorStatus `json:"compactExecutorStatus,omitempty"`
+}
+
+// CompactExecutorStatus configures a compact executor status.
+type CompactExecutorStatus struct {
+	// MaxFailedExecutorDetails is the number of the most recently failed executors the details of which are kept.
+	// Optional.
+	// Defaults to 10.
+	MaxFailedExecutorDetails *int32 `json:"maxFailedExecutorDetails,omitempty"`
 }
 
 // ApplicationStateType represents the type of the current state of an application.
@@ -262,6 +516,8 @@ const (
 	SucceedingState       ApplicationStateType = "SUCCEEDING"
 	FailingState          ApplicationStateType = "FAILING"
 	UnknownState          ApplicationStateType = "UNKNOWN"
+	SuspendingState       ApplicationStateType = "SUSPENDING"
+	SuspendedState        ApplicationStateType = "SUSPENDED"
 )
 
 // ApplicationState tells the current state of the application and an error message in case of failures.
@@ -282,8 +538,48 @@ const (
 	ExecutorUnknownState   ExecutorState = "UNKNOWN"
 )
 
+// SparkApplicationConditionType is the type of a condition of a SparkApplication.
+type SparkApplicationConditionType string
+
+// Different types of conditions a SparkApplication may have.
+const (
+	// SparkApplicationSubmitted tells if the current run of the application has been submitted.
+	SparkApplicationSubmitted SparkApplicationConditionType = "Submitted"
+	// SparkApplicationDriverReady tells if the driver of the current run is running.
+	SparkApplicationDriverReady SparkApplicationConditionType = "DriverReady"
+	// SparkApplicationExecutorsReady tells if the requested executors of the current run are running.
+	SparkApplicationExecutorsReady SparkApplicationConditionType = "ExecutorsReady"
+	// SparkApplicationCompleted tells if the application has run to completion successfully.
+	SparkApplicationCompleted SparkApplicationConditionType = "Completed"
+	// SparkApplicationFailed tells if the application has failed and will not be retried.
+	SparkApplicationFailed SparkApplicationConditionType = "Failed"
+)
+
+// SparkApplicationCondition describes an aspect of the state of a SparkApplication. It follows the conventions
+// of Kubernetes API conditions so tools like `kubectl wait --for=condition=Completed` work.
+type SparkApplicationCondition struct {
+	// Type is the type of the condition.
+	Type SparkApplicationConditionType `json:"type"`
+	// Status is the status of the condition, one of True, False or Unknown.
+	Status apiv1.ConditionStatus `json:"status"`
+	// ObservedGeneration is the generation of the SparkApplication the condition was computed for.
+	// Optional.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastTransitionTime is the last time the condition changed from one status to another.
+	// Optional.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a CamelCase identifier of the reason of the last transition of the condition.
+	// Optional.
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable message about the last transition of the condition.
+	// Optional.
+	Message string `json:"message,omitempty"`
+}
+
 // SparkApplicationStatus describes the current status of a Spark application.
 type SparkApplicationStatus struct {
+	// ObservedGeneration is the most recent generation of the SparkApplication observed by the operator.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// SparkApplicationID is set by the spark-distribution(via spark.app.id config) on the driver and executor pods
 	SparkApplicationID string `json:"sparkApplicationId,omitempty"`
 	// SubmissionID is a unique ID of the current submission of the application.
@@ -298,12 +594,82 @@ type SparkApplicationStatus struct {
 	AppState ApplicationState `json:"applicationState,omitempty"`
 	// ExecutorState records the state of executors by executor Pod names.
 	ExecutorState map[string]ExecutorState `json:"executorState,omitempty"`
+	// Executors records details of executors by executor Pod names. To bound the size of the object, the details
+	// of the executors that terminated the longest time ago are dropped once there are too many executors.
+	Executors map[string]ExecutorInfo `json:"executors,omitempty"`
+	// OmittedExecutors is the number of terminated executors the details of which were dropped from Executors.
+	// Their states are still recorded in ExecutorState.
+	OmittedExecutors int32 `json:"omittedExecutors,omitempty"`
+	// ExecutorCounts counts the executors by state if the application has a compact executor status, in which
+	// case ExecutorState only records the executors that have not terminated or whose pods still exist, and
+	// Executors only the most recently failed executors.
+	ExecutorCounts *ExecutorCounts `json:"executorCounts,omitempty"`
+	// ExecutorFailures records the executor failures of the current attempt that count against the executor failure
+	// budget set by the MaxFailures of the executor spec. Only recorded if MaxFailures is set.
+	ExecutorFailures []ExecutorFailure `json:"executorFailures,omitempty"`
 	// ExecutionAttempts is the total number of attempts to run a submitted application to completion.
 	// Incremented upon each attempted run of the application and reset upon invalidation.
 	ExecutionAttempts int32 `json:"executionAttempts,omitempty"`
 	// SubmissionAttempts is the total number of attempts to submit an application to run.
 	// Incremented upon each attempted submission of the application and reset upon invalidation and rerun.
 	SubmissionAttempts int32 `json:"submissionAttempts,omitempty"`
+	// NextRetryTime is the time the application is going to be retried at after a failure.
+	NextRetryTime metav1.Time `json:"nextRetryTime,omitempty"`
+	// DriverMemory is the driver memory of the current attempt if it was increased from the one in the spec after
+	// failures of previous attempts. Reset upon invalidation.
+	DriverMemory string `json:"driverMemory,omitempty"`
+	// ExecutorMemory is the executor memory of the current attempt if it was increased from the one in the spec after
+	// failures of previous attempts. Reset upon invalidation.
+	ExecutorMemory string `json:"executorMemory,omitempty"`
+	// MemoryHistory records the memory used by the most recent execution attempts. Reset upon invalidation.
+	MemoryHistory []AttemptMemory `json:"memoryHistory,omitempty"`
+	// Conditions is the list of conditions of the application.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []SparkApplicationCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ExecutorCounts counts the executors of an application by state.
+type ExecutorCounts struct {
+	Pending   int32 `json:"pending,omitempty"`
+	Running   int32 `json:"running,omitempty"`
+	Completed int32 `json:"completed,omitempty"`
+	Failed    int32 `json:"failed,omitempty"`
+	Unknown   int32 `json:"unknown,omitempty"`
+}
+
+// ExecutorInfo has information about an executor.
+type ExecutorInfo struct {
+	// ExecutorID is the ID Spark assigned to the executor.
+	ExecutorID string `json:"executorID,omitempty"`
+	// State is the current state of the executor.
+	State ExecutorState `json:"state,omitempty"`
+	// NodeName is the name of the node the executor pod was scheduled onto.
+	NodeName string `json:"nodeName,omitempty"`
+	// PodIP is the IP address of the executor pod.
+	PodIP string `json:"podIP,omitempty"`
+	// StartTime is the time the executor pod was started by the kubelet.
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// TerminationTime is the time the executor terminated at, if it did.
+	TerminationTime metav1.Time `json:"terminationTime,omitempty"`
+	// ExitCode is the exit code of the executor container, if it terminated.
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// TerminationReason is the reason the executor pod or container terminated with.
+	TerminationReason string `json:"terminationReason,omitempty"`
+	// RestartCount is the number of times the executor container has been restarted.
+	RestartCount int32 `json:"restartCount,omitempty"`
+}
+
+// ExecutorFailure describes the failure of an executor.
+type ExecutorFailure struct {
+	// PodName is the name of the executor pod.
+	PodName string `json:"podName"`
+	// Time is the time the executor failed at.
+	Time metav1.Time `json:"time"`
+	// ExitCode is the exit code of the executor container, if it terminated.
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// Reason is the reason the executor pod or container terminated with.
+	Reason string `json:"reason,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -315,6 +681,107 @@ type SparkApplicationList struct {
 	Items           []SparkApplication `json:"items,omitempty"`
 }
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:defaulter-gen=true
+
+// SparkPipeline represents a directed acyclic graph of SparkApplications, each of which runs once the ones it
+// depends on have completed.
+type SparkPipeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              SparkPipelineSpec   `json:"spec"`
+	Status            SparkPipelineStatus `json:"status,omitempty"`
+}
+
+// PipelineFailurePolicy tells how a SparkPipeline handles failed steps.
+type PipelineFailurePolicy string
+
+const (
+	// PipelineStopOnFailure starts no more steps once a step failed. Running steps are not killed.
+	PipelineStopOnFailure PipelineFailurePolicy = "StopOnFailure"
+	// PipelineContinueOnFailure keeps starting the steps that do not depend on a failed step, directly or not.
+	PipelineContinueOnFailure PipelineFailurePolicy = "ContinueOnFailure"
+)
+
+// SparkPipelineSpec describes the steps of a SparkPipeline.
+type SparkPipelineSpec struct {
+	// Steps are the steps of the pipeline.
+	Steps []PipelineStep `json:"steps"`
+	// FailurePolicy tells how to handle failed steps.
+	// Optional.
+	// Defaults to StopOnFailure.
+	FailurePolicy PipelineFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// PipelineStep is a step of a SparkPipeline, which runs a SparkApplication.
+type PipelineStep struct {
+	// Name is the name of the step, unique within the pipeline.
+	Name string `json:"name"`
+	// DependsOn are the names of the steps that must complete before this step runs.
+	// Optional.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Template is the spec of the SparkApplication the step runs.
+	Template SparkApplicationSpec `json:"template"`
+}
+
+// PipelineState is the state of a SparkPipeline.
+type PipelineState string
+
+const (
+	PipelineRunningState          PipelineState = "RUNNING"
+	PipelineCompletedState        PipelineState = "COMPLETED"
+	PipelineFailedState           PipelineState = "FAILED"
+	PipelineFailedValidationState PipelineState = "FAILED_VALIDATION"
+)
+
+// PipelineStepState is the state of a step of a SparkPipeline.
+type PipelineStepState string
+
+const (
+	// PipelineStepPendingState means the step waits for the steps it depends on.
+	PipelineStepPendingState PipelineStepState = "PENDING"
+	// PipelineStepRunningState means the SparkApplication of the step has been created and has not finished.
+	PipelineStepRunningState   PipelineStepState = "RUNNING"
+	PipelineStepCompletedState PipelineStepState = "COMPLETED"
+	PipelineStepFailedState    PipelineStepState = "FAILED"
+	// PipelineStepSkippedState means the step will not run because a step failed.
+	PipelineStepSkippedState PipelineStepState = "SKIPPED"
+)
+
+// SparkPipelineStatus describes the current status of a SparkPipeline.
+type SparkPipelineStatus struct {
+	// State is the overall state of the pipeline.
+	State PipelineState `json:"state,omitempty"`
+	// Reason tells why the pipeline is in the particular state.
+	Reason string `json:"reason,omitempty"`
+	// StartTime is the time the pipeline started at.
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the last step of the pipeline finished at.
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+	// StepStatuses are the statuses of the steps, keyed by the step names.
+	StepStatuses map[string]PipelineStepStatus `json:"stepStatuses,omitempty"`
+}
+
+// PipelineStepStatus describes the current status of a step of a SparkPipeline.
+type PipelineStepStatus struct {
+	// State is the state of the step.
+	State PipelineStepState `json:"state"`
+	// ApplicationName is the name of the SparkApplication the step runs, once it has been created.
+	ApplicationName string `json:"applicationName,omitempty"`
+	// Reason tells why the step failed if it failed without running a SparkApplication.
+	Reason string `json:"reason,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SparkPipelineList carries a list of SparkPipeline objects.
+type SparkPipelineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SparkPipeline `json:"items,omitempty"`
+}
+
 // Dependencies specifies all possible types of dependencies of a Spark application.
 type Dependencies struct {
 	// Jars is a list of JAR files the Spark application depends on.
@@ -405,6 +872,12 @@ type SparkPodSpec struct {
 	// DnsConfig dns settings for the pod, following the Kubernetes specifications.
 	// Optional.
 	DNSConfig *apiv1.PodDNSConfig `json:"dnsConfig,omitempty"`
+	// Template is a pod template that is strategically merged into the pod created by Spark. It can set any
+	// pod field, e.g., init containers or the priority class. The Spark container keeps its image, command,
+	// arguments, and resources. Setting a field in the template that is also set by another field of the
+	// SparkPodSpec is an error.
+	// Optional.
+	Template *apiv1.PodTemplateSpec `json:"template,omitempty"`
 }
 
 // DriverSpec is specification of the driver.
@@ -436,6 +909,15 @@ type ExecutorSpec struct {
 	// JavaOptions is a string of extra JVM options to pass to the executors. For instance,
 	// GC settings or other logging.
 	JavaOptions *string `json:"javaOptions,omitempty"`
+	// MaxFailures is the number of executor failures an attempt of the application tolerates. The attempt fails
+	// once more executors failed, within FailureWindowSeconds if set.
+	// Optional.
+	// Defaults to tolerating any number of executor failures.
+	MaxFailures *int32 `json:"maxFailures,omitempty"`
+	// FailureWindowSeconds is the period of time executor failures count against MaxFailures for.
+	// Optional.
+	// Defaults to the whole attempt.
+	FailureWindowSeconds *int64 `json:"failureWindowSeconds,omitempty"`
 }
 
 // NamePath is a pair of a name and a path to which the named objects should be mounted to.
@@ -469,6 +951,43 @@ type DriverInfo struct {
 	WebUIIngressName    string `json:"webUIIngressName,omitempty"`
 	WebUIIngressAddress string `json:"webUIIngressAddress,omitempty"`
 	PodName             string `json:"podName,omitempty"`
+	// TerminationReason is the reason the driver pod or container failed with, e.g., OOMKilled or Evicted.
+	TerminationReason string `json:"terminationReason,omitempty"`
+	// ExitCode is the exit code of the driver container if it terminated.
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// PendingReason is the reason the driver pod is pending for, e.g., Unschedulable or ImagePullBackOff.
+	PendingReason string `json:"pendingReason,omitempty"`
+	// PendingMessage is the human-readable message explaining the PendingReason.
+	PendingMessage string `json:"pendingMessage,omitempty"`
+	// PendingSince is the time the driver pod has been pending for the PendingReason since.
+	PendingSince metav1.Time `json:"pendingSince,omitempty"`
+}
+
+// UnrecoverablePendingPolicy tells when to fail attempts of an application the driver of which stays pending for a
+// reason that will not go away by itself.
+type UnrecoverablePendingPolicy struct {
+	// FailAfterMinutes is the number of minutes the driver may stay pending for an unrecoverable reason. The attempt
+	// fails with the pending reason as its termination reason once they pass.
+	FailAfterMinutes int32 `json:"failAfterMinutes"`
+	// Reasons are the pending reasons considered unrecoverable.
+	// Optional.
+	// Defaults to ErrImagePull, ImagePullBackOff, InvalidImageName, CreateContainerConfigError, and
+	// CreateContainerError.
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// AttemptMemory records the memory used by an execution attempt of an application.
+type AttemptMemory struct {
+	// Attempt is the number of the execution attempt.
+	Attempt int32 `json:"attempt"`
+	// DriverMemory is the memory the driver was submitted with.
+	DriverMemory string `json:"driverMemory,omitempty"`
+	// ExecutorMemory is the memory the executors were submitted with.
+	ExecutorMemory string `json:"executorMemory,omitempty"`
+	// DriverOOMKilled tells if the driver was OOMKilled during the attempt.
+	DriverOOMKilled bool `json:"driverOOMKilled,omitempty"`
+	// ExecutorOOMKilled tells if an executor was OOMKilled during the attempt.
+	ExecutorOOMKilled bool `json:"executorOOMKilled,omitempty"`
 }
 
 // SecretInfo captures information of a secret.
@@ -518,6 +1037,29 @@ type PrometheusSpec struct {
 	Configuration *string `json:"configuration,omitempty"`
 }
 
+// DynamicAllocation contains configuration options for dynamic allocation of executors.
+type DynamicAllocation struct {
+	// Enabled controls whether dynamic allocation is enabled or not.
+	Enabled bool `json:"enabled,omitempty"`
+	// InitialExecutors is the initial number of executors to request. If .spec.executor.instances
+	// is also set, the initial number of executors is set to the bigger of that and this option.
+	// Optional.
+	InitialExecutors *int32 `json:"initialExecutors,omitempty"`
+	// MinExecutors is the lower bound for the number of executors if dynamic allocation is enabled.
+	// Optional.
+	MinExecutors *int32 `json:"minExecutors,omitempty"`
+	// MaxExecutors is the upper bound for the number of executors if dynamic allocation is enabled.
+	// Resource quota is charged against this number of executors.
+	// Optional.
+	MaxExecutors *int32 `json:"maxExecutors,omitempty"`
+	// ShuffleTrackingEnabled enables shuffle file tracking for executors, which allows dynamic allocation without
+	// the need for an external shuffle service. This option will try to keep alive executors that are storing
+	// shuffle data for active jobs.
+	// Optional.
+	// Defaults to true if dynamic allocation is enabled.
+	ShuffleTrackingEnabled *bool `json:"shuffleTrackingEnabled,omitempty"`
+}
+
 type GPUSpec struct {
 	// Name is GPU resource name, such as: nvidia.com/gpu or amd.com/gpu
 	Name string `json:"name"`
@@ -537,6 +1079,26 @@ func (s *SparkApplication) HasPrometheusConfigFile() bool {
 		*s.Spec.Monitoring.Prometheus.ConfigFile != ""
 }
 
+// DynamicAllocationEnabled returns if dynamic allocation of executors is enabled or not.
+func (s *SparkApplication) DynamicAllocationEnabled() bool {
+	return s.Spec.DynamicAllocation != nil && s.Spec.DynamicAllocation.Enabled
+}
+
+// EffectiveSpec returns a copy of the spec with the memory of the driver and executors increased after failures of
+// previous attempts, as recorded in the status.
+func (s *SparkApplication) EffectiveSpec() *SparkApplicationSpec {
+	spec := s.Spec.DeepCopy()
+	if s.Status.DriverMemory != "" {
+		memory := s.Status.DriverMemory
+		spec.Driver.Memory = &memory
+	}
+	if s.Status.ExecutorMemory != "" {
+		memory := s.Status.ExecutorMemory
+		spec.Executor.Memory = &memory
+	}
+	return spec
+}
+
 // ExposeDriverMetrics returns if driver metrics should be exposed.
 func (s *SparkApplication) ExposeDriverMetrics() bool {
 	return s.Spec.Monitoring != nil && s.Spec.Monitoring.ExposeDriverMetrics
@@ -43,6 +43,123 @@ func (in *ApplicationState) DeepCopy() *ApplicationState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AttemptMemory) DeepCopyInto(out *AttemptMemory) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttemptMemory.
+func (in *AttemptMemory) DeepCopy() *AttemptMemory {
+	if in == nil {
+		return nil
+	}
+	out := new(AttemptMemory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackfillSpec) DeepCopyInto(out *BackfillSpec) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	if in.MaxParallel != nil {
+		in, out := &in.MaxParallel, &out.MaxParallel
+		*out = new(int32)
+		**out = **in
+	}
+	in.RequestTime.DeepCopyInto(&out.RequestTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackfillSpec.
+func (in *BackfillSpec) DeepCopy() *BackfillSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackfillSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackfillStatus) DeepCopyInto(out *BackfillStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	in.RequestTime.DeepCopyInto(&out.RequestTime)
+	in.NextRunTime.DeepCopyInto(&out.NextRunTime)
+	if in.ActiveRunNames != nil {
+		in, out := &in.ActiveRunNames, &out.ActiveRunNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackfillStatus.
+func (in *BackfillStatus) DeepCopy() *BackfillStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackfillStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackoffPolicy) DeepCopyInto(out *BackoffPolicy) {
+	*out = *in
+	if in.Multiplier != nil {
+		in, out := &in.Multiplier, &out.Multiplier
+		*out = new(float64)
+		**out = **in
+	}
+	if in.MaxIntervalSeconds != nil {
+		in, out := &in.MaxIntervalSeconds, &out.MaxIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.JitterFactor != nil {
+		in, out := &in.JitterFactor, &out.JitterFactor
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackoffPolicy.
+func (in *BackoffPolicy) DeepCopy() *BackoffPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BackoffPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompactExecutorStatus) DeepCopyInto(out *CompactExecutorStatus) {
+	*out = *in
+	if in.MaxFailedExecutorDetails != nil {
+		in, out := &in.MaxFailedExecutorDetails, &out.MaxFailedExecutorDetails
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompactExecutorStatus.
+func (in *CompactExecutorStatus) DeepCopy() *CompactExecutorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CompactExecutorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Dependencies) DeepCopyInto(out *Dependencies) {
 	*out = *in
@@ -97,6 +214,12 @@ func (in *Dependencies) DeepCopy() *Dependencies {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DriverInfo) DeepCopyInto(out *DriverInfo) {
 	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	in.PendingSince.DeepCopyInto(&out.PendingSince)
 	return
 }
 
@@ -142,6 +265,103 @@ func (in *DriverSpec) DeepCopy() *DriverSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicAllocation) DeepCopyInto(out *DynamicAllocation) {
+	*out = *in
+	if in.InitialExecutors != nil {
+		in, out := &in.InitialExecutors, &out.InitialExecutors
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinExecutors != nil {
+		in, out := &in.MinExecutors, &out.MinExecutors
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxExecutors != nil {
+		in, out := &in.MaxExecutors, &out.MaxExecutors
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ShuffleTrackingEnabled != nil {
+		in, out := &in.ShuffleTrackingEnabled, &out.ShuffleTrackingEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicAllocation.
+func (in *DynamicAllocation) DeepCopy() *DynamicAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorCounts) DeepCopyInto(out *ExecutorCounts) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorCounts.
+func (in *ExecutorCounts) DeepCopy() *ExecutorCounts {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorCounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorFailure) DeepCopyInto(out *ExecutorFailure) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorFailure.
+func (in *ExecutorFailure) DeepCopy() *ExecutorFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorInfo) DeepCopyInto(out *ExecutorInfo) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.TerminationTime.DeepCopyInto(&out.TerminationTime)
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorInfo.
+func (in *ExecutorInfo) DeepCopy() *ExecutorInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExecutorSpec) DeepCopyInto(out *ExecutorSpec) {
 	*out = *in
@@ -161,6 +381,16 @@ func (in *ExecutorSpec) DeepCopyInto(out *ExecutorSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MaxFailures != nil {
+		in, out := &in.MaxFailures, &out.MaxFailures
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureWindowSeconds != nil {
+		in, out := &in.FailureWindowSeconds, &out.FailureWindowSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -174,6 +404,60 @@ func (in *ExecutorSpec) DeepCopy() *ExecutorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExitCodeRange) DeepCopyInto(out *ExitCodeRange) {
+	*out = *in
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExitCodeRange.
+func (in *ExitCodeRange) DeepCopy() *ExitCodeRange {
+	if in == nil {
+		return nil
+	}
+	out := new(ExitCodeRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureRule) DeepCopyInto(out *FailureRule) {
+	*out = *in
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExitCodes != nil {
+		in, out := &in.ExitCodes, &out.ExitCodes
+		*out = make([]ExitCodeRange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MemoryIncreaseFactor != nil {
+		in, out := &in.MemoryIncreaseFactor, &out.MemoryIncreaseFactor
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureRule.
+func (in *FailureRule) DeepCopy() *FailureRule {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GPUSpec) DeepCopyInto(out *GPUSpec) {
 	*out = *in
@@ -190,6 +474,65 @@ func (in *GPUSpec) DeepCopy() *GPUSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryIncreasePolicy) DeepCopyInto(out *MemoryIncreasePolicy) {
+	*out = *in
+	if in.MaxMemory != nil {
+		in, out := &in.MaxMemory, &out.MaxMemory
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryIncreasePolicy.
+func (in *MemoryIncreasePolicy) DeepCopy() *MemoryIncreasePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryIncreasePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MissedRun) DeepCopyInto(out *MissedRun) {
+	*out = *in
+	in.ScheduledTime.DeepCopyInto(&out.ScheduledTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MissedRun.
+func (in *MissedRun) DeepCopy() *MissedRun {
+	if in == nil {
+		return nil
+	}
+	out := new(MissedRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MissedRunPolicy) DeepCopyInto(out *MissedRunPolicy) {
+	*out = *in
+	if in.MaxRuns != nil {
+		in, out := &in.MaxRuns, &out.MaxRuns
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MissedRunPolicy.
+func (in *MissedRunPolicy) DeepCopy() *MissedRunPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MissedRunPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
 	*out = *in
@@ -248,6 +591,44 @@ func (in *NamePath) DeepCopy() *NamePath {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineStep) DeepCopyInto(out *PipelineStep) {
+	*out = *in
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Template.DeepCopyInto(&out.Template)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineStep.
+func (in *PipelineStep) DeepCopy() *PipelineStep {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineStepStatus) DeepCopyInto(out *PipelineStepStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineStepStatus.
+func (in *PipelineStepStatus) DeepCopy() *PipelineStepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineStepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusSpec) DeepCopyInto(out *PrometheusSpec) {
 	*out = *in
@@ -302,6 +683,23 @@ func (in *RestartPolicy) DeepCopyInto(out *RestartPolicy) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(BackoffPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureRules != nil {
+		in, out := &in.FailureRules, &out.FailureRules
+		*out = make([]FailureRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MemoryIncreaseOnOOM != nil {
+		in, out := &in.MemoryIncreaseOnOOM, &out.MemoryIncreaseOnOOM
+		*out = new(MemoryIncreasePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -380,6 +778,16 @@ func (in *ScheduledSparkApplicationList) DeepCopyObject() runtime.Object {
 func (in *ScheduledSparkApplicationSpec) DeepCopyInto(out *ScheduledSparkApplicationSpec) {
 	*out = *in
 	in.Template.DeepCopyInto(&out.Template)
+	if in.PipelineTemplate != nil {
+		in, out := &in.PipelineTemplate, &out.PipelineTemplate
+		*out = new(SparkPipelineSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpandTemplates != nil {
+		in, out := &in.ExpandTemplates, &out.ExpandTemplates
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Suspend != nil {
 		in, out := &in.Suspend, &out.Suspend
 		*out = new(bool)
@@ -395,6 +803,21 @@ func (in *ScheduledSparkApplicationSpec) DeepCopyInto(out *ScheduledSparkApplica
 		*out = new(int32)
 		**out = **in
 	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MissedRunPolicy != nil {
+		in, out := &in.MissedRunPolicy, &out.MissedRunPolicy
+		*out = new(MissedRunPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backfill != nil {
+		in, out := &in.Backfill, &out.Backfill
+		*out = new(BackfillSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -412,6 +835,7 @@ func (in *ScheduledSparkApplicationSpec) DeepCopy() *ScheduledSparkApplicationSp
 func (in *ScheduledSparkApplicationStatus) DeepCopyInto(out *ScheduledSparkApplicationStatus) {
 	*out = *in
 	in.LastRun.DeepCopyInto(&out.LastRun)
+	in.LastRunScheduledTime.DeepCopyInto(&out.LastRunScheduledTime)
 	in.NextRun.DeepCopyInto(&out.NextRun)
 	if in.PastSuccessfulRunNames != nil {
 		in, out := &in.PastSuccessfulRunNames, &out.PastSuccessfulRunNames
@@ -423,6 +847,18 @@ func (in *ScheduledSparkApplicationStatus) DeepCopyInto(out *ScheduledSparkAppli
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.MissedRuns != nil {
+		in, out := &in.MissedRuns, &out.MissedRuns
+		*out = make([]MissedRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Backfill != nil {
+		in, out := &in.Backfill, &out.Backfill
+		*out = new(BackfillStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -480,6 +916,23 @@ func (in *SparkApplication) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkApplicationCondition) DeepCopyInto(out *SparkApplicationCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationCondition.
+func (in *SparkApplicationCondition) DeepCopy() *SparkApplicationCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkApplicationCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SparkApplicationList) DeepCopyInto(out *SparkApplicationList) {
 	*out = *in
@@ -624,6 +1077,41 @@ func (in *SparkApplicationSpec) DeepCopyInto(out *SparkApplicationSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.DynamicAllocation != nil {
+		in, out := &in.DynamicAllocation, &out.DynamicAllocation
+		*out = new(DynamicAllocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TimeToLiveBeforeRunning != nil {
+		in, out := &in.TimeToLiveBeforeRunning, &out.TimeToLiveBeforeRunning
+		*out = new(int64)
+		**out = **in
+	}
+	if in.UnrecoverablePendingPolicy != nil {
+		in, out := &in.UnrecoverablePendingPolicy, &out.UnrecoverablePendingPolicy
+		*out = new(UnrecoverablePendingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TimeToLiveSecondsAfterFinished != nil {
+		in, out := &in.TimeToLiveSecondsAfterFinished, &out.TimeToLiveSecondsAfterFinished
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CompactExecutorStatus != nil {
+		in, out := &in.CompactExecutorStatus, &out.CompactExecutorStatus
+		*out = new(CompactExecutorStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -642,7 +1130,7 @@ func (in *SparkApplicationStatus) DeepCopyInto(out *SparkApplicationStatus) {
 	*out = *in
 	in.LastSubmissionAttemptTime.DeepCopyInto(&out.LastSubmissionAttemptTime)
 	in.TerminationTime.DeepCopyInto(&out.TerminationTime)
-	out.DriverInfo = in.DriverInfo
+	in.DriverInfo.DeepCopyInto(&out.DriverInfo)
 	out.AppState = in.AppState
 	if in.ExecutorState != nil {
 		in, out := &in.ExecutorState, &out.ExecutorState
@@ -651,6 +1139,38 @@ func (in *SparkApplicationStatus) DeepCopyInto(out *SparkApplicationStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Executors != nil {
+		in, out := &in.Executors, &out.Executors
+		*out = make(map[string]ExecutorInfo, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.ExecutorCounts != nil {
+		in, out := &in.ExecutorCounts, &out.ExecutorCounts
+		*out = new(ExecutorCounts)
+		**out = **in
+	}
+	if in.ExecutorFailures != nil {
+		in, out := &in.ExecutorFailures, &out.ExecutorFailures
+		*out = make([]ExecutorFailure, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.NextRetryTime.DeepCopyInto(&out.NextRetryTime)
+	if in.MemoryHistory != nil {
+		in, out := &in.MemoryHistory, &out.MemoryHistory
+		*out = make([]AttemptMemory, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]SparkApplicationCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -664,6 +1184,115 @@ func (in *SparkApplicationStatus) DeepCopy() *SparkApplicationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkPipeline) DeepCopyInto(out *SparkPipeline) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkPipeline.
+func (in *SparkPipeline) DeepCopy() *SparkPipeline {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkPipeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SparkPipeline) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkPipelineList) DeepCopyInto(out *SparkPipelineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SparkPipeline, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkPipelineList.
+func (in *SparkPipelineList) DeepCopy() *SparkPipelineList {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkPipelineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SparkPipelineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkPipelineSpec) DeepCopyInto(out *SparkPipelineSpec) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]PipelineStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkPipelineSpec.
+func (in *SparkPipelineSpec) DeepCopy() *SparkPipelineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkPipelineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkPipelineStatus) DeepCopyInto(out *SparkPipelineStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.StepStatuses != nil {
+		in, out := &in.StepStatuses, &out.StepStatuses
+		*out = make(map[string]PipelineStepStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkPipelineStatus.
+func (in *SparkPipelineStatus) DeepCopy() *SparkPipelineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkPipelineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SparkPodSpec) DeepCopyInto(out *SparkPodSpec) {
 	*out = *in
@@ -788,6 +1417,11 @@ func (in *SparkPodSpec) DeepCopyInto(out *SparkPodSpec) {
 		*out = new(v1.PodDNSConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(v1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -800,3 +1434,24 @@ func (in *SparkPodSpec) DeepCopy() *SparkPodSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnrecoverablePendingPolicy) DeepCopyInto(out *UnrecoverablePendingPolicy) {
+	*out = *in
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnrecoverablePendingPolicy.
+func (in *UnrecoverablePendingPolicy) DeepCopy() *UnrecoverablePendingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UnrecoverablePendingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coscheduling
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/batchscheduler/interface"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/batchscheduler/util"
+)
+
+const (
+	PodGroupName = "podgroups.scheduling.x-k8s.io"
+	// PodGroupLabel is the label the coscheduling plugin uses to find the PodGroup of a pod.
+	PodGroupLabel = "scheduling.x-k8s.io/pod-group"
+	// DefaultSchedulerName is the name of the scheduler running the coscheduling plugin if none is
+	// specified for the driver or executors.
+	DefaultSchedulerName = "scheduler-plugins-scheduler"
+)
+
+var podGroupResource = schema.GroupVersionResource{
+	Group:    "scheduling.x-k8s.io",
+	Version:  "v1alpha1",
+	Resource: "podgroups",
+}
+
+// CoschedulingBatchScheduler gang-schedules the pods of an application with the coscheduling plugin of the
+// scheduler-plugins project. The PodGroup API is accessed through the dynamic client.
+type CoschedulingBatchScheduler struct {
+	dynamicClient dynamic.Interface
+}
+
+func GetPluginName() string {
+	return "coscheduling"
+}
+
+// GetPodGroupName returns the name of the PodGroup of the given application.
+func GetPodGroupName(app *v1beta1.SparkApplication) string {
+	return fmt.Sprintf("spark-%s-pg", app.Name)
+}
+
+func (s *CoschedulingBatchScheduler) Name() string {
+	return GetPluginName()
+}
+
+func (s *CoschedulingBatchScheduler) ShouldSchedule(app *v1beta1.SparkApplication) bool {
+	//NOTE: There is no additional requirement for the coscheduling plugin
+	return true
+}
+
+func (s *CoschedulingBatchScheduler) DoBatchSchedulingOnSubmission(app *v1beta1.SparkApplication) (*v1beta1.SparkApplication, error) {
+	//NOTE: Pods are labeled with the PodGroup name by the mutating webhook.
+	size, minResource := getPodGroupSpec(app)
+	if err := s.syncPodGroup(app, size, minResource); err != nil {
+		return nil, err
+	}
+	return app.DeepCopy(), nil
+}
+
+func (s *CoschedulingBatchScheduler) OnSparkApplicationDelete(app *v1beta1.SparkApplication) error {
+	return s.deletePodGroup(app)
+}
+
+func (s *CoschedulingBatchScheduler) CleanupOnCompletion(app *v1beta1.SparkApplication) error {
+	return s.deletePodGroup(app)
+}
+
+func (s *CoschedulingBatchScheduler) syncPodGroup(app *v1beta1.SparkApplication, size int32, minResource corev1.ResourceList) error {
+	podGroups := s.dynamicClient.Resource(podGroupResource).Namespace(app.Namespace)
+	desired := newPodGroup(app, size, minResource)
+
+	existing, err := podGroups.Get(desired.GetName(), v1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get PodGroup %s/%s: %v", app.Namespace, desired.GetName(), err)
+		}
+		if _, err := podGroups.Create(desired, v1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create PodGroup %s/%s: %v", app.Namespace, desired.GetName(), err)
+		}
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(existing.Object["spec"], desired.Object["spec"]) {
+		return nil
+	}
+	existing.Object["spec"] = desired.Object["spec"]
+	if _, err := podGroups.Update(existing, v1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update PodGroup %s/%s: %v", app.Namespace, desired.GetName(), err)
+	}
+	return nil
+}
+
+func (s *CoschedulingBatchScheduler) deletePodGroup(app *v1beta1.SparkApplication) error {
+	podGroupName := GetPodGroupName(app)
+	err := s.dynamicClient.Resource(podGroupResource).Namespace(app.Namespace).Delete(podGroupName, &v1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete PodGroup %s/%s: %v", app.Namespace, podGroupName, err)
+	}
+	return nil
+}
+
+// getPodGroupSpec returns the minimum number of members and the minimum resources of the PodGroup of an application.
+func getPodGroupSpec(app *v1beta1.SparkApplication) (int32, corev1.ResourceList) {
+	executorResource := schedulerutil.GetExecutorRequestResource(app)
+	if app.Spec.Mode == v1beta1.ClientMode {
+		// Only the executors run in pods in client mode, they can be scheduled together.
+		size := schedulerutil.GetExecutorInstances(app)
+		if size < 1 {
+			size = 1
+		}
+		return size, executorResource
+	}
+	//NOTE: In cluster mode, the executors are only created once the driver runs, so the PodGroup only requires the
+	// driver to be schedulable. The minimum resources still reserve room for the executors.
+	return 1, schedulerutil.SumResourceList([]corev1.ResourceList{executorResource, schedulerutil.GetDriverRequestResource(app)})
+}
+
+func newPodGroup(app *v1beta1.SparkApplication, size int32, minResource corev1.ResourceList) *unstructured.Unstructured {
+	resources := make(map[string]interface{})
+	for name, quantity := range minResource {
+		resources[string(name)] = quantity.String()
+	}
+
+	podGroup := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"minMember":    int64(size),
+				"minResources": resources,
+			},
+		},
+	}
+	podGroup.SetAPIVersion(podGroupResource.GroupVersion().String())
+	podGroup.SetKind("PodGroup")
+	podGroup.SetNamespace(app.Namespace)
+	podGroup.SetName(GetPodGroupName(app))
+	podGroup.SetOwnerReferences([]v1.OwnerReference{
+		*v1.NewControllerRef(app, v1beta1.SchemeGroupVersion.WithKind("SparkApplication")),
+	})
+	return podGroup
+}
+
+func New(config *rest.Config) (schedulerinterface.BatchScheduler, error) {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize dynamic client with error %v", err)
+	}
+	extClient, err := apiextensionsclient.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize k8s extension client with error %v", err)
+	}
+
+	if _, err := extClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(
+		PodGroupName, v1.GetOptions{}); err != nil {
+		return nil, fmt.Errorf("podGroup CRD is required to exists in current cluster error: %s", err)
+	}
+	return &CoschedulingBatchScheduler{
+		dynamicClient: dynamicClient,
+	}, nil
+}
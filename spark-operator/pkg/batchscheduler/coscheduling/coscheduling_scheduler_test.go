@@ -0,0 +1,110 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coscheduling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestPodGroupLifecycle(t *testing.T) {
+	cores := float32(1)
+	memory := "1Gi"
+	instances := int32(2)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: v1.ObjectMeta{Name: "foo", Namespace: "default", UID: "foo-123"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Mode: v1beta1.ClientMode,
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{Cores: &cores, Memory: &memory},
+				Instances:    &instances,
+			},
+		},
+	}
+
+	scheduler := &CoschedulingBatchScheduler{dynamicClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())}
+	podGroups := scheduler.dynamicClient.Resource(podGroupResource).Namespace(app.Namespace)
+
+	_, err := scheduler.DoBatchSchedulingOnSubmission(app)
+	assert.Nil(t, err)
+	podGroup, err := podGroups.Get(GetPodGroupName(app), v1.GetOptions{})
+	assert.Nil(t, err)
+	minMember, _, _ := unstructured.NestedInt64(podGroup.Object, "spec", "minMember")
+	assert.Equal(t, int64(2), minMember)
+	minResources, _, _ := unstructured.NestedStringMap(podGroup.Object, "spec", "minResources")
+	assert.Equal(t, map[string]string{"cpu": "2", "memory": "2Gi"}, minResources)
+	assert.Equal(t, 1, len(podGroup.GetOwnerReferences()))
+	assert.Equal(t, app.Name, podGroup.GetOwnerReferences()[0].Name)
+
+	// Re-submissions update the existing PodGroup.
+	instances = 3
+	_, err = scheduler.DoBatchSchedulingOnSubmission(app)
+	assert.Nil(t, err)
+	podGroup, err = podGroups.Get(GetPodGroupName(app), v1.GetOptions{})
+	assert.Nil(t, err)
+	minMember, _, _ = unstructured.NestedInt64(podGroup.Object, "spec", "minMember")
+	assert.Equal(t, int64(3), minMember)
+
+	assert.Nil(t, scheduler.CleanupOnCompletion(app))
+	_, err = podGroups.Get(GetPodGroupName(app), v1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	// Deleting a PodGroup that is already gone is not an error.
+	assert.Nil(t, scheduler.OnSparkApplicationDelete(app))
+}
+
+func TestGetPodGroupSpec(t *testing.T) {
+	cores := float32(1)
+	instances := int32(4)
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			Mode: v1beta1.ClusterMode,
+			Driver: v1beta1.DriverSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{Cores: &cores},
+			},
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{Cores: &cores},
+				Instances:    &instances,
+			},
+		},
+	}
+
+	// The driver is scheduled first in cluster mode.
+	size, minResource := getPodGroupSpec(app)
+	assert.Equal(t, int32(1), size)
+	cpu := minResource["cpu"]
+	assert.Equal(t, int64(5), cpu.Value())
+
+	app.Spec.Mode = v1beta1.ClientMode
+	size, minResource = getPodGroupSpec(app)
+	assert.Equal(t, int32(4), size)
+	cpu = minResource["cpu"]
+	assert.Equal(t, int64(4), cpu.Value())
+
+	// Only the minimum number of executors is required with dynamic allocation.
+	minExecutors := int32(2)
+	app.Spec.DynamicAllocation = &v1beta1.DynamicAllocation{Enabled: true, MinExecutors: &minExecutors}
+	size, _ = getPodGroupSpec(app)
+	assert.Equal(t, int32(2), size)
+}
@@ -25,4 +25,8 @@ type BatchScheduler interface {
 
 	ShouldSchedule(app *v1beta1.SparkApplication) bool
 	DoBatchSchedulingOnSubmission(app *v1beta1.SparkApplication) (*v1beta1.SparkApplication, error)
+	// OnSparkApplicationDelete releases the scheduling objects of an application being deleted.
+	OnSparkApplicationDelete(app *v1beta1.SparkApplication) error
+	// CleanupOnCompletion releases the scheduling objects of an application that has terminated.
+	CleanupOnCompletion(app *v1beta1.SparkApplication) error
 }
@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/client-go/rest"
 
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/batchscheduler/coscheduling"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/batchscheduler/interface"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/batchscheduler/volcano"
 )
@@ -29,7 +30,8 @@ import (
 type schedulerInitializeFunc func(config *rest.Config) (schedulerinterface.BatchScheduler, error)
 
 var schedulerContainers = map[string]schedulerInitializeFunc{
-	volcano.GetPluginName(): volcano.New,
+	volcano.GetPluginName():      volcano.New,
+	coscheduling.GetPluginName(): coscheduling.New,
 }
 
 func GetRegisteredNames() []string {
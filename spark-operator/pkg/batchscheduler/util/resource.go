@@ -0,0 +1,147 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedulerutil
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// GetExecutorRequestResource returns the resources requested by all executors of the application.
+func GetExecutorRequestResource(app *v1beta1.SparkApplication) corev1.ResourceList {
+	minResource := corev1.ResourceList{}
+
+	//CoreRequest correspond to executor's core request
+	if app.Spec.Executor.CoreRequest != nil {
+		if value, err := resource.ParseQuantity(*app.Spec.Executor.CoreRequest); err == nil {
+			minResource[corev1.ResourceCPU] = value
+		}
+	}
+
+	//Use Core attribute if CoreRequest is empty
+	if app.Spec.Executor.Cores != nil {
+		if _, ok := minResource[corev1.ResourceCPU]; !ok {
+			if value, err := resource.ParseQuantity(fmt.Sprintf("%f", *app.Spec.Executor.Cores)); err == nil {
+				minResource[corev1.ResourceCPU] = value
+			}
+		}
+	}
+
+	//CoreLimit correspond to executor's core limit, this attribute will be used only when core request is empty.
+	if app.Spec.Executor.CoreLimit != nil {
+		if _, ok := minResource[corev1.ResourceCPU]; !ok {
+			if value, err := resource.ParseQuantity(*app.Spec.Executor.CoreLimit); err == nil {
+				minResource[corev1.ResourceCPU] = value
+			}
+		}
+	}
+
+	//Memory + MemoryOverhead correspond to executor's memory request
+	if app.Spec.Executor.Memory != nil {
+		if value, err := resource.ParseQuantity(*app.Spec.Executor.Memory); err == nil {
+			minResource[corev1.ResourceMemory] = value
+		}
+	}
+	if app.Spec.Executor.MemoryOverhead != nil {
+		if value, err := resource.ParseQuantity(*app.Spec.Executor.MemoryOverhead); err == nil {
+			if existing, ok := minResource[corev1.ResourceMemory]; ok {
+				existing.Add(value)
+				minResource[corev1.ResourceMemory] = existing
+			}
+		}
+	}
+
+	resourceList := []corev1.ResourceList{{}}
+	for i := int32(0); i < GetExecutorInstances(app); i++ {
+		resourceList = append(resourceList, minResource)
+	}
+	return SumResourceList(resourceList)
+}
+
+// GetExecutorInstances returns the number of executors a gang-scheduled application must be able to hold. With dynamic
+// allocation, only the minimum number of executors is guaranteed, the rest come and go with the workload.
+func GetExecutorInstances(app *v1beta1.SparkApplication) int32 {
+	if app.DynamicAllocationEnabled() {
+		if app.Spec.DynamicAllocation.MinExecutors != nil {
+			return *app.Spec.DynamicAllocation.MinExecutors
+		}
+		return 0
+	}
+	if app.Spec.Executor.Instances != nil {
+		return *app.Spec.Executor.Instances
+	}
+	return 1
+}
+
+// GetDriverRequestResource returns the resources requested by the driver of the application.
+func GetDriverRequestResource(app *v1beta1.SparkApplication) corev1.ResourceList {
+	minResource := corev1.ResourceList{}
+
+	//Cores correspond to driver's core request
+	if app.Spec.Driver.Cores != nil {
+		if value, err := resource.ParseQuantity(fmt.Sprintf("%f", *app.Spec.Driver.Cores)); err == nil {
+			minResource[corev1.ResourceCPU] = value
+		}
+	}
+
+	//CoreLimit correspond to driver's core limit, this attribute will be used only when core request is empty.
+	if app.Spec.Driver.CoreLimit != nil {
+		if _, ok := minResource[corev1.ResourceCPU]; !ok {
+			if value, err := resource.ParseQuantity(*app.Spec.Driver.CoreLimit); err == nil {
+				minResource[corev1.ResourceCPU] = value
+			}
+		}
+	}
+
+	//Memory + MemoryOverhead correspond to driver's memory request
+	if app.Spec.Driver.Memory != nil {
+		if value, err := resource.ParseQuantity(*app.Spec.Driver.Memory); err == nil {
+			minResource[corev1.ResourceMemory] = value
+		}
+	}
+	if app.Spec.Driver.MemoryOverhead != nil {
+		if value, err := resource.ParseQuantity(*app.Spec.Driver.MemoryOverhead); err == nil {
+			if existing, ok := minResource[corev1.ResourceMemory]; ok {
+				existing.Add(value)
+				minResource[corev1.ResourceMemory] = existing
+			}
+		}
+	}
+
+	return minResource
+}
+
+// SumResourceList returns the sum of the given resource lists.
+func SumResourceList(list []corev1.ResourceList) corev1.ResourceList {
+	totalResource := corev1.ResourceList{}
+	for _, l := range list {
+		for name, quantity := range l {
+
+			if value, ok := totalResource[name]; !ok {
+				totalResource[name] = *quantity.Copy()
+			} else {
+				value.Add(quantity)
+				totalResource[name] = value
+			}
+		}
+	}
+	return totalResource
+}
@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package volcano
+package schedulerutil
 
 import (
 	"testing"
@@ -76,7 +76,7 @@ func TestGetDriverResource(t *testing.T) {
 
 	for _, testcase := range testcases {
 		t.Run(testcase.Name, func(t *testing.T) {
-			r := getDriverRequestResource(&testcase.app)
+			r := GetDriverRequestResource(&testcase.app)
 			for name, quantity := range testcase.result {
 				if actual, ok := r[name]; !ok {
 					t.Errorf("expecting driver pod to have resource %s, while get none", name)
@@ -98,6 +98,7 @@ func TestGetExecutorResource(t *testing.T) {
 	oneGB := "1024m"
 	oneCore := float32(1)
 	instances := int32(2)
+	maxInstances := int32(10)
 
 	result := v1.ResourceList{}
 	result[v1.ResourceCPU] = resource.MustParse("1")
@@ -157,11 +158,32 @@ func TestGetExecutorResource(t *testing.T) {
 			},
 			result: result,
 		},
+		{
+			Name: "Validate dynamic allocation with min executors",
+			app: v1beta1.SparkApplication{
+				Spec: v1beta1.SparkApplicationSpec{
+					Executor: v1beta1.ExecutorSpec{
+						SparkPodSpec: v1beta1.SparkPodSpec{
+							Cores:          &halfCore,
+							Memory:         &oneGB,
+							MemoryOverhead: &oneGB,
+						},
+						Instances: &maxInstances,
+					},
+					DynamicAllocation: &v1beta1.DynamicAllocation{
+						Enabled:      true,
+						MinExecutors: &instances,
+						MaxExecutors: &maxInstances,
+					},
+				},
+			},
+			result: result,
+		},
 	}
 
 	for _, testcase := range testcases {
 		t.Run(testcase.Name, func(t *testing.T) {
-			r := getExecutorRequestResource(&testcase.app)
+			r := GetExecutorRequestResource(&testcase.app)
 			for name, quantity := range testcase.result {
 				if actual, ok := r[name]; !ok {
 					t.Errorf("expecting executor pod to have resource %s, while get none", name)
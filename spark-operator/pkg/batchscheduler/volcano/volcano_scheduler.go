@@ -21,7 +21,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 
@@ -30,6 +29,7 @@ import (
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/batchscheduler/interface"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/batchscheduler/util"
 )
 
 const (
@@ -72,12 +72,26 @@ func (v *VolcanoBatchScheduler) DoBatchSchedulingOnSubmission(app *v1beta1.Spark
 	return newApp, nil
 }
 
+func (v *VolcanoBatchScheduler) OnSparkApplicationDelete(app *v1beta1.SparkApplication) error {
+	return v.deletePodGroup(app)
+}
+
+func (v *VolcanoBatchScheduler) CleanupOnCompletion(app *v1beta1.SparkApplication) error {
+	//NOTE: The PodGroup holds resources in its queue until it is deleted.
+	return v.deletePodGroup(app)
+}
+
 func (v *VolcanoBatchScheduler) syncPodGroupInClientMode(app *v1beta1.SparkApplication) (*v1beta1.SparkApplication, error) {
 	//We only care about the executor pods in client mode
 	newApp := app.DeepCopy()
 	if _, ok := newApp.Spec.Executor.Annotations[v1alpha2.GroupNameAnnotationKey]; !ok {
 		//Only executor resource will be considered.
-		if err := v.syncPodGroup(newApp, 1, getExecutorRequestResource(app)); err == nil {
+		//NOTE: With dynamic allocation, the PodGroup requires the minimum number of executors to be schedulable.
+		size := int32(1)
+		if app.DynamicAllocationEnabled() && schedulerutil.GetExecutorInstances(app) > size {
+			size = schedulerutil.GetExecutorInstances(app)
+		}
+		if err := v.syncPodGroup(newApp, size, schedulerutil.GetExecutorRequestResource(app)); err == nil {
 			newApp.Spec.Executor.Annotations[v1alpha2.GroupNameAnnotationKey] = v.getAppPodGroupName(newApp)
 		} else {
 			return nil, err
@@ -91,7 +105,7 @@ func (v *VolcanoBatchScheduler) syncPodGroupInClusterMode(app *v1beta1.SparkAppl
 	//NOTE: In cluster mode, the initial size of PodGroup is set to 1 in order to schedule driver pod first.
 	if _, ok := app.Spec.Driver.Annotations[v1alpha2.GroupNameAnnotationKey]; !ok {
 		//Both driver and executor resource will be considered.
-		totalResource := sumResourceList([]corev1.ResourceList{getExecutorRequestResource(app), getDriverRequestResource(app)})
+		totalResource := schedulerutil.SumResourceList([]corev1.ResourceList{schedulerutil.GetExecutorRequestResource(app), schedulerutil.GetDriverRequestResource(app)})
 		if err := v.syncPodGroup(app, 1, totalResource); err == nil {
 			app.Spec.Executor.Annotations[v1alpha2.GroupNameAnnotationKey] = v.getAppPodGroupName(app)
 			app.Spec.Driver.Annotations[v1alpha2.GroupNameAnnotationKey] = v.getAppPodGroupName(app)
@@ -107,9 +121,10 @@ func (v *VolcanoBatchScheduler) getAppPodGroupName(app *v1beta1.SparkApplication
 }
 
 func (v *VolcanoBatchScheduler) syncPodGroup(app *v1beta1.SparkApplication, size int32, minResource corev1.ResourceList) error {
+	var pg *v1alpha2.PodGroup
 	var err error
 	podGroupName := v.getAppPodGroupName(app)
-	if pg, err := v.volcanoClient.SchedulingV1alpha2().PodGroups(app.Namespace).Get(podGroupName, v1.GetOptions{}); err != nil {
+	if pg, err = v.volcanoClient.SchedulingV1alpha2().PodGroups(app.Namespace).Get(podGroupName, v1.GetOptions{}); err != nil {
 		if !errors.IsNotFound(err) {
 			return err
 		}
@@ -134,7 +149,19 @@ func (v *VolcanoBatchScheduler) syncPodGroup(app *v1beta1.SparkApplication, size
 			_, err = v.volcanoClient.SchedulingV1alpha2().PodGroups(app.Namespace).Update(pg)
 		}
 	}
-	return fmt.Errorf("failed to sync PodGroup with error: %s. Abandon schedule pods via volcano", err)
+	if err != nil {
+		return fmt.Errorf("failed to sync PodGroup with error: %s. Abandon schedule pods via volcano", err)
+	}
+	return nil
+}
+
+func (v *VolcanoBatchScheduler) deletePodGroup(app *v1beta1.SparkApplication) error {
+	podGroupName := v.getAppPodGroupName(app)
+	err := v.volcanoClient.SchedulingV1alpha2().PodGroups(app.Namespace).Delete(podGroupName, &v1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete PodGroup %s/%s: %v", app.Namespace, podGroupName, err)
+	}
+	return nil
 }
 
 func New(config *rest.Config) (schedulerinterface.BatchScheduler, error) {
@@ -156,106 +183,3 @@ func New(config *rest.Config) (schedulerinterface.BatchScheduler, error) {
 		volcanoClient:   vkClient,
 	}, nil
 }
-
-func getExecutorRequestResource(app *v1beta1.SparkApplication) corev1.ResourceList {
-	minResource := corev1.ResourceList{}
-
-	//CoreRequest correspond to executor's core request
-	if app.Spec.Executor.CoreRequest != nil {
-		if value, err := resource.ParseQuantity(*app.Spec.Executor.CoreRequest); err == nil {
-			minResource[corev1.ResourceCPU] = value
-		}
-	}
-
-	//Use Core attribute if CoreRequest is empty
-	if app.Spec.Executor.Cores != nil {
-		if _, ok := minResource[corev1.ResourceCPU]; !ok {
-			if value, err := resource.ParseQuantity(fmt.Sprintf("%f", *app.Spec.Executor.Cores)); err == nil {
-				minResource[corev1.ResourceCPU] = value
-			}
-		}
-	}
-
-	//CoreLimit correspond to executor's core limit, this attribute will be used only when core request is empty.
-	if app.Spec.Executor.CoreLimit != nil {
-		if _, ok := minResource[corev1.ResourceCPU]; !ok {
-			if value, err := resource.ParseQuantity(*app.Spec.Executor.CoreLimit); err == nil {
-				minResource[corev1.ResourceCPU] = value
-			}
-		}
-	}
-
-	//Memory + MemoryOverhead correspond to executor's memory request
-	if app.Spec.Executor.Memory != nil {
-		if value, err := resource.ParseQuantity(*app.Spec.Executor.Memory); err == nil {
-			minResource[corev1.ResourceMemory] = value
-		}
-	}
-	if app.Spec.Executor.MemoryOverhead != nil {
-		if value, err := resource.ParseQuantity(*app.Spec.Executor.MemoryOverhead); err == nil {
-			if existing, ok := minResource[corev1.ResourceMemory]; ok {
-				existing.Add(value)
-				minResource[corev1.ResourceMemory] = existing
-			}
-		}
-	}
-
-	resourceList := []corev1.ResourceList{{}}
-	for i := int32(0); i < *app.Spec.Executor.Instances; i++ {
-		resourceList = append(resourceList, minResource)
-	}
-	return sumResourceList(resourceList)
-}
-
-func getDriverRequestResource(app *v1beta1.SparkApplication) corev1.ResourceList {
-	minResource := corev1.ResourceList{}
-
-	//Cores correspond to driver's core request
-	if app.Spec.Driver.Cores != nil {
-		if value, err := resource.ParseQuantity(fmt.Sprintf("%f", *app.Spec.Driver.Cores)); err == nil {
-			minResource[corev1.ResourceCPU] = value
-		}
-	}
-
-	//CoreLimit correspond to driver's core limit, this attribute will be used only when core request is empty.
-	if app.Spec.Driver.CoreLimit != nil {
-		if _, ok := minResource[corev1.ResourceCPU]; !ok {
-			if value, err := resource.ParseQuantity(*app.Spec.Driver.CoreLimit); err == nil {
-				minResource[corev1.ResourceCPU] = value
-			}
-		}
-	}
-
-	//Memory + MemoryOverhead correspond to driver's memory request
-	if app.Spec.Driver.Memory != nil {
-		if value, err := resource.ParseQuantity(*app.Spec.Driver.Memory); err == nil {
-			minResource[corev1.ResourceMemory] = value
-		}
-	}
-	if app.Spec.Driver.MemoryOverhead != nil {
-		if value, err := resource.ParseQuantity(*app.Spec.Driver.MemoryOverhead); err == nil {
-			if existing, ok := minResource[corev1.ResourceMemory]; ok {
-				existing.Add(value)
-				minResource[corev1.ResourceMemory] = existing
-			}
-		}
-	}
-
-	return minResource
-}
-
-func sumResourceList(list []corev1.ResourceList) corev1.ResourceList {
-	totalResource := corev1.ResourceList{}
-	for _, l := range list {
-		for name, quantity := range l {
-
-			if value, ok := totalResource[name]; !ok {
-				totalResource[name] = *quantity.Copy()
-			} else {
-				value.Add(quantity)
-				totalResource[name] = value
-			}
-		}
-	}
-	return totalResource
-}
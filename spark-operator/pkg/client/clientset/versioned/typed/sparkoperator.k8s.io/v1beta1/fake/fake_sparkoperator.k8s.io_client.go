@@ -38,6 +38,10 @@ func (c *FakeSparkoperatorV1beta1) SparkApplications(namespace string) v1beta1.S
 	return &FakeSparkApplications{c, namespace}
 }
 
+func (c *FakeSparkoperatorV1beta1) SparkPipelines(namespace string) v1beta1.SparkPipelineInterface {
+	return &FakeSparkPipelines{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeSparkoperatorV1beta1) RESTClient() rest.Interface {
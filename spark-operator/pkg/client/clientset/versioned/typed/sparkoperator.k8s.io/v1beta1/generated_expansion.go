@@ -23,3 +23,5 @@ package v1beta1
 type ScheduledSparkApplicationExpansion interface{}
 
 type SparkApplicationExpansion interface{}
+
+type SparkPipelineExpansion interface{}
@@ -31,6 +31,7 @@ type SparkoperatorV1beta1Interface interface {
 	RESTClient() rest.Interface
 	ScheduledSparkApplicationsGetter
 	SparkApplicationsGetter
+	SparkPipelinesGetter
 }
 
 // SparkoperatorV1beta1Client is used to interact with features provided by the sparkoperator.k8s.io group.
@@ -46,6 +47,10 @@ func (c *SparkoperatorV1beta1Client) SparkApplications(namespace string) SparkAp
 	return newSparkApplications(c, namespace)
 }
 
+func (c *SparkoperatorV1beta1Client) SparkPipelines(namespace string) SparkPipelineInterface {
+	return newSparkPipelines(c, namespace)
+}
+
 // NewForConfig creates a new SparkoperatorV1beta1Client for the given config.
 func NewForConfig(c *rest.Config) (*SparkoperatorV1beta1Client, error) {
 	config := *c
@@ -66,6 +66,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1beta1().ScheduledSparkApplications().Informer()}, nil
 	case v1beta1.SchemeGroupVersion.WithResource("sparkapplications"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1beta1().SparkApplications().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("sparkpipelines"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1beta1().SparkPipelines().Informer()}, nil
 
 	}
 
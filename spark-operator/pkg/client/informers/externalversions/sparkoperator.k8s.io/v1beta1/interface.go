@@ -30,6 +30,8 @@ type Interface interface {
 	ScheduledSparkApplications() ScheduledSparkApplicationInformer
 	// SparkApplications returns a SparkApplicationInformer.
 	SparkApplications() SparkApplicationInformer
+	// SparkPipelines returns a SparkPipelineInformer.
+	SparkPipelines() SparkPipelineInformer
 }
 
 type version struct {
@@ -52,3 +54,8 @@ func (v *version) ScheduledSparkApplications() ScheduledSparkApplicationInformer
 func (v *version) SparkApplications() SparkApplicationInformer {
 	return &sparkApplicationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// SparkPipelines returns a SparkPipelineInformer.
+func (v *version) SparkPipelines() SparkPipelineInformer {
+	return &sparkPipelineInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -35,3 +35,11 @@ type SparkApplicationListerExpansion interface{}
 // SparkApplicationNamespaceListerExpansion allows custom methods to be added to
 // SparkApplicationNamespaceLister.
 type SparkApplicationNamespaceListerExpansion interface{}
+
+// SparkPipelineListerExpansion allows custom methods to be added to
+// SparkPipelineLister.
+type SparkPipelineListerExpansion interface{}
+
+// SparkPipelineNamespaceListerExpansion allows custom methods to be added to
+// SparkPipelineNamespaceLister.
+type SparkPipelineNamespaceListerExpansion interface{}
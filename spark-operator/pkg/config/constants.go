@@ -42,6 +42,18 @@ const (
 	SparkAppNameLabel = LabelAnnotationPrefix + "app-name"
 	// ScheduledSparkAppNameLabel is the name of the label for the ScheduledSparkApplication object name.
 	ScheduledSparkAppNameLabel = LabelAnnotationPrefix + "scheduled-app-name"
+	// ScheduledTimeAnnotation is the name of the annotation recording the time a run of a ScheduledSparkApplication
+	// was scheduled at.
+	ScheduledTimeAnnotation = LabelAnnotationPrefix + "scheduled-time"
+	// BackfillScheduledAppNameLabel is the name of the label for the name of the ScheduledSparkApplication a run of a
+	// backfill belongs to.
+	BackfillScheduledAppNameLabel = LabelAnnotationPrefix + "backfill-scheduled-app-name"
+	// BackfillRunTimeLabel is the name of the label for the time a run of a backfill is scheduled at.
+	BackfillRunTimeLabel = LabelAnnotationPrefix + "backfill-run-time"
+	// SparkPipelineNameLabel is the name of the label for the SparkPipeline object name.
+	SparkPipelineNameLabel = LabelAnnotationPrefix + "pipeline-name"
+	// SparkPipelineStepLabel is the name of the label for the name of the SparkPipeline step a SparkApplication runs.
+	SparkPipelineStepLabel = LabelAnnotationPrefix + "pipeline-step"
 	// LaunchedBySparkOperatorLabel is a label on Spark pods launched through the Spark Operator.
 	LaunchedBySparkOperatorLabel = LabelAnnotationPrefix + "launched-by-spark-operator"
 	// SparkApplicationSelectorLabel is the AppID set by the spark-distribution on the driver/executors Pods.
@@ -54,6 +66,9 @@ const (
 	SparkExecutorRole = "executor"
 	// SubmissionIDLabel is the label that records the submission ID of the current run of an application.
 	SubmissionIDLabel = LabelAnnotationPrefix + "submission-id"
+	// CleanupFinalizer is the finalizer added to SparkApplications so the resources of an application get
+	// cleaned up before the application object is removed.
+	CleanupFinalizer = LabelAnnotationPrefix + "cleanup"
 )
 
 const (
@@ -135,6 +150,21 @@ const (
 	SparkDriverJavaOptions = "spark.driver.extraJavaOptions"
 	// SparkExecutorJavaOptions is the Spark configuration key for a string of extra JVM options to pass to executors.
 	SparkExecutorJavaOptions = "spark.executor.extraJavaOptions"
+	// SparkDynamicAllocationEnabled is the Spark configuration key for specifying if dynamic
+	// allocation is enabled or not.
+	SparkDynamicAllocationEnabled = "spark.dynamicAllocation.enabled"
+	// SparkDynamicAllocationShuffleTrackingEnabled is the Spark configuration key for
+	// specifying if shuffle data tracking is enabled.
+	SparkDynamicAllocationShuffleTrackingEnabled = "spark.dynamicAllocation.shuffleTracking.enabled"
+	// SparkDynamicAllocationInitialExecutors is the Spark configuration key for specifying
+	// the initial number of executors to request if dynamic allocation is enabled.
+	SparkDynamicAllocationInitialExecutors = "spark.dynamicAllocation.initialExecutors"
+	// SparkDynamicAllocationMinExecutors is the Spark configuration key for specifying the
+	// lower bound of the number of executors to request if dynamic allocation is enabled.
+	SparkDynamicAllocationMinExecutors = "spark.dynamicAllocation.minExecutors"
+	// SparkDynamicAllocationMaxExecutors is the Spark configuration key for specifying the
+	// upper bound of the number of executors to request if dynamic allocation is enabled.
+	SparkDynamicAllocationMaxExecutors = "spark.dynamicAllocation.maxExecutors"
 )
 
 const (
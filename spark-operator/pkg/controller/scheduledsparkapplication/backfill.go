@@ -0,0 +1,217 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledsparkapplication
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/robfig/cron"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// backfillRunTimeLayout is the layout of the values of the label recording the times runs of backfills are scheduled
+// at, which cannot contain colons.
+const backfillRunTimeLayout = "20060102T150405Z"
+
+// syncBackfill makes progress on the backfill requested by the spec of the application, if any. It starts a new
+// backfill if the time range or the request time of the spec differs from the one of the status, tracks the runs of
+// the backfill that have not finished yet, and creates the next runs as long as fewer than the maximum number of runs
+// are running. Runs of backfills are not runs of the schedule, so they are subject to neither the concurrency policy
+// nor the history limits of the application.
+func (c *Controller) syncBackfill(
+	app *v1beta1.ScheduledSparkApplication,
+	status *v1beta1.ScheduledSparkApplicationStatus,
+	schedule cron.Schedule,
+	location *time.Location,
+	now time.Time) error {
+	spec := app.Spec.Backfill
+	if spec == nil {
+		status.Backfill = nil
+		return nil
+	}
+
+	backfill := status.Backfill
+	if backfill == nil || !backfill.StartTime.Equal(&spec.StartTime) || !backfill.EndTime.Equal(&spec.EndTime) ||
+		!backfill.RequestTime.Equal(&spec.RequestTime) {
+		backfill = newBackfillStatus(spec, schedule, location, now)
+		status.Backfill = backfill
+		glog.Infof("Starting backfill of ScheduledSparkApplication %s/%s from %s to %s with %d runs", app.Namespace,
+			app.Name, spec.StartTime.Format(time.RFC3339), spec.EndTime.Format(time.RFC3339), backfill.TotalRuns)
+	}
+	if backfill.State != v1beta1.BackfillRunningState {
+		return nil
+	}
+
+	// The status is only changed once all active runs are looked up, so it stays consistent if one of them fails.
+	var activeRunNames []string
+	var completedRuns, failedRuns int32
+	for _, name := range backfill.ActiveRunNames {
+		run, err := c.getRun(app, name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case run == nil || run.failed:
+			failedRuns++
+		case run.completed:
+			completedRuns++
+		default:
+			activeRunNames = append(activeRunNames, name)
+		}
+	}
+	backfill.ActiveRunNames = activeRunNames
+	backfill.CompletedRuns += completedRuns
+	backfill.FailedRuns += failedRuns
+
+	maxParallel := 1
+	if spec.MaxParallel != nil {
+		maxParallel = int(*spec.MaxParallel)
+	}
+	for backfill.CreatedRuns < backfill.TotalRuns && len(backfill.ActiveRunNames) < maxParallel {
+		scheduledTime := backfill.NextRunTime.In(location)
+		name, err := c.startBackfillRun(app, backfill.RequestTime, now, scheduledTime)
+		if err != nil {
+			// The runs created so far are recorded, and the remaining ones are created when the sync is retried.
+			return err
+		}
+		backfill.ActiveRunNames = append(backfill.ActiveRunNames, name)
+		backfill.CreatedRuns++
+		backfill.NextRunTime = metav1.NewTime(getNextRunTime(schedule, scheduledTime, location))
+	}
+
+	if backfill.CreatedRuns == backfill.TotalRuns {
+		backfill.NextRunTime = metav1.Time{}
+		if len(backfill.ActiveRunNames) == 0 {
+			backfill.State = v1beta1.BackfillCompletedState
+			if backfill.FailedRuns > 0 {
+				backfill.State = v1beta1.BackfillFailedState
+			}
+			glog.Infof("Backfill of ScheduledSparkApplication %s/%s finished: %d runs completed, %d runs failed",
+				app.Namespace, app.Name, backfill.CompletedRuns, backfill.FailedRuns)
+		}
+	}
+	return nil
+}
+
+// newBackfillStatus returns the status of a new backfill with the given spec, counting the times in its time range
+// the schedule is due at, up to maxBackfillRuns. Times after now are left to the schedule.
+func newBackfillStatus(
+	spec *v1beta1.BackfillSpec,
+	schedule cron.Schedule,
+	location *time.Location,
+	now time.Time) *v1beta1.BackfillStatus {
+	backfill := &v1beta1.BackfillStatus{
+		StartTime:   spec.StartTime,
+		EndTime:     spec.EndTime,
+		RequestTime: spec.RequestTime,
+		State:       v1beta1.BackfillRunningState,
+	}
+
+	end := spec.EndTime.Time
+	if end.After(now) {
+		end = now
+	}
+	// Schedules are due at times after the given time, so the start time is included by starting a second before.
+	first := getNextRunTime(schedule, spec.StartTime.Add(-time.Second), location)
+	for t := first; !t.IsZero() && !t.After(end); t = getNextRunTime(schedule, t, location) {
+		if backfill.TotalRuns == maxBackfillRuns {
+			glog.Warningf("Backfill from %s to %s has more than %d runs, backfilling the first %d only",
+				spec.StartTime.Format(time.RFC3339), spec.EndTime.Format(time.RFC3339), maxBackfillRuns,
+				maxBackfillRuns)
+			break
+		}
+		if backfill.TotalRuns == 0 {
+			backfill.NextRunTime = metav1.NewTime(t)
+		}
+		backfill.TotalRuns++
+	}
+	if backfill.TotalRuns == 0 {
+		backfill.State = v1beta1.BackfillCompletedState
+	}
+	return backfill
+}
+
+// startBackfillRun starts a run of the backfill of the application scheduled at the given time. The run is labeled
+// with the time, but not as a run of the schedule. Its name is derived from the times only, so a run that was created
+// by a sync failing before recording it is not created again.
+func (c *Controller) startBackfillRun(
+	app *v1beta1.ScheduledSparkApplication,
+	requestTime metav1.Time,
+	now time.Time,
+	scheduledTime time.Time) (string, error) {
+	name := getBackfillRunName(app.Name, requestTime, scheduledTime)
+	runLabels := map[string]string{
+		config.BackfillScheduledAppNameLabel: app.Name,
+		config.BackfillRunTimeLabel:          scheduledTime.UTC().Format(backfillRunTimeLayout),
+	}
+	glog.V(2).Infof("Creating backfill run %s of ScheduledSparkApplication %s/%s scheduled at %s", name,
+		app.Namespace, app.Name, scheduledTime.Format(time.RFC3339))
+	if _, err := c.createRun(app, name, runLabels, now, scheduledTime); err != nil && !errors.IsAlreadyExists(err) {
+		return "", err
+	}
+	return name, nil
+}
+
+// getBackfillRunName returns the name of the run of a backfill requested at the given time that is scheduled at the
+// given time.
+func getBackfillRunName(appName string, requestTime metav1.Time, scheduledTime time.Time) string {
+	if requestTime.IsZero() {
+		return fmt.Sprintf("%s-backfill-%d", appName, scheduledTime.Unix())
+	}
+	return fmt.Sprintf("%s-backfill-%d-%d", appName, requestTime.Unix(), scheduledTime.Unix())
+}
+
+// getRun returns the run of the application with the given name, or nil if it does not exist. Runs not in the cache
+// yet, e.g., right after they are created, are looked up on the API server.
+func (c *Controller) getRun(app *v1beta1.ScheduledSparkApplication, name string) (*scheduledRun, error) {
+	var run scheduledRun
+	if app.Spec.PipelineTemplate != nil {
+		pipeline, err := c.pipelineLister.SparkPipelines(app.Namespace).Get(name)
+		if errors.IsNotFound(err) {
+			pipeline, err = c.crdClient.SparkoperatorV1beta1().SparkPipelines(app.Namespace).Get(name,
+				metav1.GetOptions{})
+		}
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		run = newPipelineRun(pipeline)
+	} else {
+		sparkApp, err := c.saLister.SparkApplications(app.Namespace).Get(name)
+		if errors.IsNotFound(err) {
+			sparkApp, err = c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(name,
+				metav1.GetOptions{})
+		}
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		run = newApplicationRun(sparkApp)
+	}
+	return &run, nil
+}
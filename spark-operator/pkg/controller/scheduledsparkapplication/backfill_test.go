@@ -0,0 +1,365 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledsparkapplication
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron"
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubetesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestSyncScheduledSparkApplication_Backfill(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-backfill",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule:          "0 2 * * *",
+			TimeZone:          "UTC",
+			ConcurrencyPolicy: v1beta1.ConcurrencyForbid,
+			Template: v1beta1.SparkApplicationSpec{
+				Arguments: []string{"--date={{date .ScheduledTime}}"},
+			},
+			ExpandTemplates: boolptr(true),
+			Backfill: &v1beta1.BackfillSpec{
+				StartTime:   metav1.NewTime(time.Date(2019, 5, 1, 2, 0, 0, 0, time.UTC)),
+				EndTime:     metav1.NewTime(time.Date(2019, 5, 4, 2, 0, 0, 0, time.UTC)),
+				MaxParallel: int32ptr(2),
+			},
+		},
+	}
+	c, clk := newFakeController()
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+	options := metav1.GetOptions{}
+
+	setRunState := func(name string, state v1beta1.ApplicationStateType) {
+		run, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(name, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		run.Status.AppState.State = state
+		c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Update(run)
+	}
+
+	// The first two of the four runs in the range are created.
+	clk.SetTime(time.Date(2019, 5, 10, 12, 0, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	backfill := app.Status.Backfill
+	if assert.NotNil(t, backfill) {
+		assert.Equal(t, v1beta1.BackfillRunningState, backfill.State)
+		assert.Equal(t, int32(4), backfill.TotalRuns)
+		assert.Equal(t, int32(2), backfill.CreatedRuns)
+		assert.Equal(t, 2, len(backfill.ActiveRunNames))
+		assert.True(t, time.Date(2019, 5, 3, 2, 0, 0, 0, time.UTC).Equal(backfill.NextRunTime.Time))
+	}
+	// Runs of the backfill are not runs of the schedule.
+	assert.Empty(t, app.Status.LastRunName)
+
+	firstRun, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(
+		backfill.ActiveRunNames[0], options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, app.Name, firstRun.Labels[config.BackfillScheduledAppNameLabel])
+	assert.Equal(t, "20190501T020000Z", firstRun.Labels[config.BackfillRunTimeLabel])
+	assert.Empty(t, firstRun.Labels[config.ScheduledSparkAppNameLabel])
+	assert.Equal(t, "2019-05-01T02:00:00Z", firstRun.Annotations[config.ScheduledTimeAnnotation])
+	assert.Equal(t, []string{"--date=2019-05-01"}, firstRun.Spec.Arguments)
+
+	// Nothing new is created while the maximum number of runs are running.
+	clk.Step(time.Minute)
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.Equal(t, int32(2), app.Status.Backfill.CreatedRuns)
+
+	// The remaining runs are created as the first runs finish.
+	setRunState(backfill.ActiveRunNames[0], v1beta1.CompletedState)
+	setRunState(backfill.ActiveRunNames[1], v1beta1.FailedState)
+	clk.Step(time.Minute)
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	backfill = app.Status.Backfill
+	assert.Equal(t, v1beta1.BackfillRunningState, backfill.State)
+	assert.Equal(t, int32(4), backfill.CreatedRuns)
+	assert.Equal(t, int32(1), backfill.CompletedRuns)
+	assert.Equal(t, int32(1), backfill.FailedRuns)
+	assert.Equal(t, 2, len(backfill.ActiveRunNames))
+	assert.True(t, backfill.NextRunTime.IsZero())
+
+	// The backfill fails once all runs finished, as one of them failed.
+	setRunState(backfill.ActiveRunNames[0], v1beta1.CompletedState)
+	setRunState(backfill.ActiveRunNames[1], v1beta1.CompletedState)
+	clk.Step(time.Minute)
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	backfill = app.Status.Backfill
+	assert.Equal(t, v1beta1.BackfillFailedState, backfill.State)
+	assert.Equal(t, int32(3), backfill.CompletedRuns)
+	assert.Equal(t, int32(1), backfill.FailedRuns)
+	assert.Empty(t, backfill.ActiveRunNames)
+
+	// Changing the time range starts a new backfill.
+	app.Spec.Backfill.StartTime = metav1.NewTime(time.Date(2019, 5, 2, 0, 0, 0, 0, time.UTC))
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Update(app)
+	clk.Step(time.Minute)
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	backfill = app.Status.Backfill
+	assert.Equal(t, v1beta1.BackfillRunningState, backfill.State)
+	assert.Equal(t, int32(3), backfill.TotalRuns)
+	assert.Equal(t, int32(2), backfill.CreatedRuns)
+
+	// Requesting the same time range again starts a new backfill, whose runs do not collide with the earlier ones.
+	requestTime := metav1.NewTime(time.Date(2019, 5, 10, 12, 5, 0, 0, time.UTC))
+	app.Spec.Backfill.RequestTime = requestTime
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Update(app)
+	clk.Step(time.Minute)
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	backfill = app.Status.Backfill
+	assert.Equal(t, v1beta1.BackfillRunningState, backfill.State)
+	assert.True(t, requestTime.Equal(&backfill.RequestTime))
+	assert.Equal(t, int32(3), backfill.TotalRuns)
+	assert.Equal(t, int32(2), backfill.CreatedRuns)
+	assert.Equal(t, int32(0), backfill.CompletedRuns)
+	assert.Equal(t, []string{
+		getBackfillRunName(app.Name, requestTime, time.Date(2019, 5, 2, 2, 0, 0, 0, time.UTC)),
+		getBackfillRunName(app.Name, requestTime, time.Date(2019, 5, 3, 2, 0, 0, 0, time.UTC)),
+	}, backfill.ActiveRunNames)
+
+	// Removing the backfill from the spec clears its status.
+	app.Spec.Backfill = nil
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Update(app)
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.Nil(t, app.Status.Backfill)
+}
+
+func TestSyncScheduledSparkApplication_BackfillCreateFailure(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-backfill-failure",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule:          "0 2 * * *",
+			TimeZone:          "UTC",
+			ConcurrencyPolicy: v1beta1.ConcurrencyAllow,
+			Backfill: &v1beta1.BackfillSpec{
+				StartTime:   metav1.NewTime(time.Date(2019, 5, 1, 2, 0, 0, 0, time.UTC)),
+				EndTime:     metav1.NewTime(time.Date(2019, 5, 3, 2, 0, 0, 0, time.UTC)),
+				MaxParallel: int32ptr(3),
+			},
+		},
+		Status: v1beta1.ScheduledSparkApplicationStatus{
+			NextRun: metav1.NewTime(time.Date(2019, 5, 10, 2, 0, 0, 0, time.UTC)),
+		},
+	}
+	c, clk := newFakeController()
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+	options := metav1.GetOptions{}
+
+	runName := func(scheduledTime time.Time) string {
+		return fmt.Sprintf("%s-backfill-%d", app.Name, scheduledTime.Unix())
+	}
+	secondRunTime := time.Date(2019, 5, 2, 2, 0, 0, 0, time.UTC)
+	failCreate := true
+	c.crdClient.(*crdclientfake.Clientset).PrependReactor("create", "sparkapplications",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.CreateAction).GetObject().(*v1beta1.SparkApplication)
+			if failCreate && obj.Name == runName(secondRunTime) {
+				return true, nil, fmt.Errorf("failed to create %s", obj.Name)
+			}
+			return false, nil, nil
+		})
+
+	// Creating the second run of the backfill fails, but the scheduled run and the first run of the backfill created
+	// before are recorded.
+	clk.SetTime(time.Date(2019, 5, 10, 2, 0, 30, 0, time.UTC))
+	assert.Error(t, c.syncScheduledSparkApplication(key))
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.NotEmpty(t, app.Status.LastRunName)
+	assert.True(t, time.Date(2019, 5, 11, 2, 0, 0, 0, time.UTC).Equal(app.Status.NextRun.Time))
+	backfill := app.Status.Backfill
+	if assert.NotNil(t, backfill) {
+		assert.Equal(t, int32(1), backfill.CreatedRuns)
+		assert.Equal(t, []string{runName(time.Date(2019, 5, 1, 2, 0, 0, 0, time.UTC))}, backfill.ActiveRunNames)
+		assert.True(t, secondRunTime.Equal(backfill.NextRunTime.Time))
+	}
+
+	// A run that exists already, e.g., because it was created by a sync failing before recording it, is not created
+	// again.
+	failCreate = false
+	existingRun := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Namespace: app.Namespace, Name: runName(secondRunTime)},
+	}
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(existingRun); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	backfill = app.Status.Backfill
+	assert.Equal(t, int32(3), backfill.CreatedRuns)
+	assert.Contains(t, backfill.ActiveRunNames, runName(secondRunTime))
+	runs, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The scheduled run and the three runs of the backfill.
+	assert.Equal(t, 4, len(runs.Items))
+}
+
+func TestBackfillRunEvents(t *testing.T) {
+	c, _ := newFakeController()
+
+	running := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo-backfill-1556676000",
+			Labels:    map[string]string{config.BackfillScheduledAppNameLabel: "foo"},
+		},
+		Status: v1beta1.SparkApplicationStatus{AppState: v1beta1.ApplicationState{State: v1beta1.RunningState}},
+	}
+	completed := running.DeepCopy()
+	completed.Status.AppState.State = v1beta1.CompletedState
+
+	// Runs that keep running and runs of the schedule do not trigger a sync.
+	c.onRunUpdate(running, running)
+	unlabeledRunning, unlabeledCompleted := running.DeepCopy(), completed.DeepCopy()
+	unlabeledRunning.Labels, unlabeledCompleted.Labels = nil, nil
+	c.onRunUpdate(unlabeledRunning, unlabeledCompleted)
+	assert.Equal(t, 0, c.queue.Len())
+
+	// A run of a backfill finishing triggers a sync of the application it belongs to.
+	c.onRunUpdate(running, completed)
+	assert.Equal(t, 1, c.queue.Len())
+	key, _ := c.queue.Get()
+	assert.Equal(t, "default/foo", key)
+	c.queue.Done(key)
+
+	// So does a run of a backfill being deleted.
+	pipeline := &v1beta1.SparkPipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "bar-backfill-1556676000",
+			Labels:    map[string]string{config.BackfillScheduledAppNameLabel: "bar"},
+		},
+	}
+	c.onRunDelete(cache.DeletedFinalStateUnknown{Key: "default/bar-backfill-1556676000", Obj: pipeline})
+	assert.Equal(t, 1, c.queue.Len())
+	key, _ = c.queue.Get()
+	assert.Equal(t, "default/bar", key)
+	c.queue.Done(key)
+}
+
+func TestNewBackfillStatus(t *testing.T) {
+	schedule, err := cron.ParseStandard("0 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2019, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	type testcase struct {
+		name                string
+		start               time.Time
+		end                 time.Time
+		expectedState       v1beta1.BackfillState
+		expectedTotalRuns   int32
+		expectedNextRunTime time.Time
+	}
+
+	testcases := []testcase{
+		{
+			name:                "inclusive range",
+			start:               time.Date(2019, 5, 1, 2, 0, 0, 0, time.UTC),
+			end:                 time.Date(2019, 5, 3, 2, 0, 0, 0, time.UTC),
+			expectedState:       v1beta1.BackfillRunningState,
+			expectedTotalRuns:   3,
+			expectedNextRunTime: time.Date(2019, 5, 1, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:                "range between runs",
+			start:               time.Date(2019, 5, 1, 3, 0, 0, 0, time.UTC),
+			end:                 time.Date(2019, 5, 3, 1, 0, 0, 0, time.UTC),
+			expectedState:       v1beta1.BackfillRunningState,
+			expectedTotalRuns:   1,
+			expectedNextRunTime: time.Date(2019, 5, 2, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:                "range ending in the future",
+			start:               time.Date(2019, 5, 8, 0, 0, 0, 0, time.UTC),
+			end:                 time.Date(2019, 5, 20, 0, 0, 0, 0, time.UTC),
+			expectedState:       v1beta1.BackfillRunningState,
+			expectedTotalRuns:   3,
+			expectedNextRunTime: time.Date(2019, 5, 8, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:                "range over the maximum number of runs",
+			start:               time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:                 time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC),
+			expectedState:       v1beta1.BackfillRunningState,
+			expectedTotalRuns:   maxBackfillRuns,
+			expectedNextRunTime: time.Date(1990, 1, 1, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "empty range",
+			start:         time.Date(2019, 5, 1, 3, 0, 0, 0, time.UTC),
+			end:           time.Date(2019, 5, 1, 4, 0, 0, 0, time.UTC),
+			expectedState: v1beta1.BackfillCompletedState,
+		},
+	}
+
+	for _, test := range testcases {
+		spec := &v1beta1.BackfillSpec{StartTime: metav1.NewTime(test.start), EndTime: metav1.NewTime(test.end)}
+		backfill := newBackfillStatus(spec, schedule, time.UTC, now)
+		assert.Equal(t, test.expectedState, backfill.State, test.name)
+		assert.Equal(t, test.expectedTotalRuns, backfill.TotalRuns, test.name)
+		assert.True(t, test.expectedNextRunTime.Equal(backfill.NextRunTime.Time), test.name)
+	}
+}
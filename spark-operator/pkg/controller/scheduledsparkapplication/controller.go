@@ -23,9 +23,12 @@ import (
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/robfig/cron"
 
+	apiv1 "k8s.io/api/core/v1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/clock"
@@ -33,7 +36,9 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 
@@ -43,6 +48,7 @@ import (
 	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
 	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
 var (
@@ -57,7 +63,12 @@ type Controller struct {
 	cacheSynced      cache.InformerSynced
 	ssaLister        crdlisters.ScheduledSparkApplicationLister
 	saLister         crdlisters.SparkApplicationLister
+	pipelineLister   crdlisters.SparkPipelineLister
+	recorder         record.EventRecorder
 	clock            clock.Clock
+
+	metricLabels  []string
+	schedulingLag *prometheus.SummaryVec
 }
 
 func NewController(
@@ -65,29 +76,70 @@ func NewController(
 	kubeClient kubernetes.Interface,
 	extensionsClient apiextensionsclient.Interface,
 	informerFactory crdinformers.SharedInformerFactory,
+	metricsConfig *util.MetricConfig,
 	clock clock.Clock) *Controller {
 	crdscheme.AddToScheme(scheme.Scheme)
 
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(),
 		"scheduled-spark-application-controller")
 
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(glog.V(2).Infof)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+		Interface: kubeClient.CoreV1().Events(apiv1.NamespaceAll),
+	})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, apiv1.EventSource{Component: "spark-operator"})
+
 	controller := &Controller{
 		crdClient:        crdClient,
 		kubeClient:       kubeClient,
 		extensionsClient: extensionsClient,
 		queue:            queue,
+		recorder:         recorder,
 		clock:            clock,
 	}
 
+	if metricsConfig != nil {
+		controller.metricLabels = make([]string, len(metricsConfig.MetricsLabels))
+		for i, label := range metricsConfig.MetricsLabels {
+			controller.metricLabels[i] = util.CreateValidMetricNameLabel("", label)
+		}
+		controller.schedulingLag = prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Name: util.CreateValidMetricNameLabel(metricsConfig.MetricsPrefix,
+					"spark_scheduled_app_scheduling_lag_microseconds"),
+				Help: "Delay of the start of Scheduled Spark App runs after their scheduled time via the Operator",
+			},
+			controller.metricLabels,
+		)
+		util.RegisterMetric(controller.schedulingLag)
+	}
+
 	informer := informerFactory.Sparkoperator().V1beta1().ScheduledSparkApplications()
 	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    controller.onAdd,
 		UpdateFunc: controller.onUpdate,
 		DeleteFunc: controller.onDelete,
 	})
-	controller.cacheSynced = informer.Informer().HasSynced
 	controller.ssaLister = informer.Lister()
-	controller.saLister = informerFactory.Sparkoperator().V1beta1().SparkApplications().Lister()
+
+	// The next runs of a backfill are created as earlier ones finish, so a run of a backfill finishing or being
+	// deleted triggers a sync of the application it belongs to.
+	runHandler := cache.ResourceEventHandlerFuncs{
+		UpdateFunc: controller.onRunUpdate,
+		DeleteFunc: controller.onRunDelete,
+	}
+	saInformer := informerFactory.Sparkoperator().V1beta1().SparkApplications()
+	saInformer.Informer().AddEventHandler(runHandler)
+	controller.saLister = saInformer.Lister()
+	pipelineInformer := informerFactory.Sparkoperator().V1beta1().SparkPipelines()
+	pipelineInformer.Informer().AddEventHandler(runHandler)
+	controller.pipelineLister = pipelineInformer.Lister()
+
+	controller.cacheSynced = func() bool {
+		return informer.Informer().HasSynced() && saInformer.Informer().HasSynced() &&
+			pipelineInformer.Informer().HasSynced()
+	}
 
 	return controller
 }
@@ -153,54 +205,180 @@ func (c *Controller) syncScheduledSparkApplication(key string) error {
 		return err
 	}
 	app, err := c.ssaLister.ScheduledSparkApplications(namespace).Get(name)
-	if err != nil {
+	if errors.IsNotFound(err) {
+		// The application was deleted after a sync of it was queued, e.g., to wake it up for its next run.
+		return nil
+	} else if err != nil {
 		return err
 	}
 
 	if app.Spec.Suspend != nil && *app.Spec.Suspend {
-		return nil
+		// The next run is cleared while the application is suspended, so the runs that were due while it was
+		// suspended are neither started nor recorded as missed when it is resumed.
+		if app.Status.NextRun.IsZero() {
+			return nil
+		}
+		status := app.Status.DeepCopy()
+		status.NextRun = metav1.Time{}
+		return c.updateScheduledSparkApplicationStatus(app, status)
 	}
 
 	glog.V(2).Infof("Syncing ScheduledSparkApplication %s/%s", app.Namespace, app.Name)
 	status := app.Status.DeepCopy()
-	schedule, err := cron.ParseStandard(app.Spec.Schedule)
+	var syncErr error
+	schedule, location, err := parseSchedule(app)
 	if err != nil {
 		glog.Errorf("failed to parse schedule %s of ScheduledSparkApplication %s/%s: %v", app.Spec.Schedule, app.Namespace, app.Name, err)
 		status.ScheduleState = v1beta1.FailedValidationState
 		status.Reason = err.Error()
+	} else if err = validateRunTemplates(app); err != nil {
+		glog.Errorf("invalid templates of ScheduledSparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		status.ScheduleState = v1beta1.FailedValidationState
+		status.Reason = err.Error()
 	} else {
 		status.ScheduleState = v1beta1.ScheduledState
-		now := c.clock.Now()
-		nextRunTime := status.NextRun.Time
-		if nextRunTime.IsZero() {
-			// The first run of the application.
-			nextRunTime = schedule.Next(now)
-			status.NextRun = metav1.NewTime(nextRunTime)
-		}
-		if nextRunTime.Before(now) {
-			// Check if the condition for starting the next run is satisfied.
-			ok, err := c.shouldStartNextRun(app)
-			if err != nil {
-				return err
-			}
-			if ok {
-				glog.Infof("Next run of ScheduledSparkApplication %s/%s is due, creating a new SparkApplication instance", app.Namespace, app.Name)
-				name, err := c.startNextRun(app, now)
-				if err != nil {
-					return err
-				}
-				status.LastRun = metav1.NewTime(now)
-				status.NextRun = metav1.NewTime(schedule.Next(status.LastRun.Time))
-				status.LastRunName = name
-			}
+		syncErr = c.syncRuns(app, status, schedule, location)
+	}
+
+	// The status is updated even if syncing the runs failed, so the runs created before the failure are not
+	// created again when the sync is retried.
+	if err := c.updateScheduledSparkApplicationStatus(app, status); err != nil {
+		return err
+	}
+	if syncErr != nil {
+		return syncErr
+	}
+	if status.ScheduleState == v1beta1.ScheduledState {
+		c.enqueueAtNextRun(key, status.NextRun.Time)
+	}
+	return nil
+}
+
+// syncRuns starts the due run of the application if there is one, bookkeeps its past runs, and makes progress on its
+// backfill. The status records the runs created before an error is returned.
+func (c *Controller) syncRuns(
+	app *v1beta1.ScheduledSparkApplication,
+	status *v1beta1.ScheduledSparkApplicationStatus,
+	schedule cron.Schedule,
+	location *time.Location) error {
+	now := c.clock.Now()
+	nextRunTime := status.NextRun.Time
+	if nextRunTime.IsZero() {
+		// The first run of the application.
+		nextRunTime = getNextRunTime(schedule, now, location)
+		status.NextRun = metav1.NewTime(nextRunTime)
+	}
+	if !nextRunTime.After(now) {
+		if err := c.startDueRun(app, status, schedule, location, now); err != nil {
+			return err
 		}
+	}
+
+	if err := c.checkAndUpdatePastRuns(app, status); err != nil {
+		return err
+	}
 
-		if err = c.checkAndUpdatePastRuns(app, status); err != nil {
+	return c.syncBackfill(app, status, schedule, location, now)
+}
+
+// startDueRun starts the oldest due run of the application that is not missed if the concurrency policy allows it.
+// Runs that were missed according to the starting deadline and the missed run policy are recorded and given up.
+func (c *Controller) startDueRun(
+	app *v1beta1.ScheduledSparkApplication,
+	status *v1beta1.ScheduledSparkApplicationStatus,
+	schedule cron.Schedule,
+	location *time.Location,
+	now time.Time) error {
+	dueTimes, ok := getDueRunTimes(schedule, status.NextRun.Time, now, location)
+	if !ok {
+		glog.Warningf("More than %d runs of ScheduledSparkApplication %s/%s are due since %s, skipping to the next run",
+			maxDueRuns, app.Namespace, app.Name, status.NextRun.Format(time.RFC3339))
+		status.NextRun = metav1.NewTime(getNextRunTime(schedule, now, location))
+		return nil
+	}
+	toStart, missed := selectDueRuns(dueTimes, now, app.Spec.StartingDeadlineSeconds, app.Spec.MissedRunPolicy)
+	if len(missed) > 0 {
+		forbidden, err := c.isForbiddenByLastRun(app)
+		if err != nil {
 			return err
 		}
+		if forbidden {
+			for i := range missed {
+				missed[i].Reason = v1beta1.MissedRunConcurrencyForbidden
+			}
+		}
+		c.recordMissedRunEvents(app, missed)
+		recordMissedRuns(status, missed)
 	}
 
-	return c.updateScheduledSparkApplicationStatus(app, status)
+	if len(toStart) == 0 {
+		status.NextRun = metav1.NewTime(getNextRunTime(schedule, now, location))
+		return nil
+	}
+	// The scheduled time is the one templates in the spec of the run are expanded with, in the time zone of the
+	// schedule.
+	scheduledTime := toStart[0].In(location)
+	status.NextRun = metav1.NewTime(scheduledTime)
+
+	// Check if the condition for starting the next run is satisfied. If it is not, the run stays due until it
+	// is started or missed.
+	ok, err := c.shouldStartNextRun(app)
+	if err != nil || !ok {
+		return err
+	}
+	glog.Infof("Next run of ScheduledSparkApplication %s/%s is due, creating a new instance", app.Namespace, app.Name)
+	name, err := c.startNextRun(app, now, scheduledTime)
+	if err != nil {
+		return err
+	}
+	c.exportSchedulingLag(app, now.Sub(scheduledTime))
+	status.LastRun = metav1.NewTime(now)
+	status.LastRunScheduledTime = metav1.NewTime(scheduledTime)
+	status.LastRunName = name
+	if len(toStart) > 1 {
+		// Catch up on the remaining due runs one at a time.
+		status.NextRun = metav1.NewTime(toStart[1])
+		c.enqueue(app)
+	} else {
+		status.NextRun = metav1.NewTime(getNextRunTime(schedule, now, location))
+	}
+	return nil
+}
+
+func (c *Controller) recordMissedRunEvents(app *v1beta1.ScheduledSparkApplication, missed []v1beta1.MissedRun) {
+	// Only the most recent missed runs are reported individually, like they are recorded in the status.
+	if len(missed) > maxRecordedMissedRuns {
+		c.recorder.Eventf(app, apiv1.EventTypeWarning, "ScheduledSparkApplicationRunsMissed",
+			"%d runs scheduled from %s to %s were missed", len(missed)-maxRecordedMissedRuns,
+			missed[0].ScheduledTime.Format(time.RFC3339),
+			missed[len(missed)-maxRecordedMissedRuns-1].ScheduledTime.Format(time.RFC3339))
+		missed = missed[len(missed)-maxRecordedMissedRuns:]
+	}
+	for _, run := range missed {
+		c.recorder.Eventf(app, apiv1.EventTypeWarning, "ScheduledSparkApplicationRunMissed",
+			"Run scheduled at %s was missed: %s", run.ScheduledTime.Format(time.RFC3339), run.Reason)
+	}
+}
+
+// enqueueAtNextRun enqueues the key of an application for the time its next run is due at, so the run starts on
+// time instead of on the next resync. Runs that are overdue already are left to the next resync, e.g., when the
+// concurrency policy keeps them from starting.
+func (c *Controller) enqueueAtNextRun(key string, nextRun time.Time) {
+	if delay := nextRun.Sub(c.clock.Now()); delay > 0 {
+		glog.V(2).Infof("Next run of ScheduledSparkApplication %s is due in %v", key, delay)
+		c.queue.AddAfter(key, delay)
+	}
+}
+
+func (c *Controller) exportSchedulingLag(app *v1beta1.ScheduledSparkApplication, lag time.Duration) {
+	if c.schedulingLag == nil {
+		return
+	}
+	if m, err := c.schedulingLag.GetMetricWith(util.FetchMetricLabels(app.Labels, c.metricLabels)); err != nil {
+		glog.Errorf("Error while exporting metrics: %v", err)
+	} else {
+		m.Observe(float64(lag / time.Microsecond))
+	}
 }
 
 func (c *Controller) onAdd(obj interface{}) {
@@ -215,6 +393,47 @@ func (c *Controller) onDelete(obj interface{}) {
 	c.dequeue(obj)
 }
 
+func (c *Controller) onRunUpdate(oldObj, newObj interface{}) {
+	oldRun, _ := getRunObject(oldObj)
+	newRun, runLabels := getRunObject(newObj)
+	if oldRun == nil || newRun == nil || oldRun.finished() || !newRun.finished() {
+		return
+	}
+	c.enqueueBackfillOwner(newRun, runLabels)
+}
+
+func (c *Controller) onRunDelete(obj interface{}) {
+	if run, runLabels := getRunObject(obj); run != nil {
+		c.enqueueBackfillOwner(run, runLabels)
+	}
+}
+
+// enqueueBackfillOwner enqueues the application the run belongs to if it is a run of a backfill.
+func (c *Controller) enqueueBackfillOwner(run *scheduledRun, runLabels map[string]string) {
+	appName, ok := runLabels[config.BackfillScheduledAppNameLabel]
+	if !ok {
+		return
+	}
+	c.queue.Add(run.namespace + "/" + appName)
+}
+
+// getRunObject returns the run of the given SparkApplication or SparkPipeline along with its labels, or nil if the
+// object is neither.
+func getRunObject(obj interface{}) (*scheduledRun, map[string]string) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	switch o := obj.(type) {
+	case *v1beta1.SparkApplication:
+		run := newApplicationRun(o)
+		return &run, o.Labels
+	case *v1beta1.SparkPipeline:
+		run := newPipelineRun(o)
+		return &run, o.Labels
+	}
+	return nil, nil
+}
+
 func (c *Controller) enqueue(obj interface{}) {
 	key, err := keyFunc(obj)
 	if err != nil {
@@ -237,10 +456,19 @@ func (c *Controller) dequeue(obj interface{}) {
 }
 
 func (c *Controller) createSparkApplication(
-	scheduledApp *v1beta1.ScheduledSparkApplication, t time.Time) (string, error) {
+	scheduledApp *v1beta1.ScheduledSparkApplication,
+	name string,
+	runLabels map[string]string,
+	t time.Time,
+	scheduledTime time.Time) (string, error) {
 	app := &v1beta1.SparkApplication{}
-	app.Spec = scheduledApp.Spec.Template
-	app.Name = fmt.Sprintf("%s-%d", scheduledApp.Name, t.UnixNano())
+	app.Spec = *scheduledApp.Spec.Template.DeepCopy()
+	app.Name = name
+	if expandsRunTemplates(scheduledApp) {
+		if err := util.ExpandRunTemplates(&app.Spec, newRunTemplateData(scheduledApp, app.Name, t, scheduledTime)); err != nil {
+			return "", err
+		}
+	}
 	app.OwnerReferences = append(app.OwnerReferences, metav1.OwnerReference{
 		APIVersion: v1beta1.SchemeGroupVersion.String(),
 		Kind:       reflect.TypeOf(v1beta1.ScheduledSparkApplication{}).Name(),
@@ -251,7 +479,12 @@ func (c *Controller) createSparkApplication(
 	for key, value := range scheduledApp.Labels {
 		app.ObjectMeta.Labels[key] = value
 	}
-	app.ObjectMeta.Labels[config.ScheduledSparkAppNameLabel] = scheduledApp.Name
+	for key, value := range runLabels {
+		app.ObjectMeta.Labels[key] = value
+	}
+	app.ObjectMeta.Annotations = map[string]string{
+		config.ScheduledTimeAnnotation: scheduledTime.UTC().Format(time.RFC3339),
+	}
 	_, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(scheduledApp.Namespace).Create(app)
 	if err != nil {
 		return "", err
@@ -259,22 +492,62 @@ func (c *Controller) createSparkApplication(
 	return app.Name, nil
 }
 
+func (c *Controller) createSparkPipeline(
+	scheduledApp *v1beta1.ScheduledSparkApplication,
+	name string,
+	runLabels map[string]string,
+	t time.Time,
+	scheduledTime time.Time) (string, error) {
+	pipeline := &v1beta1.SparkPipeline{}
+	pipeline.Spec = *scheduledApp.Spec.PipelineTemplate.DeepCopy()
+	pipeline.Name = name
+	if expandsRunTemplates(scheduledApp) {
+		data := newRunTemplateData(scheduledApp, pipeline.Name, t, scheduledTime)
+		for i := range pipeline.Spec.Steps {
+			if err := util.ExpandRunTemplates(&pipeline.Spec.Steps[i].Template, data); err != nil {
+				return "", fmt.Errorf("step %s: %v", pipeline.Spec.Steps[i].Name, err)
+			}
+		}
+	}
+	pipeline.OwnerReferences = append(pipeline.OwnerReferences, metav1.OwnerReference{
+		APIVersion: v1beta1.SchemeGroupVersion.String(),
+		Kind:       reflect.TypeOf(v1beta1.ScheduledSparkApplication{}).Name(),
+		Name:       scheduledApp.Name,
+		UID:        scheduledApp.UID,
+	})
+	pipeline.ObjectMeta.Labels = make(map[string]string)
+	for key, value := range scheduledApp.Labels {
+		pipeline.ObjectMeta.Labels[key] = value
+	}
+	for key, value := range runLabels {
+		pipeline.ObjectMeta.Labels[key] = value
+	}
+	pipeline.ObjectMeta.Annotations = map[string]string{
+		config.ScheduledTimeAnnotation: scheduledTime.UTC().Format(time.RFC3339),
+	}
+	_, err := c.crdClient.SparkoperatorV1beta1().SparkPipelines(scheduledApp.Namespace).Create(pipeline)
+	if err != nil {
+		return "", err
+	}
+	return pipeline.Name, nil
+}
+
 func (c *Controller) shouldStartNextRun(app *v1beta1.ScheduledSparkApplication) (bool, error) {
-	sortedApps, err := c.listSparkApplications(app)
+	sortedRuns, err := c.listRuns(app)
 	if err != nil {
 		return false, err
 	}
-	if len(sortedApps) == 0 {
+	if len(sortedRuns) == 0 {
 		return true, nil
 	}
 
 	// The last run (most recently started) is the first one in the sorted slice.
-	lastRun := sortedApps[0]
+	lastRun := sortedRuns[0]
 	switch app.Spec.ConcurrencyPolicy {
 	case v1beta1.ConcurrencyAllow:
 		return true, nil
 	case v1beta1.ConcurrencyForbid:
-		return c.hasLastRunFinished(lastRun), nil
+		return lastRun.finished(), nil
 	case v1beta1.ConcurrencyReplace:
 		if err := c.killLastRunIfNotFinished(lastRun); err != nil {
 			return false, err
@@ -284,8 +557,43 @@ func (c *Controller) shouldStartNextRun(app *v1beta1.ScheduledSparkApplication)
 	return true, nil
 }
 
-func (c *Controller) startNextRun(app *v1beta1.ScheduledSparkApplication, now time.Time) (string, error) {
-	name, err := c.createSparkApplication(app, now)
+// isForbiddenByLastRun tells if the concurrency policy of the application keeps a new run from starting because the
+// last run is still running.
+func (c *Controller) isForbiddenByLastRun(app *v1beta1.ScheduledSparkApplication) (bool, error) {
+	if app.Spec.ConcurrencyPolicy != v1beta1.ConcurrencyForbid {
+		return false, nil
+	}
+	sortedRuns, err := c.listRuns(app)
+	if err != nil {
+		return false, err
+	}
+	return len(sortedRuns) > 0 && !sortedRuns[0].finished(), nil
+}
+
+// startNextRun starts a run of the application scheduled at the given time.
+func (c *Controller) startNextRun(app *v1beta1.ScheduledSparkApplication, now time.Time, scheduledTime time.Time) (string, error) {
+	name := fmt.Sprintf("%s-%d", app.Name, now.UnixNano())
+	return c.createRun(app, name, map[string]string{config.ScheduledSparkAppNameLabel: app.Name}, now, scheduledTime)
+}
+
+// createRun creates the SparkPipeline of a run of the application if it has a pipeline template, or the
+// SparkApplication of the run otherwise.
+func (c *Controller) createRun(
+	app *v1beta1.ScheduledSparkApplication,
+	runName string,
+	runLabels map[string]string,
+	now time.Time,
+	scheduledTime time.Time) (string, error) {
+	if app.Spec.PipelineTemplate != nil {
+		name, err := c.createSparkPipeline(app, runName, runLabels, now, scheduledTime)
+		if err != nil {
+			glog.Errorf("failed to create a SparkPipeline instance for ScheduledSparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+			return "", err
+		}
+		return name, nil
+	}
+
+	name, err := c.createSparkApplication(app, runName, runLabels, now, scheduledTime)
 	if err != nil {
 		glog.Errorf("failed to create a SparkApplication instance for ScheduledSparkApplication %s/%s: %v", app.Namespace, app.Name, err)
 		return "", err
@@ -293,52 +601,52 @@ func (c *Controller) startNextRun(app *v1beta1.ScheduledSparkApplication, now ti
 	return name, nil
 }
 
-func (c *Controller) hasLastRunFinished(app *v1beta1.SparkApplication) bool {
-	return app.Status.AppState.State == v1beta1.CompletedState ||
-		app.Status.AppState.State == v1beta1.FailedState
-}
-
-func (c *Controller) killLastRunIfNotFinished(app *v1beta1.SparkApplication) error {
-	finished := c.hasLastRunFinished(app)
-	if finished {
+func (c *Controller) killLastRunIfNotFinished(run scheduledRun) error {
+	if run.finished() {
 		return nil
 	}
 
-	// Delete the SparkApplication object of the last run.
-	if err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Delete(app.Name,
-		metav1.NewDeleteOptions(0)); err != nil {
-		return err
-	}
+	// Delete the SparkApplication or SparkPipeline object of the last run.
+	return c.deleteRun(run)
+}
 
-	return nil
+func (c *Controller) deleteRun(run scheduledRun) error {
+	if run.pipeline {
+		return c.crdClient.SparkoperatorV1beta1().SparkPipelines(run.namespace).Delete(run.name,
+			metav1.NewDeleteOptions(0))
+	}
+	return c.crdClient.SparkoperatorV1beta1().SparkApplications(run.namespace).Delete(run.name,
+		metav1.NewDeleteOptions(0))
 }
 
 func (c *Controller) checkAndUpdatePastRuns(
 	app *v1beta1.ScheduledSparkApplication,
 	status *v1beta1.ScheduledSparkApplicationStatus) error {
-	sortedApps, err := c.listSparkApplications(app)
+	sortedRuns, err := c.listRuns(app)
 	if err != nil {
 		return err
 	}
 
 	var completedRuns []string
 	var failedRuns []string
-	for _, a := range sortedApps {
-		if a.Status.AppState.State == v1beta1.CompletedState {
-			completedRuns = append(completedRuns, a.Name)
-		} else if a.Status.AppState.State == v1beta1.FailedState {
-			failedRuns = append(failedRuns, a.Name)
+	runsByName := make(map[string]scheduledRun)
+	for _, r := range sortedRuns {
+		runsByName[r.name] = r
+		if r.completed {
+			completedRuns = append(completedRuns, r.name)
+		} else if r.failed {
+			failedRuns = append(failedRuns, r.name)
 		}
 	}
 
 	var toDelete []string
 	status.PastSuccessfulRunNames, toDelete = bookkeepPastRuns(completedRuns, app.Spec.SuccessfulRunHistoryLimit)
 	for _, name := range toDelete {
-		c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Delete(name, metav1.NewDeleteOptions(0))
+		c.deleteRun(runsByName[name])
 	}
 	status.PastFailedRunNames, toDelete = bookkeepPastRuns(failedRuns, app.Spec.FailedRunHistoryLimit)
 	for _, name := range toDelete {
-		c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Delete(name, metav1.NewDeleteOptions(0))
+		c.deleteRun(runsByName[name])
 	}
 
 	return nil
@@ -355,7 +663,7 @@ func (c *Controller) updateScheduledSparkApplicationStatus(
 	toUpdate := app.DeepCopy()
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		toUpdate.Status = *newStatus
-		_, updateErr := c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(toUpdate.Namespace).Update(
+		_, updateErr := c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(toUpdate.Namespace).UpdateStatus(
 			toUpdate)
 		if updateErr == nil {
 			return nil
@@ -372,15 +680,30 @@ func (c *Controller) updateScheduledSparkApplicationStatus(
 	})
 }
 
-func (c *Controller) listSparkApplications(app *v1beta1.ScheduledSparkApplication) (sparkApps, error) {
+// listRuns lists the runs of the ScheduledSparkApplication, most recent first. The runs are SparkPipelines if the
+// ScheduledSparkApplication has a pipeline template, or SparkApplications otherwise.
+func (c *Controller) listRuns(app *v1beta1.ScheduledSparkApplication) (scheduledRuns, error) {
 	set := labels.Set{config.ScheduledSparkAppNameLabel: app.Name}
-	apps, err := c.saLister.SparkApplications(app.Namespace).List(set.AsSelector())
-	if err != nil {
-		return nil, fmt.Errorf("failed to list SparkApplications: %v", err)
+	var runs scheduledRuns
+	if app.Spec.PipelineTemplate != nil {
+		pipelines, err := c.pipelineLister.SparkPipelines(app.Namespace).List(set.AsSelector())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list SparkPipelines: %v", err)
+		}
+		for _, p := range pipelines {
+			runs = append(runs, newPipelineRun(p))
+		}
+	} else {
+		apps, err := c.saLister.SparkApplications(app.Namespace).List(set.AsSelector())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list SparkApplications: %v", err)
+		}
+		for _, a := range apps {
+			runs = append(runs, newApplicationRun(a))
+		}
 	}
-	sortedApps := sparkApps(apps)
-	sort.Sort(sortedApps)
-	return sortedApps, nil
+	sort.Sort(runs)
+	return runs, nil
 }
 
 func bookkeepPastRuns(names []string, runLimit *int32) (toKeep []string, toDelete []string) {
@@ -400,8 +723,12 @@ func bookkeepPastRuns(names []string, runLimit *int32) (toKeep []string, toDelet
 func isStatusEqual(newStatus, currentStatus *v1beta1.ScheduledSparkApplicationStatus) bool {
 	return newStatus.ScheduleState == currentStatus.ScheduleState &&
 		newStatus.LastRun == currentStatus.LastRun &&
+		newStatus.LastRunScheduledTime == currentStatus.LastRunScheduledTime &&
 		newStatus.NextRun == currentStatus.NextRun &&
 		newStatus.LastRunName == currentStatus.LastRunName &&
+		reflect.DeepEqual(newStatus.MissedRuns, currentStatus.MissedRuns) &&
+		newStatus.MissedRunCount == currentStatus.MissedRunCount &&
+		reflect.DeepEqual(newStatus.Backfill, currentStatus.Backfill) &&
 		reflect.DeepEqual(newStatus.PastSuccessfulRunNames, currentStatus.PastSuccessfulRunNames) &&
 		reflect.DeepEqual(newStatus.PastFailedRunNames, currentStatus.PastFailedRunNames) &&
 		newStatus.Reason == currentStatus.Reason
@@ -20,6 +20,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/robfig/cron"
 	"github.com/stretchr/testify/assert"
 
 	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
@@ -29,6 +30,8 @@ import (
 	kubeclientfake "k8s.io/client-go/kubernetes/fake"
 	kubetesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
@@ -462,13 +465,680 @@ func TestCheckAndUpdatePastRuns(t *testing.T) {
 	assert.Nil(t, existing)
 }
 
+func TestPipelineRuns(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule:          "@every 1m",
+			ConcurrencyPolicy: v1beta1.ConcurrencyForbid,
+			PipelineTemplate: &v1beta1.SparkPipelineSpec{
+				Steps: []v1beta1.PipelineStep{{Name: "extract"}},
+			},
+		},
+	}
+	c, clk := newFakeController()
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+
+	// A run of a ScheduledSparkApplication with a pipeline template is a SparkPipeline.
+	name, err := c.startNextRun(app, clk.Now(), clk.Now())
+	assert.Nil(t, err)
+	run, err := c.crdClient.SparkoperatorV1beta1().SparkPipelines(app.Namespace).Get(name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, app.Name, run.Labels[config.ScheduledSparkAppNameLabel])
+	assert.Equal(t, "extract", run.Spec.Steps[0].Name)
+	_, err = c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(name, metav1.GetOptions{})
+	assert.NotNil(t, err)
+
+	// ConcurrencyForbid with a running pipeline.
+	run.Status.State = v1beta1.PipelineRunningState
+	c.crdClient.SparkoperatorV1beta1().SparkPipelines(app.Namespace).Update(run)
+	ok, _ := c.shouldStartNextRun(app)
+	assert.False(t, ok)
+
+	// ConcurrencyForbid with a completed pipeline.
+	run.Status.State = v1beta1.PipelineCompletedState
+	c.crdClient.SparkoperatorV1beta1().SparkPipelines(app.Namespace).Update(run)
+	ok, _ = c.shouldStartNextRun(app)
+	assert.True(t, ok)
+
+	// The completed pipeline should have been recorded.
+	status := app.Status.DeepCopy()
+	c.checkAndUpdatePastRuns(app, status)
+	assert.Equal(t, []string{run.Name}, status.PastSuccessfulRunNames)
+}
+
+func TestSyncScheduledSparkApplication_TimeZone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-time-zone",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule:          "30 1 * * *",
+			TimeZone:          "America/New_York",
+			ConcurrencyPolicy: v1beta1.ConcurrencyAllow,
+		},
+	}
+	c, clk := newFakeController()
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+	options := metav1.GetOptions{}
+
+	// The clocks are set back from 2:00 EDT to 1:00 EST on November 3, 2019 in New York.
+	clk.SetTime(time.Date(2019, 11, 3, 0, 0, 0, 0, newYork))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.Equal(t, v1beta1.ScheduledState, app.Status.ScheduleState)
+	assert.True(t, time.Date(2019, 11, 3, 5, 30, 0, 0, time.UTC).Equal(app.Status.NextRun.Time))
+
+	// The run is started at the first 1:30, and the next run is scheduled for the next day instead of the second 1:30.
+	clk.SetTime(time.Date(2019, 11, 3, 5, 31, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	firstRunName := app.Status.LastRunName
+	assert.NotEmpty(t, firstRunName)
+	assert.True(t, time.Date(2019, 11, 4, 6, 30, 0, 0, time.UTC).Equal(app.Status.NextRun.Time))
+
+	clk.SetTime(time.Date(2019, 11, 3, 6, 31, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.Equal(t, firstRunName, app.Status.LastRunName)
+}
+
+func TestSyncScheduledSparkApplication_InvalidTimeZone(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-invalid-time-zone",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule: "CRON_TZ=Mars/Olympus_Mons 30 1 * * *",
+		},
+	}
+	c, _ := newFakeController()
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Equal(t, v1beta1.FailedValidationState, app.Status.ScheduleState)
+	assert.True(t, app.Status.NextRun.IsZero())
+}
+
+func TestSyncScheduledSparkApplication_TemplatedRun(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-templated",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule:          "0 1 * * *",
+			TimeZone:          "America/New_York",
+			ConcurrencyPolicy: v1beta1.ConcurrencyAllow,
+			Template: v1beta1.SparkApplicationSpec{
+				Arguments: []string{"--date={{date .ScheduledTime}}", "--run={{.RunName}}"},
+				SparkConf: map[string]string{
+					"spark.eventLog.dir": "gs://logs/{{.ScheduledAppName}}/{{formatTime \"2006/01/02\" .ScheduledTime}}",
+				},
+			},
+			ExpandTemplates: boolptr(true),
+		},
+	}
+	c, clk := newFakeController()
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+	options := metav1.GetOptions{}
+
+	// The run scheduled at 1:00 EDT on May 2, 2019 is on May 1 in UTC.
+	clk.SetTime(time.Date(2019, 5, 2, 4, 0, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	clk.SetTime(time.Date(2019, 5, 2, 5, 0, 10, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	run, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Status.LastRunName, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"--date=2019-05-02", "--run=" + run.Name}, run.Spec.Arguments)
+	assert.Equal(t, "gs://logs/test-app-templated/2019/05/02", run.Spec.SparkConf["spark.eventLog.dir"])
+	// The template itself is left untouched.
+	assert.Equal(t, "--date={{date .ScheduledTime}}", app.Spec.Template.Arguments[0])
+}
+
+func TestSyncScheduledSparkApplication_InvalidTemplate(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-invalid-template",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule: "@every 1h",
+			Template: v1beta1.SparkApplicationSpec{
+				Arguments: []string{"--date={{date .ScheduledTime"},
+			},
+			ExpandTemplates: boolptr(true),
+		},
+	}
+	c, _ := newFakeController()
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Equal(t, v1beta1.FailedValidationState, app.Status.ScheduleState)
+	assert.Contains(t, app.Status.Reason, "arguments[0]")
+}
+
+func TestSyncScheduledSparkApplication_TemplatesNotExpanded(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-literal-template",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule: "@every 1m",
+			Template: v1beta1.SparkApplicationSpec{
+				SparkConf: map[string]string{"spark.executor.extraJavaOptions": "-Dapp={{APP_ID}}"},
+			},
+		},
+	}
+	c, clk := newFakeController()
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+	options := metav1.GetOptions{}
+
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	clk.Step(1 * time.Minute)
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.Equal(t, v1beta1.ScheduledState, app.Status.ScheduleState)
+	run, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Status.LastRunName, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Values are used as they are unless template expansion is enabled.
+	assert.Equal(t, "-Dapp={{APP_ID}}", run.Spec.SparkConf["spark.executor.extraJavaOptions"])
+}
+
+// delayRecordingQueue is a work queue recording the delays keys are added after.
+type delayRecordingQueue struct {
+	workqueue.RateLimitingInterface
+	delays map[interface{}]time.Duration
+}
+
+func (q *delayRecordingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.delays[item] = duration
+}
+
+func TestSyncScheduledSparkApplication_TriggerTiming(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-timing",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule:          "0 2 * * *",
+			TimeZone:          "UTC",
+			ConcurrencyPolicy: v1beta1.ConcurrencyAllow,
+		},
+	}
+	c, clk := newFakeController()
+	queue := &delayRecordingQueue{delays: make(map[interface{}]time.Duration)}
+	c.queue = queue
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+	options := metav1.GetOptions{}
+
+	// The application is synced again exactly when its next run is due.
+	clk.SetTime(time.Date(2019, 5, 1, 1, 59, 30, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 30*time.Second, queue.delays[key])
+
+	clk.SetTime(time.Date(2019, 5, 1, 2, 0, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.NotEmpty(t, app.Status.LastRunName)
+	assert.True(t, time.Date(2019, 5, 1, 2, 0, 0, 0, time.UTC).Equal(app.Status.LastRunScheduledTime.Time))
+	assert.Equal(t, 24*time.Hour, queue.delays[key])
+	run, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Status.LastRunName, options)
+	assert.Nil(t, err)
+	assert.Equal(t, "2019-05-01T02:00:00Z", run.Annotations[config.ScheduledTimeAnnotation])
+
+	// A run starting late still records the time it was scheduled at.
+	clk.SetTime(time.Date(2019, 5, 2, 2, 0, 45, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.True(t, time.Date(2019, 5, 2, 2, 0, 45, 0, time.UTC).Equal(app.Status.LastRun.Time))
+	assert.True(t, time.Date(2019, 5, 2, 2, 0, 0, 0, time.UTC).Equal(app.Status.LastRunScheduledTime.Time))
+	assert.Equal(t, 24*time.Hour-45*time.Second, queue.delays[key])
+}
+
+func TestSyncScheduledSparkApplication_Deleted(t *testing.T) {
+	c, _ := newFakeController()
+
+	// A sync queued for the next run of an application deleted in the meantime is dropped.
+	assert.Nil(t, c.syncScheduledSparkApplication("default/test-app-deleted"))
+}
+
+func TestSyncScheduledSparkApplication_MissedRuns(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-missed-runs",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule:          "0 * * * *",
+			TimeZone:          "UTC",
+			ConcurrencyPolicy: v1beta1.ConcurrencyForbid,
+		},
+	}
+	c, clk := newFakeController()
+	recorder := record.NewFakeRecorder(10)
+	c.recorder = recorder
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+	options := metav1.GetOptions{}
+
+	clk.SetTime(time.Date(2019, 5, 1, 0, 30, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// The operator was down across the runs at 1:00, 2:00 and 3:00, so only the run at 4:00 is started.
+	clk.SetTime(time.Date(2019, 5, 1, 4, 0, 30, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.NotEmpty(t, app.Status.LastRunName)
+	assert.True(t, time.Date(2019, 5, 1, 4, 0, 0, 0, time.UTC).Equal(app.Status.LastRunScheduledTime.Time))
+	assert.Equal(t, int32(3), app.Status.MissedRunCount)
+	assert.Equal(t, 3, len(app.Status.MissedRuns))
+	for i, run := range app.Status.MissedRuns {
+		assert.True(t, time.Date(2019, 5, 1, i+1, 0, 0, 0, time.UTC).Equal(run.ScheduledTime.Time))
+		assert.Equal(t, v1beta1.MissedRunOvertaken, run.Reason)
+	}
+	assert.Equal(t, 3, len(recorder.Events))
+	for len(recorder.Events) > 0 {
+		assert.Contains(t, <-recorder.Events, "ScheduledSparkApplicationRunMissed")
+	}
+
+	// The run at 5:00 waits for the run at 4:00, which is still running, and is missed once the run at 6:00 is due.
+	firstRunName := app.Status.LastRunName
+	clk.SetTime(time.Date(2019, 5, 1, 5, 0, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.Equal(t, firstRunName, app.Status.LastRunName)
+	assert.True(t, time.Date(2019, 5, 1, 5, 0, 0, 0, time.UTC).Equal(app.Status.NextRun.Time))
+
+	clk.SetTime(time.Date(2019, 5, 1, 6, 0, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.Equal(t, firstRunName, app.Status.LastRunName)
+	assert.Equal(t, int32(4), app.Status.MissedRunCount)
+	lastMissed := app.Status.MissedRuns[len(app.Status.MissedRuns)-1]
+	assert.True(t, time.Date(2019, 5, 1, 5, 0, 0, 0, time.UTC).Equal(lastMissed.ScheduledTime.Time))
+	assert.Equal(t, v1beta1.MissedRunConcurrencyForbidden, lastMissed.Reason)
+	assert.True(t, time.Date(2019, 5, 1, 6, 0, 0, 0, time.UTC).Equal(app.Status.NextRun.Time))
+}
+
+func TestSyncScheduledSparkApplication_RunAllMissedRuns(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-run-all",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule:          "0 * * * *",
+			TimeZone:          "UTC",
+			ConcurrencyPolicy: v1beta1.ConcurrencyAllow,
+			MissedRunPolicy:   &v1beta1.MissedRunPolicy{Type: v1beta1.MissedRunRunAll, MaxRuns: int32ptr(2)},
+		},
+	}
+	c, clk := newFakeController()
+	c.recorder = record.NewFakeRecorder(10)
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+	options := metav1.GetOptions{}
+
+	clk.SetTime(time.Date(2019, 5, 1, 0, 30, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// The two most recent runs are started one after the other, and the older one is missed.
+	clk.SetTime(time.Date(2019, 5, 1, 3, 0, 30, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.True(t, time.Date(2019, 5, 1, 2, 0, 0, 0, time.UTC).Equal(app.Status.LastRunScheduledTime.Time))
+	assert.True(t, time.Date(2019, 5, 1, 3, 0, 0, 0, time.UTC).Equal(app.Status.NextRun.Time))
+	assert.Equal(t, []v1beta1.MissedRun{{
+		ScheduledTime: metav1.NewTime(time.Date(2019, 5, 1, 1, 0, 0, 0, time.UTC)),
+		Reason:        v1beta1.MissedRunLimitExceeded,
+	}}, app.Status.MissedRuns)
+
+	clk.Step(time.Second)
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.True(t, time.Date(2019, 5, 1, 3, 0, 0, 0, time.UTC).Equal(app.Status.LastRunScheduledTime.Time))
+	assert.True(t, time.Date(2019, 5, 1, 4, 0, 0, 0, time.UTC).Equal(app.Status.NextRun.Time))
+	assert.Equal(t, int32(1), app.Status.MissedRunCount)
+}
+
+func TestSyncScheduledSparkApplication_SuspendAndResume(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-suspend",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule:          "0 * * * *",
+			TimeZone:          "UTC",
+			ConcurrencyPolicy: v1beta1.ConcurrencyAllow,
+			MissedRunPolicy:   &v1beta1.MissedRunPolicy{Type: v1beta1.MissedRunRunAll},
+		},
+	}
+	c, clk := newFakeController()
+	c.recorder = record.NewFakeRecorder(10)
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+	options := metav1.GetOptions{}
+
+	clk.SetTime(time.Date(2019, 5, 1, 0, 30, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next run is cleared while the application is suspended.
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	app.Spec.Suspend = boolptr(true)
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Update(app)
+	clk.SetTime(time.Date(2019, 5, 1, 1, 30, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.True(t, app.Status.NextRun.IsZero())
+
+	// The runs due while the application was suspended are neither started nor missed when it is resumed.
+	app.Spec.Suspend = boolptr(false)
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Update(app)
+	clk.SetTime(time.Date(2019, 5, 1, 5, 30, 0, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name, options)
+	assert.True(t, time.Date(2019, 5, 1, 6, 0, 0, 0, time.UTC).Equal(app.Status.NextRun.Time))
+	assert.Empty(t, app.Status.LastRunName)
+	assert.Empty(t, app.Status.MissedRuns)
+	assert.Equal(t, int32(0), app.Status.MissedRunCount)
+	runs, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, runs.Items)
+}
+
+func TestSyncScheduledSparkApplication_StaleNextRun(t *testing.T) {
+	app := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-app-stale",
+		},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule:          "* * * * *",
+			TimeZone:          "UTC",
+			ConcurrencyPolicy: v1beta1.ConcurrencyAllow,
+			MissedRunPolicy:   &v1beta1.MissedRunPolicy{Type: v1beta1.MissedRunRunAll},
+		},
+		Status: v1beta1.ScheduledSparkApplicationStatus{
+			NextRun: metav1.NewTime(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)),
+		},
+	}
+	c, clk := newFakeController()
+	c.recorder = record.NewFakeRecorder(10)
+	c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+
+	// Far more runs than maxDueRuns are due, so the application skips to its next run after now.
+	clk.SetTime(time.Date(2019, 5, 1, 0, 0, 30, 0, time.UTC))
+	if err := c.syncScheduledSparkApplication(key); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Get(app.Name,
+		metav1.GetOptions{})
+	assert.True(t, time.Date(2019, 5, 1, 0, 1, 0, 0, time.UTC).Equal(app.Status.NextRun.Time))
+	assert.Empty(t, app.Status.LastRunName)
+	assert.Equal(t, int32(0), app.Status.MissedRunCount)
+}
+
+func TestSelectDueRuns(t *testing.T) {
+	hour := func(h int) time.Time {
+		return time.Date(2019, 5, 1, h, 0, 0, 0, time.UTC)
+	}
+	missed := func(h int, reason v1beta1.MissedRunReason) v1beta1.MissedRun {
+		return v1beta1.MissedRun{ScheduledTime: metav1.NewTime(hour(h)), Reason: reason}
+	}
+	dueTimes := []time.Time{hour(1), hour(2), hour(3), hour(4)}
+	now := hour(4).Add(10 * time.Minute)
+	deadline := int64(3600)
+	shortDeadline := int64(60)
+
+	type testcase struct {
+		name            string
+		deadline        *int64
+		policy          *v1beta1.MissedRunPolicy
+		expectedToStart []time.Time
+		expectedMissed  []v1beta1.MissedRun
+	}
+
+	testcases := []testcase{
+		{
+			name:            "skip by default",
+			expectedToStart: []time.Time{hour(4)},
+			expectedMissed: []v1beta1.MissedRun{
+				missed(1, v1beta1.MissedRunOvertaken),
+				missed(2, v1beta1.MissedRunOvertaken),
+				missed(3, v1beta1.MissedRunOvertaken),
+			},
+		},
+		{
+			name:     "skip past the deadline",
+			deadline: &shortDeadline,
+			policy:   &v1beta1.MissedRunPolicy{Type: v1beta1.MissedRunSkip},
+			expectedMissed: []v1beta1.MissedRun{
+				missed(1, v1beta1.MissedRunDeadlineExceeded),
+				missed(2, v1beta1.MissedRunDeadlineExceeded),
+				missed(3, v1beta1.MissedRunDeadlineExceeded),
+				missed(4, v1beta1.MissedRunDeadlineExceeded),
+			},
+		},
+		{
+			name:            "run once past the deadline",
+			deadline:        &shortDeadline,
+			policy:          &v1beta1.MissedRunPolicy{Type: v1beta1.MissedRunRunOnce},
+			expectedToStart: []time.Time{hour(4)},
+			expectedMissed: []v1beta1.MissedRun{
+				missed(1, v1beta1.MissedRunDeadlineExceeded),
+				missed(2, v1beta1.MissedRunDeadlineExceeded),
+				missed(3, v1beta1.MissedRunDeadlineExceeded),
+			},
+		},
+		{
+			name:            "run all",
+			policy:          &v1beta1.MissedRunPolicy{Type: v1beta1.MissedRunRunAll},
+			expectedToStart: []time.Time{hour(1), hour(2), hour(3), hour(4)},
+		},
+		{
+			name:            "run all within the deadline",
+			deadline:        &deadline,
+			policy:          &v1beta1.MissedRunPolicy{Type: v1beta1.MissedRunRunAll},
+			expectedToStart: []time.Time{hour(4)},
+			expectedMissed: []v1beta1.MissedRun{
+				missed(1, v1beta1.MissedRunDeadlineExceeded),
+				missed(2, v1beta1.MissedRunDeadlineExceeded),
+				missed(3, v1beta1.MissedRunDeadlineExceeded),
+			},
+		},
+		{
+			name:            "run all up to the limit",
+			policy:          &v1beta1.MissedRunPolicy{Type: v1beta1.MissedRunRunAll, MaxRuns: int32ptr(3)},
+			expectedToStart: []time.Time{hour(2), hour(3), hour(4)},
+			expectedMissed:  []v1beta1.MissedRun{missed(1, v1beta1.MissedRunLimitExceeded)},
+		},
+	}
+
+	for _, test := range testcases {
+		toStart, missed := selectDueRuns(dueTimes, now, test.deadline, test.policy)
+		assert.Equal(t, test.expectedToStart, toStart, test.name)
+		assert.Equal(t, test.expectedMissed, missed, test.name)
+	}
+}
+
+func int32ptr(n int32) *int32 {
+	return &n
+}
+
+func boolptr(b bool) *bool {
+	return &b
+}
+
+func TestGetNextRunTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testcase struct {
+		name     string
+		schedule string
+		location *time.Location
+		after    time.Time
+		expected time.Time
+	}
+
+	testcases := []testcase{
+		{
+			name:     "standard time",
+			schedule: "0 2 * * *",
+			location: berlin,
+			after:    time.Date(2019, 1, 10, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2019, 1, 10, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "daylight saving time",
+			schedule: "0 2 * * *",
+			location: berlin,
+			after:    time.Date(2019, 7, 10, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2019, 7, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// 2:30 does not exist on March 10, 2019 in New York, so the run of that day is skipped.
+			name:     "clocks set forward",
+			schedule: "30 2 * * *",
+			location: newYork,
+			after:    time.Date(2019, 3, 9, 12, 0, 0, 0, newYork),
+			expected: time.Date(2019, 3, 11, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "clocks set forward before the gap",
+			schedule: "30 1 * * *",
+			location: newYork,
+			after:    time.Date(2019, 3, 9, 12, 0, 0, 0, newYork),
+			expected: time.Date(2019, 3, 10, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			// 1:30 occurs twice on November 3, 2019 in New York, and the run of that day starts at the first 1:30.
+			name:     "clocks set back",
+			schedule: "30 1 * * *",
+			location: newYork,
+			after:    time.Date(2019, 11, 3, 0, 0, 0, 0, newYork),
+			expected: time.Date(2019, 11, 3, 5, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "clocks set back after the first occurrence",
+			schedule: "30 1 * * *",
+			location: newYork,
+			after:    time.Date(2019, 11, 3, 5, 30, 0, 0, time.UTC),
+			expected: time.Date(2019, 11, 4, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			// Schedules running every hour keep running on elapsed time through the second pass.
+			name:     "clocks set back hourly",
+			schedule: "*/30 * * * *",
+			location: newYork,
+			after:    time.Date(2019, 11, 3, 5, 45, 0, 0, time.UTC),
+			expected: time.Date(2019, 11, 3, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "clocks set back fixed interval",
+			schedule: "@every 1h",
+			location: newYork,
+			after:    time.Date(2019, 11, 3, 5, 30, 0, 0, time.UTC),
+			expected: time.Date(2019, 11, 3, 6, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range testcases {
+		schedule, err := cron.ParseStandard(test.schedule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		next := getNextRunTime(schedule, test.after, test.location)
+		assert.True(t, test.expected.Equal(next), "%s: expected %v, got %v", test.name, test.expected, next)
+	}
+}
+
 func newFakeController() (*Controller, *clock.FakeClock) {
 	crdClient := crdclientfake.NewSimpleClientset()
 	kubeClient := kubeclientfake.NewSimpleClientset()
 	apiExtensionsClient := apiextensionsfake.NewSimpleClientset()
 	informerFactory := crdinformers.NewSharedInformerFactory(crdClient, 1*time.Second)
 	clk := clock.NewFakeClock(time.Now())
-	controller := NewController(crdClient, kubeClient, apiExtensionsClient, informerFactory, clk)
+	controller := NewController(crdClient, kubeClient, apiExtensionsClient, informerFactory, nil, clk)
 	ssaInformer := informerFactory.Sparkoperator().V1beta1().ScheduledSparkApplications().Informer()
 	saInformer := informerFactory.Sparkoperator().V1beta1().SparkApplications().Informer()
 	crdClient.PrependReactor("create", "scheduledsparkapplications",
@@ -483,6 +1153,19 @@ func newFakeController() (*Controller, *clock.FakeClock) {
 			ssaInformer.GetStore().Update(obj)
 			return false, obj, nil
 		})
+	pipelineInformer := informerFactory.Sparkoperator().V1beta1().SparkPipelines().Informer()
+	crdClient.PrependReactor("create", "sparkpipelines",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.CreateAction).GetObject()
+			pipelineInformer.GetStore().Add(obj)
+			return false, obj, nil
+		})
+	crdClient.PrependReactor("update", "sparkpipelines",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.UpdateAction).GetObject()
+			pipelineInformer.GetStore().Update(obj)
+			return false, obj, nil
+		})
 	crdClient.PrependReactor("create", "sparkapplications",
 		func(action kubetesting.Action) (bool, runtime.Object, error) {
 			obj := action.(kubetesting.CreateAction).GetObject()
@@ -17,20 +17,243 @@ limitations under the License.
 package scheduledsparkapplication
 
 import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
-type sparkApps []*v1beta1.SparkApplication
+// allHours is the set of all the hours of a day in the format of cron.SpecSchedule.
+const allHours = 1<<24 - 1
+
+const (
+	// defaultMaxMissedRuns is the default maximum number of due runs started by the RunAll missed run policy.
+	defaultMaxMissedRuns = 10
+	// maxRecordedMissedRuns is the maximum number of missed runs recorded in the status.
+	maxRecordedMissedRuns = 10
+	// maxDueRuns is the maximum number of due runs of an application enumerated at once. Applications with more due
+	// runs, e.g., after the operator was down for a long time, skip to their next run after now instead.
+	maxDueRuns = 1000
+	// maxBackfillRuns is the maximum number of runs of a backfill. Later times in the range of the backfill are not
+	// backfilled.
+	maxBackfillRuns = 10000
+)
+
+// scheduledRun is a run of a ScheduledSparkApplication, backed by either a SparkApplication or a SparkPipeline.
+type scheduledRun struct {
+	name      string
+	namespace string
+	pipeline  bool
+	completed bool
+	failed    bool
+}
+
+func newApplicationRun(app *v1beta1.SparkApplication) scheduledRun {
+	return scheduledRun{
+		name:      app.Name,
+		namespace: app.Namespace,
+		completed: app.Status.AppState.State == v1beta1.CompletedState,
+		failed:    app.Status.AppState.State == v1beta1.FailedState,
+	}
+}
+
+func newPipelineRun(pipeline *v1beta1.SparkPipeline) scheduledRun {
+	return scheduledRun{
+		name:      pipeline.Name,
+		namespace: pipeline.Namespace,
+		pipeline:  true,
+		completed: pipeline.Status.State == v1beta1.PipelineCompletedState,
+		failed: pipeline.Status.State == v1beta1.PipelineFailedState ||
+			pipeline.Status.State == v1beta1.PipelineFailedValidationState,
+	}
+}
+
+func (r scheduledRun) finished() bool {
+	return r.completed || r.failed
+}
+
+type scheduledRuns []scheduledRun
 
-func (s sparkApps) Len() int {
+func (s scheduledRuns) Len() int {
 	return len(s)
 }
 
-func (s sparkApps) Swap(i, j int) {
+func (s scheduledRuns) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
 
-func (s sparkApps) Less(i, j int) bool {
-	// Sort by decreasing order of application names and correspondingly creation time.
-	return s[i].Name > s[j].Name
+func (s scheduledRuns) Less(i, j int) bool {
+	// Sort by decreasing order of run names and correspondingly creation time.
+	return s[i].name > s[j].name
+}
+
+// parseSchedule parses the schedule of the application, and returns it with the location it is evaluated in.
+func parseSchedule(app *v1beta1.ScheduledSparkApplication) (cron.Schedule, *time.Location, error) {
+	spec, location, err := util.GetScheduleLocation(app.Spec.Schedule, app.Spec.TimeZone)
+	if err != nil {
+		return nil, nil, err
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schedule, location, nil
+}
+
+// expandsRunTemplates tells if the templates in the spec of the runs of the application are to be expanded.
+func expandsRunTemplates(app *v1beta1.ScheduledSparkApplication) bool {
+	return app.Spec.ExpandTemplates != nil && *app.Spec.ExpandTemplates
+}
+
+// validateRunTemplates checks that the templates in the spec of the runs of the application can be expanded, if they
+// are to be expanded.
+func validateRunTemplates(app *v1beta1.ScheduledSparkApplication) error {
+	if !expandsRunTemplates(app) {
+		return nil
+	}
+	if app.Spec.PipelineTemplate == nil {
+		return util.ValidateRunTemplates(&app.Spec.Template)
+	}
+	for i := range app.Spec.PipelineTemplate.Steps {
+		step := &app.Spec.PipelineTemplate.Steps[i]
+		if err := util.ValidateRunTemplates(&step.Template); err != nil {
+			return fmt.Errorf("step %s: %v", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// newRunTemplateData returns the data the templates in the spec of a run of the application are expanded with. The
+// run is created at the given time for the given scheduled time, whose time zone is the one of the schedule.
+func newRunTemplateData(
+	app *v1beta1.ScheduledSparkApplication,
+	runName string,
+	t time.Time,
+	scheduledTime time.Time) util.RunTemplateData {
+	return util.RunTemplateData{
+		ScheduledTime:    scheduledTime,
+		ActualTime:       t.In(scheduledTime.Location()),
+		RunName:          runName,
+		ScheduledAppName: app.Name,
+	}
+}
+
+// getNextRunTime returns the first time after the given time the schedule is due at, evaluating the schedule in the
+// given location. Around daylight saving time transitions of the location, runs scheduled at the times skipped when
+// the clocks are set forward are skipped, and runs scheduled at the times repeated when the clocks are set back run
+// once, on the first occurrence of the times. Schedules running every hour, or on a fixed interval, are unaffected
+// and keep running on elapsed time.
+func getNextRunTime(schedule cron.Schedule, after time.Time, location *time.Location) time.Time {
+	next := schedule.Next(after.In(location))
+	spec, ok := schedule.(*cron.SpecSchedule)
+	if !ok || spec.Hour&allHours == allHours {
+		return next
+	}
+	// Times in the gap of a transition may be normalized to wall clock times past the gap not in the schedule.
+	for !next.IsZero() && (isRepeatedWallTime(next) || spec.Hour&(1<<uint(next.Hour())) == 0) {
+		next = schedule.Next(next)
+	}
+	return next
+}
+
+// isRepeatedWallTime tells if the wall clock time of t in its location already occurred earlier, i.e., t is in the
+// second pass through the times repeated when the clocks were set back.
+func isRepeatedWallTime(t time.Time) bool {
+	_, offset := t.Zone()
+	// Zone transitions are hours apart, so a zone offset larger half a day earlier means the clocks have been set
+	// back in between, by the difference of the offsets.
+	_, earlierOffset := t.Add(-12 * time.Hour).Zone()
+	if earlierOffset <= offset {
+		return false
+	}
+	earlier := t.Add(-time.Duration(earlierOffset-offset) * time.Second)
+	_, offsetThen := earlier.Zone()
+	return offsetThen == earlierOffset
+}
+
+// getDueRunTimes returns the times of the runs due by now, oldest first, starting from the given time of the first
+// run that is due. It returns false if more than maxDueRuns runs are due.
+func getDueRunTimes(
+	schedule cron.Schedule,
+	first time.Time,
+	now time.Time,
+	location *time.Location) ([]time.Time, bool) {
+	var times []time.Time
+	for t := first; !t.IsZero() && !t.After(now); t = getNextRunTime(schedule, t, location) {
+		if len(times) == maxDueRuns {
+			return nil, false
+		}
+		times = append(times, t)
+	}
+	return times, true
+}
+
+// selectDueRuns splits the due runs, given by their scheduled times in the order they are due, into the runs to start
+// and the runs that were missed according to the starting deadline and the missed run policy. The runs to start are
+// returned oldest first.
+func selectDueRuns(
+	dueTimes []time.Time,
+	now time.Time,
+	startingDeadlineSeconds *int64,
+	policy *v1beta1.MissedRunPolicy) ([]time.Time, []v1beta1.MissedRun) {
+	policyType := v1beta1.MissedRunSkip
+	if policy != nil {
+		policyType = policy.Type
+	}
+
+	var toStart []time.Time
+	var missed []v1beta1.MissedRun
+	for i, t := range dueTimes {
+		switch {
+		case startingDeadlineSeconds != nil && now.Sub(t) > time.Duration(*startingDeadlineSeconds)*time.Second:
+			missed = append(missed, newMissedRun(t, v1beta1.MissedRunDeadlineExceeded))
+		case i < len(dueTimes)-1 && policyType != v1beta1.MissedRunRunAll:
+			missed = append(missed, newMissedRun(t, v1beta1.MissedRunOvertaken))
+		default:
+			toStart = append(toStart, t)
+		}
+	}
+
+	switch policyType {
+	case v1beta1.MissedRunRunOnce:
+		// A single run makes up for the missed runs, unless a run that was not missed starts anyway.
+		if len(toStart) == 0 && len(missed) > 0 {
+			toStart = append(toStart, missed[len(missed)-1].ScheduledTime.Time)
+			missed = missed[:len(missed)-1]
+		}
+	case v1beta1.MissedRunRunAll:
+		maxRuns := defaultMaxMissedRuns
+		if policy.MaxRuns != nil {
+			maxRuns = int(*policy.MaxRuns)
+		}
+		if len(toStart) > maxRuns {
+			for _, t := range toStart[:len(toStart)-maxRuns] {
+				missed = append(missed, newMissedRun(t, v1beta1.MissedRunLimitExceeded))
+			}
+			toStart = toStart[len(toStart)-maxRuns:]
+		}
+		sort.Slice(missed, func(i, j int) bool {
+			return missed[i].ScheduledTime.Before(&missed[j].ScheduledTime)
+		})
+	}
+	return toStart, missed
+}
+
+func newMissedRun(scheduledTime time.Time, reason v1beta1.MissedRunReason) v1beta1.MissedRun {
+	return v1beta1.MissedRun{ScheduledTime: metav1.NewTime(scheduledTime), Reason: reason}
+}
+
+// recordMissedRuns adds the missed runs to the ones recorded in the status, keeping the most recent ones.
+func recordMissedRuns(status *v1beta1.ScheduledSparkApplicationStatus, missed []v1beta1.MissedRun) {
+	status.MissedRunCount += int32(len(missed))
+	status.MissedRuns = append(status.MissedRuns, missed...)
+	if len(status.MissedRuns) > maxRecordedMissedRuns {
+		status.MissedRuns = status.MissedRuns[len(status.MissedRuns)-maxRecordedMissedRuns:]
+	}
 }
@@ -38,6 +38,7 @@ import (
 	"k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
@@ -57,6 +58,7 @@ const (
 	queueTokenRefillRate      = 50
 	queueTokenBucketSize      = 500
 	maximumUpdateRetries      = 3
+	cleanupCheckInterval      = 5 * time.Second
 )
 
 var (
@@ -68,6 +70,7 @@ var (
 type Controller struct {
 	crdClient         crdclientset.Interface
 	kubeClient        clientset.Interface
+	namespace         string
 	queue             workqueue.RateLimitingInterface
 	cacheSynced       cache.InformerSynced
 	recorder          record.EventRecorder
@@ -76,6 +79,13 @@ type Controller struct {
 	podLister         v1.PodLister
 	ingressURLFormat  string
 	batchSchedulerMgr *batchscheduler.SchedulerManager
+	submitter         Submitter
+	// submissionPool runs submissions asynchronously on submissionWorkers workers if set.
+	submissionPool    *submissionPool
+	submissionWorkers int
+	// statusUpdateInterval is the period of time the changes to the pods of an application are collected for before
+	// they are processed together, so they result in a single update of its status.
+	statusUpdateInterval time.Duration
 }
 
 // NewController creates a new Controller.
@@ -87,7 +97,10 @@ func NewController(
 	metricsConfig *util.MetricConfig,
 	namespace string,
 	ingressURLFormat string,
-	batchSchedulerMgr *batchscheduler.SchedulerManager) *Controller {
+	batchSchedulerMgr *batchscheduler.SchedulerManager,
+	submitter Submitter,
+	submissionWorkers int,
+	statusUpdateInterval time.Duration) *Controller {
 	crdscheme.AddToScheme(scheme.Scheme)
 
 	eventBroadcaster := record.NewBroadcaster()
@@ -97,7 +110,7 @@ func NewController(
 	})
 	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, apiv1.EventSource{Component: "spark-operator"})
 
-	return newSparkApplicationController(crdClient, kubeClient, crdInformerFactory, podInformerFactory, recorder, metricsConfig, ingressURLFormat, batchSchedulerMgr)
+	return newSparkApplicationController(crdClient, kubeClient, crdInformerFactory, podInformerFactory, recorder, metricsConfig, namespace, ingressURLFormat, batchSchedulerMgr, submitter, submissionWorkers, statusUpdateInterval)
 }
 
 func newSparkApplicationController(
@@ -107,18 +120,32 @@ func newSparkApplicationController(
 	podInformerFactory informers.SharedInformerFactory,
 	eventRecorder record.EventRecorder,
 	metricsConfig *util.MetricConfig,
+	namespace string,
 	ingressURLFormat string,
-	batchSchedulerMgr *batchscheduler.SchedulerManager) *Controller {
+	batchSchedulerMgr *batchscheduler.SchedulerManager,
+	submitter Submitter,
+	submissionWorkers int,
+	statusUpdateInterval time.Duration) *Controller {
 	queue := workqueue.NewNamedRateLimitingQueue(&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(queueTokenRefillRate), queueTokenBucketSize)},
 		"spark-application-controller")
 
 	controller := &Controller{
-		crdClient:         crdClient,
-		kubeClient:        kubeClient,
-		recorder:          eventRecorder,
-		queue:             queue,
-		ingressURLFormat:  ingressURLFormat,
-		batchSchedulerMgr: batchSchedulerMgr,
+		crdClient:            crdClient,
+		kubeClient:           kubeClient,
+		namespace:            namespace,
+		recorder:             eventRecorder,
+		queue:                queue,
+		ingressURLFormat:     ingressURLFormat,
+		batchSchedulerMgr:    batchSchedulerMgr,
+		submitter:            submitter,
+		statusUpdateInterval: statusUpdateInterval,
+	}
+	if controller.submitter == nil {
+		controller.submitter = NewSparkSubmitter(0)
+	}
+	if submissionWorkers > 0 {
+		controller.submissionPool = newSubmissionPool(controller.submitter, controller.queue.Add)
+		controller.submissionWorkers = submissionWorkers
 	}
 
 	if metricsConfig != nil {
@@ -135,7 +162,7 @@ func newSparkApplicationController(
 	controller.applicationLister = crdInformer.Lister()
 
 	podsInformer := podInformerFactory.Core().V1().Pods()
-	sparkPodEventHandler := newSparkPodEventHandler(controller.queue.AddRateLimited, controller.applicationLister)
+	sparkPodEventHandler := newSparkPodEventHandler(controller.enqueueForPodEvent, controller.applicationLister)
 	podsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    sparkPodEventHandler.onPodAdded,
 		UpdateFunc: sparkPodEventHandler.onPodUpdated,
@@ -163,6 +190,13 @@ func (c *Controller) Start(workers int, stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.cacheSynced) {
 		return fmt.Errorf("timed out waiting for cache to sync")
 	}
+
+	if c.submissionPool != nil {
+		c.submissionPool.start(c.submissionWorkers, stopCh)
+	}
+
+	// Clean up resources left behind by applications deleted while the operator was not running.
+	go c.collectOrphanedResources()
 	return nil
 }
 
@@ -170,6 +204,9 @@ func (c *Controller) Start(workers int, stopCh <-chan struct{}) error {
 func (c *Controller) Stop() {
 	glog.Info("Stopping the SparkApplication controller")
 	c.queue.ShutDown()
+	if c.submissionPool != nil {
+		c.submissionPool.stop()
+	}
 }
 
 // Callback function called when a new SparkApplication object gets created.
@@ -191,8 +228,10 @@ func (c *Controller) onUpdate(oldObj, newObj interface{}) {
 	}
 
 	// The spec has changed. This is currently best effort as we can potentially miss updates
-	// and end up in an inconsistent state.
-	if !equality.Semantic.DeepEqual(oldApp.Spec, newApp.Spec) {
+	// and end up in an inconsistent state. Suspending or resuming the application is not a spec
+	// change, it is handled by the state machine.
+	switch classifySpecChange(&oldApp.Spec, &newApp.Spec) {
+	case resubmissionSpecChange:
 		// Force-set the application status to Invalidating which handles clean-up and application re-run.
 		if _, err := c.updateApplicationStatusWithRetries(newApp, func(status *v1beta1.SparkApplicationStatus) {
 			status.AppState.State = v1beta1.InvalidatingState
@@ -211,7 +250,30 @@ func (c *Controller) onUpdate(oldObj, newObj interface{}) {
 			newApp,
 			apiv1.EventTypeNormal,
 			"SparkApplicationSpecUpdateProcessed",
-			"Successfully processed spec update for SparkApplication %s",
+			"Successfully processed spec update for SparkApplication %s, resubmitting it",
+			newApp.Name)
+	case inPlaceSpecChange:
+		// The current run goes on. A pending retry is rescheduled according to the updated restart policy.
+		if _, err := c.updateApplicationStatusWithRetries(newApp, func(status *v1beta1.SparkApplicationStatus) {
+			if !equality.Semantic.DeepEqual(oldApp.Spec.RestartPolicy, newApp.Spec.RestartPolicy) {
+				status.NextRetryTime = metav1.Time{}
+			}
+		}); err != nil {
+			c.recorder.Eventf(
+				newApp,
+				apiv1.EventTypeWarning,
+				"SparkApplicationSpecUpdateFailed",
+				"failed to process spec update for SparkApplication %s: %v",
+				newApp.Name,
+				err)
+			return
+		}
+
+		c.recorder.Eventf(
+			newApp,
+			apiv1.EventTypeNormal,
+			"SparkApplicationSpecUpdateAppliedInPlace",
+			"Successfully applied spec update for SparkApplication %s without resubmitting it",
 			newApp.Name)
 	}
 
@@ -230,7 +292,9 @@ func (c *Controller) onDelete(obj interface{}) {
 	}
 
 	if app != nil {
-		c.handleSparkApplicationDeletion(app)
+		if err := c.handleSparkApplicationDeletion(app); err != nil {
+			glog.Error(err)
+		}
 		c.recorder.Eventf(
 			app,
 			apiv1.EventTypeNormal,
@@ -325,6 +389,7 @@ func (c *Controller) getAndUpdateDriverState(app *v1beta1.SparkApplication) erro
 	}
 
 	app.Status.SparkApplicationID = getSparkApplicationID(driverPod)
+	c.updateDriverPendingReason(app, driverPod)
 
 	if driverPod.Status.Phase == apiv1.PodSucceeded || driverPod.Status.Phase == apiv1.PodFailed {
 		if app.Status.TerminationTime.IsZero() {
@@ -335,10 +400,20 @@ func (c *Controller) getAndUpdateDriverState(app *v1beta1.SparkApplication) erro
 				terminatedState := driverPod.Status.ContainerStatuses[0].State.Terminated
 				if terminatedState != nil {
 					app.Status.AppState.ErrorMessage = fmt.Sprintf("driver pod failed with ExitCode: %d, Reason: %s", terminatedState.ExitCode, terminatedState.Reason)
+					exitCode := terminatedState.ExitCode
+					app.Status.DriverInfo.ExitCode = &exitCode
+					app.Status.DriverInfo.TerminationReason = terminatedState.Reason
 				}
 			} else {
 				app.Status.AppState.ErrorMessage = "driver container status missing"
 			}
+			// Reasons of pod failures, e.g., Evicted or DeadlineExceeded, take precedence over the container's.
+			if driverPod.Status.Reason != "" {
+				app.Status.DriverInfo.TerminationReason = driverPod.Status.Reason
+			}
+			if attempt := getCurrentAttemptMemory(app); attempt != nil && isOOMKilled(driverPod) {
+				attempt.DriverOOMKilled = true
+			}
 		}
 	}
 
@@ -352,6 +427,32 @@ func (c *Controller) getAndUpdateDriverState(app *v1beta1.SparkApplication) erro
 	return nil
 }
 
+// updateDriverPendingReason records why the driver pod is pending if it is. The kubelet alternates between reasons of
+// the same kind, e.g., ErrImagePull and ImagePullBackOff, so the time since which the driver is pending is only reset,
+// and a warning event emitted, when the driver starts pending or its reason becomes unrecoverable or recoverable.
+func (c *Controller) updateDriverPendingReason(app *v1beta1.SparkApplication, driverPod *apiv1.Pod) {
+	reason, message := getPodPendingReason(driverPod)
+	info := &app.Status.DriverInfo
+	if reason == "" {
+		info.PendingReason = ""
+		info.PendingMessage = ""
+		info.PendingSince = metav1.Time{}
+		return
+	}
+	policy := app.Spec.UnrecoverablePendingPolicy
+	if policy == nil {
+		policy = &v1beta1.UnrecoverablePendingPolicy{}
+	}
+	if info.PendingReason == "" ||
+		isUnrecoverablePendingReason(reason, policy) != isUnrecoverablePendingReason(info.PendingReason, policy) {
+		info.PendingSince = metav1.Now()
+		c.recorder.Eventf(app, apiv1.EventTypeWarning, "SparkDriverPending", "Driver %s is pending: %s: %s",
+			driverPod.Name, reason, message)
+	}
+	info.PendingReason = reason
+	info.PendingMessage = message
+}
+
 // getAndUpdateExecutorState lists the executor pods of the application
 // and updates the executor state based on the current phase of the pods.
 func (c *Controller) getAndUpdateExecutorState(app *v1beta1.SparkApplication) error {
@@ -360,7 +461,17 @@ func (c *Controller) getAndUpdateExecutorState(app *v1beta1.SparkApplication) er
 		return err
 	}
 
+	compact := app.Spec.CompactExecutorStatus != nil
+	var oldExecutorStateMap map[string]v1beta1.ExecutorState
+	if compact {
+		oldExecutorStateMap = make(map[string]v1beta1.ExecutorState, len(app.Status.ExecutorState))
+		for name, state := range app.Status.ExecutorState {
+			oldExecutorStateMap[name] = state
+		}
+	}
+
 	executorStateMap := make(map[string]v1beta1.ExecutorState)
+	executorInfoMap := make(map[string]v1beta1.ExecutorInfo)
 	var executorApplicationID string
 	for _, pod := range pods {
 		if util.IsExecutorPod(pod) {
@@ -369,8 +480,21 @@ func (c *Controller) getAndUpdateExecutorState(app *v1beta1.SparkApplication) er
 			// Only record an executor event if the executor state is new or it has changed.
 			if !exists || newState != oldState {
 				c.recordExecutorEvent(app, newState, pod.Name)
+				if newState == v1beta1.ExecutorFailedState && app.Spec.Executor.MaxFailures != nil {
+					app.Status.ExecutorFailures = append(app.Status.ExecutorFailures, newExecutorFailure(pod))
+				}
 			}
 			executorStateMap[pod.Name] = newState
+			// The details of terminated executors that were dropped from the status are not recorded again, and
+			// compact executor statuses only record the details of failed executors.
+			_, recorded := app.Status.Executors[pod.Name]
+			if (recorded || !exists || !isExecutorTerminated(oldState)) &&
+				(!compact || newState == v1beta1.ExecutorFailedState) {
+				executorInfoMap[pod.Name] = newExecutorInfo(pod, newState)
+			}
+			if attempt := getCurrentAttemptMemory(app); attempt != nil && isOOMKilled(pod) {
+				attempt.ExecutorOOMKilled = true
+			}
 
 			if executorApplicationID == "" {
 				executorApplicationID = getSparkApplicationID(pod)
@@ -390,15 +514,50 @@ func (c *Controller) getAndUpdateExecutorState(app *v1beta1.SparkApplication) er
 	for name, execStatus := range executorStateMap {
 		app.Status.ExecutorState[name] = execStatus
 	}
+	if app.Status.Executors == nil && len(executorInfoMap) > 0 {
+		app.Status.Executors = make(map[string]v1beta1.ExecutorInfo)
+	}
+	for name, info := range executorInfoMap {
+		if isExecutorTerminated(info.State) && info.TerminationTime.IsZero() {
+			// Keep the time the executor was first seen terminated at if its pod does not tell.
+			info.TerminationTime = app.Status.Executors[name].TerminationTime
+			if info.TerminationTime.IsZero() {
+				info.TerminationTime = metav1.Now()
+			}
+		}
+		app.Status.Executors[name] = info
+	}
 
 	// Handle missing/deleted executors.
 	for name, oldStatus := range app.Status.ExecutorState {
 		_, exists := executorStateMap[name]
 		if !isExecutorTerminated(oldStatus) && !exists {
+			// With dynamic allocation, Spark deletes the pods of executors it no longer needs.
+			if app.DynamicAllocationEnabled() {
+				glog.Infof("Executor pod %s not found, assuming it was removed by dynamic allocation.", name)
+				app.Status.ExecutorState[name] = v1beta1.ExecutorCompletedState
+				markExecutorInfoTerminated(app, name, v1beta1.ExecutorCompletedState, "")
+				continue
+			}
 			glog.Infof("Executor pod %s not found, assuming it was deleted.", name)
 			app.Status.ExecutorState[name] = v1beta1.ExecutorFailedState
+			markExecutorInfoTerminated(app, name, v1beta1.ExecutorFailedState, executorPodNotFoundReason)
+			if app.Spec.Executor.MaxFailures != nil {
+				app.Status.ExecutorFailures = append(app.Status.ExecutorFailures, v1beta1.ExecutorFailure{
+					PodName: name,
+					Time:    metav1.Now(),
+					Reason:  executorPodNotFoundReason,
+				})
+			}
 		}
 	}
+	pruneExecutorFailures(app, time.Now())
+	if compact {
+		compactExecutorStatus(app, oldExecutorStateMap, executorStateMap)
+	} else {
+		app.Status.ExecutorCounts = nil
+		pruneExecutorInfos(app, maxExecutorInfos)
+	}
 
 	return nil
 }
@@ -413,11 +572,96 @@ func (c *Controller) getAndUpdateAppState(app *v1beta1.SparkApplication) error {
 	return nil
 }
 
-func (c *Controller) handleSparkApplicationDeletion(app *v1beta1.SparkApplication) {
+// handleSparkApplicationDeletion deletes the resources of an application being deleted. Once all of them are gone,
+// the cleanup finalizer is removed so the deletion of the application can complete.
+func (c *Controller) handleSparkApplicationDeletion(app *v1beta1.SparkApplication) error {
 	// SparkApplication deletion requested, lets delete driver pod.
 	if err := c.deleteSparkResources(app); err != nil {
-		glog.Errorf("failed to delete resources associated with deleted SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return fmt.Errorf("failed to delete resources associated with deleted SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+	}
+	if needScheduling, scheduler := c.shouldDoBatchScheduling(app); needScheduling {
+		if err := scheduler.OnSparkApplicationDelete(app); err != nil {
+			return fmt.Errorf("failed to release batch scheduling resources of deleted SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		}
+	}
+	if c.submissionPool != nil {
+		// A queued submission is dropped, and the driver of a finished one is deleted. The application is enqueued
+		// again once a running submission finishes, so its driver is deleted then.
+		result, running := c.submissionPool.cancel(createMetaNamespaceKey(app.Namespace, app.Name))
+		if result != nil {
+			if err := c.deleteSubmittedDriverPod(app.Namespace, result); err != nil {
+				return err
+			}
+		}
+		if running {
+			glog.V(2).Infof("Waiting for the submission of deleted SparkApplication %s/%s to finish", app.Namespace,
+				app.Name)
+			return nil
+		}
+	}
+	if !hasCleanupFinalizer(app) {
+		return nil
+	}
+
+	if !c.validateSparkResourceDeletion(app) {
+		glog.V(2).Infof("Waiting for resources of SparkApplication %s/%s to be deleted", app.Namespace, app.Name)
+		c.enqueueAfter(app, cleanupCheckInterval)
+		return nil
+	}
+	if _, err := c.updateFinalizers(app, func(finalizers []string) []string {
+		return removeString(finalizers, config.CleanupFinalizer)
+	}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to remove finalizer from SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
 	}
+	glog.V(2).Infof("Resources of SparkApplication %s/%s have been cleaned up", app.Namespace, app.Name)
+	return nil
+}
+
+// addCleanupFinalizer adds the cleanup finalizer to the given application so its resources always get a chance
+// to be cleaned up when the application is deleted, even if the delete event is missed.
+func (c *Controller) addCleanupFinalizer(app *v1beta1.SparkApplication) (*v1beta1.SparkApplication, error) {
+	updatedApp, err := c.updateFinalizers(app, func(finalizers []string) []string {
+		return append(removeString(finalizers, config.CleanupFinalizer), config.CleanupFinalizer)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add finalizer to SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+	}
+	return updatedApp, nil
+}
+
+func (c *Controller) updateFinalizers(
+	app *v1beta1.SparkApplication,
+	updateFunc func(finalizers []string) []string) (*v1beta1.SparkApplication, error) {
+	toUpdate := app.DeepCopy()
+	var updatedApp *v1beta1.SparkApplication
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		toUpdate.Finalizers = updateFunc(toUpdate.Finalizers)
+		updated, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(toUpdate.Namespace).Update(toUpdate)
+		if err == nil {
+			updatedApp = updated
+			return nil
+		}
+		if errors.IsConflict(err) {
+			// Get the latest version from the API server first and re-apply the update.
+			latest, getErr := c.crdClient.SparkoperatorV1beta1().SparkApplications(toUpdate.Namespace).Get(
+				toUpdate.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return getErr
+			}
+			toUpdate = latest
+		}
+		return err
+	})
+	return updatedApp, err
+}
+
+func hasCleanupFinalizer(app *v1beta1.SparkApplication) bool {
+	for _, finalizer := range app.Finalizers {
+		if finalizer == config.CleanupFinalizer {
+			return true
+		}
+	}
+	return false
 }
 
 // ShouldRetry determines if SparkApplication in a given state should be retried.
@@ -426,6 +670,13 @@ func shouldRetry(app *v1beta1.SparkApplication) bool {
 	case v1beta1.SucceedingState:
 		return app.Spec.RestartPolicy.Type == v1beta1.Always
 	case v1beta1.FailingState:
+		if rule := getFailureRule(app); rule != nil {
+			if rule.Action == v1beta1.FailureActionNeverRetry {
+				return false
+			}
+			// We retry if we haven't hit the retry limit.
+			return app.Spec.RestartPolicy.OnFailureRetries != nil && app.Status.ExecutionAttempts <= *app.Spec.RestartPolicy.OnFailureRetries
+		}
 		if app.Spec.RestartPolicy.Type == v1beta1.Always {
 			return true
 		} else if app.Spec.RestartPolicy.Type == v1beta1.OnFailure {
@@ -483,6 +734,9 @@ func shouldRetry(app *v1beta1.SparkApplication) bool {
 //|                                             +-------------------------------+                                      |
 //|                                                                                                                    |
 //+--------------------------------------------------------------------------------------------------------------------+
+//
+// Suspending an application moves it from any non-terminal state to Suspending, then to Suspended once its resources
+// are deleted. Resuming it moves it from Suspended to Pending Rerun.
 
 func (c *Controller) syncSparkApplication(key string) error {
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
@@ -494,16 +748,41 @@ func (c *Controller) syncSparkApplication(key string) error {
 		return err
 	}
 	if app == nil {
-		// SparkApplication not found.
+		// SparkApplication not found. The driver of a submission that finished after it was deleted must not keep
+		// running.
+		if c.submissionPool != nil {
+			if result, _ := c.submissionPool.cancel(key); result != nil {
+				return c.deleteSubmittedDriverPod(namespace, result)
+			}
+		}
 		return nil
 	}
 	if !app.DeletionTimestamp.IsZero() {
-		c.handleSparkApplicationDeletion(app)
-		return nil
+		return c.handleSparkApplicationDeletion(app)
+	}
+	if !hasCleanupFinalizer(app) {
+		if app, err = c.addCleanupFinalizer(app); err != nil {
+			return err
+		}
+	}
+
+	if c.submissionPool != nil {
+		result, pending := c.submissionPool.takeResult(key)
+		if pending {
+			// The application is enqueued again once its submission finishes.
+			return nil
+		}
+		if result != nil {
+			return c.applySubmissionResult(app, result)
+		}
 	}
 
 	appToUpdate := app.DeepCopy()
 
+	if isSuspended(appToUpdate) && canBeSuspended(appToUpdate.Status.AppState.State) {
+		c.startSuspension(appToUpdate)
+	}
+
 	// Take action based on application state.
 	switch appToUpdate.Status.AppState.State {
 	case v1beta1.NewState:
@@ -519,6 +798,7 @@ func (c *Controller) syncSparkApplication(key string) error {
 			// Application is not subject to retry. Move to terminal CompletedState.
 			appToUpdate.Status.AppState.State = v1beta1.CompletedState
 			c.recordSparkApplicationEvent(appToUpdate)
+			c.cleanupBatchSchedulingOnCompletion(appToUpdate)
 		} else {
 			if err := c.deleteSparkResources(appToUpdate); err != nil {
 				glog.Errorf("failed to delete resources associated with SparkApplication %s/%s: %v",
@@ -532,21 +812,28 @@ func (c *Controller) syncSparkApplication(key string) error {
 			// Application is not subject to retry. Move to terminal FailedState.
 			appToUpdate.Status.AppState.State = v1beta1.FailedState
 			c.recordSparkApplicationEvent(appToUpdate)
-		} else if hasRetryIntervalPassed(appToUpdate.Spec.RestartPolicy.OnFailureRetryInterval, appToUpdate.Status.ExecutionAttempts, appToUpdate.Status.TerminationTime) {
+			c.cleanupBatchSchedulingOnCompletion(appToUpdate)
+		} else if hasRetryIntervalPassed(appToUpdate, appToUpdate.Spec.RestartPolicy.OnFailureRetryInterval, appToUpdate.Status.ExecutionAttempts, appToUpdate.Status.TerminationTime) {
 			if err := c.deleteSparkResources(appToUpdate); err != nil {
 				glog.Errorf("failed to delete resources associated with SparkApplication %s/%s: %v",
 					appToUpdate.Namespace, appToUpdate.Name, err)
 				return err
 			}
+			c.increaseMemory(appToUpdate)
 			appToUpdate.Status.AppState.State = v1beta1.PendingRerunState
+		} else {
+			c.enqueueForRetry(appToUpdate)
 		}
 	case v1beta1.FailedSubmissionState:
 		if !shouldRetry(appToUpdate) {
 			// App will never be retried. Move to terminal FailedState.
 			appToUpdate.Status.AppState.State = v1beta1.FailedState
 			c.recordSparkApplicationEvent(appToUpdate)
-		} else if hasRetryIntervalPassed(appToUpdate.Spec.RestartPolicy.OnSubmissionFailureRetryInterval, appToUpdate.Status.SubmissionAttempts, appToUpdate.Status.LastSubmissionAttemptTime) {
+			c.cleanupBatchSchedulingOnCompletion(appToUpdate)
+		} else if hasRetryIntervalPassed(appToUpdate, appToUpdate.Spec.RestartPolicy.OnSubmissionFailureRetryInterval, appToUpdate.Status.SubmissionAttempts, appToUpdate.Status.LastSubmissionAttemptTime) {
 			appToUpdate = c.submitSparkApplication(appToUpdate)
+		} else {
+			c.enqueueForRetry(appToUpdate)
 		}
 	case v1beta1.InvalidatingState:
 		// Invalidate the current run and enqueue the SparkApplication for re-execution.
@@ -569,6 +856,31 @@ func (c *Controller) syncSparkApplication(key string) error {
 		if err := c.getAndUpdateAppState(appToUpdate); err != nil {
 			return err
 		}
+		if err := c.checkExecutorFailureBudget(appToUpdate); err != nil {
+			return err
+		}
+		if err := c.checkUnrecoverablePending(appToUpdate); err != nil {
+			return err
+		}
+		if err := c.checkDeadlines(appToUpdate); err != nil {
+			return err
+		}
+	case v1beta1.SuspendingState:
+		if err := c.deleteSparkResources(appToUpdate); err != nil {
+			glog.Errorf("failed to delete resources associated with SparkApplication %s/%s: %v",
+				appToUpdate.Namespace, appToUpdate.Name, err)
+			return err
+		}
+		if c.validateSparkResourceDeletion(appToUpdate) {
+			appToUpdate.Status.AppState.State = v1beta1.SuspendedState
+			c.recordSparkApplicationEvent(appToUpdate)
+		}
+	case v1beta1.SuspendedState:
+		if !isSuspended(appToUpdate) {
+			// Resume the application by running it again.
+			appToUpdate.Status.AppState.State = v1beta1.PendingRerunState
+			c.recordSparkApplicationEvent(appToUpdate)
+		}
 	}
 
 	if appToUpdate != nil {
@@ -584,23 +896,185 @@ func (c *Controller) syncSparkApplication(key string) error {
 }
 
 // Helper func to determine if we have waited enough to retry the SparkApplication.
-func hasRetryIntervalPassed(retryInterval *int64, attemptsDone int32, lastEventTime metav1.Time) bool {
+// The time of the retry is recorded in the status the first time, so a jittered backoff does not change between checks.
+func hasRetryIntervalPassed(app *v1beta1.SparkApplication, retryInterval *int64, attemptsDone int32, lastEventTime metav1.Time) bool {
 	glog.V(3).Infof("retryInterval: %d , lastEventTime: %v, attempsDone: %d", retryInterval, lastEventTime, attemptsDone)
-	if retryInterval == nil || lastEventTime.IsZero() || attemptsDone <= 0 {
+	if app.Status.NextRetryTime.IsZero() {
+		app.Status.NextRetryTime = getNextRetryTime(&app.Spec.RestartPolicy, retryInterval, attemptsDone, lastEventTime)
+	}
+	if app.Status.NextRetryTime.IsZero() {
 		return false
 	}
 
-	// Retry if we have waited at-least equal to attempts*RetryInterval since we do a linear back-off.
-	interval := time.Duration(*retryInterval) * time.Second * time.Duration(attemptsDone)
 	currentTime := time.Now()
-	glog.V(3).Infof("currentTime is %v, interval is %v", currentTime, interval)
-	if currentTime.After(lastEventTime.Add(interval)) {
-		return true
+	glog.V(3).Infof("currentTime is %v, nextRetryTime is %v", currentTime, app.Status.NextRetryTime)
+	return currentTime.After(app.Status.NextRetryTime.Time)
+}
+
+// checkDeadlines fails the current attempt of an application that has been pending or running for longer than its
+// spec allows, killing the driver. Otherwise, the application is enqueued again for the nearest deadline.
+func (c *Controller) checkDeadlines(app *v1beta1.SparkApplication) error {
+	state := app.Status.AppState.State
+	submissionTime := app.Status.LastSubmissionAttemptTime
+	if submissionTime.IsZero() ||
+		(state != v1beta1.SubmittedState && state != v1beta1.RunningState && state != v1beta1.UnknownState) {
+		return nil
 	}
-	return false
+
+	now := time.Now()
+	var reason, message string
+	var nextDeadline time.Time
+	if ttl := app.Spec.TimeToLiveBeforeRunning; ttl != nil && state == v1beta1.SubmittedState {
+		deadline := submissionTime.Add(time.Duration(*ttl) * time.Second)
+		if !now.Before(deadline) {
+			reason = v1beta1.PendingDeadlineExceededReason
+			message = fmt.Sprintf("the driver did not start running within %d seconds", *ttl)
+		} else {
+			nextDeadline = deadline
+		}
+	}
+	if activeDeadline := app.Spec.ActiveDeadlineSeconds; activeDeadline != nil && reason == "" {
+		deadline := submissionTime.Add(time.Duration(*activeDeadline) * time.Second)
+		if !now.Before(deadline) {
+			reason = v1beta1.ActiveDeadlineExceededReason
+			message = fmt.Sprintf("the application did not complete within %d seconds", *activeDeadline)
+		} else if nextDeadline.IsZero() || deadline.Before(nextDeadline) {
+			nextDeadline = deadline
+		}
+	}
+
+	if reason == "" {
+		if !nextDeadline.IsZero() {
+			c.enqueueAfter(app, nextDeadline.Sub(now))
+		}
+		return nil
+	}
+
+	glog.Infof("SparkApplication %s/%s exceeded its deadline: %s", app.Namespace, app.Name, message)
+	return c.failCurrentAttempt(app, reason, message, "SparkApplicationDeadlineExceeded")
+}
+
+// checkExecutorFailureBudget fails the current attempt of an application more executors of which failed than its
+// executor spec tolerates, killing the driver.
+func (c *Controller) checkExecutorFailureBudget(app *v1beta1.SparkApplication) error {
+	state := app.Status.AppState.State
+	maxFailures := app.Spec.Executor.MaxFailures
+	if maxFailures == nil ||
+		(state != v1beta1.SubmittedState && state != v1beta1.RunningState && state != v1beta1.UnknownState) {
+		return nil
+	}
+	failures := app.Status.ExecutorFailures
+	if len(failures) <= int(*maxFailures) {
+		return nil
+	}
+
+	window := ""
+	if app.Spec.Executor.FailureWindowSeconds != nil {
+		window = fmt.Sprintf(" within %d seconds", *app.Spec.Executor.FailureWindowSeconds)
+	}
+	message := fmt.Sprintf("executor failure budget exhausted: %d executors failed%s, more than the %d allowed: %s",
+		len(failures), window, *maxFailures, describeExecutorFailures(failures))
+	glog.Infof("SparkApplication %s/%s exhausted its executor failure budget: %s", app.Namespace, app.Name, message)
+	return c.failCurrentAttempt(app, v1beta1.ExecutorFailureBudgetExhaustedReason, message,
+		"SparkApplicationExecutorFailureBudgetExhausted")
+}
+
+// checkUnrecoverablePending fails the current attempt of an application the driver of which has been pending for an
+// unrecoverable reason for longer than its spec allows, killing the driver. Otherwise, the application is enqueued
+// again for the time it would fail at.
+func (c *Controller) checkUnrecoverablePending(app *v1beta1.SparkApplication) error {
+	policy := app.Spec.UnrecoverablePendingPolicy
+	info := app.Status.DriverInfo
+	if policy == nil || app.Status.AppState.State != v1beta1.SubmittedState ||
+		!isUnrecoverablePendingReason(info.PendingReason, policy) {
+		return nil
+	}
+
+	now := time.Now()
+	deadline := info.PendingSince.Add(time.Duration(policy.FailAfterMinutes) * time.Minute)
+	if now.Before(deadline) {
+		c.enqueueAfter(app, deadline.Sub(now))
+		return nil
+	}
+
+	message := fmt.Sprintf("the driver has been pending for more than %d minutes: %s: %s", policy.FailAfterMinutes,
+		info.PendingReason, info.PendingMessage)
+	glog.Infof("SparkApplication %s/%s cannot start: %s", app.Namespace, app.Name, message)
+	return c.failCurrentAttempt(app, info.PendingReason, message, "SparkApplicationPendingUnrecoverable")
+}
+
+// failCurrentAttempt kills the driver of the current attempt of an application and moves the application to
+// FailingState, from which it is retried or failed according to its restart policy.
+func (c *Controller) failCurrentAttempt(app *v1beta1.SparkApplication, reason, message, eventReason string) error {
+	if err := c.deleteSparkResources(app); err != nil {
+		glog.Errorf("failed to delete resources associated with SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return err
+	}
+	app.Status.AppState.State = v1beta1.FailingState
+	app.Status.AppState.ErrorMessage = message
+	app.Status.DriverInfo.TerminationReason = reason
+	app.Status.DriverInfo.ExitCode = nil
+	app.Status.TerminationTime = metav1.Now()
+	c.recorder.Eventf(app, apiv1.EventTypeWarning, eventReason, "SparkApplication %s failed: %s", app.Name, message)
+	return nil
 }
 
-// submitSparkApplication creates a new submission for the given SparkApplication and submits it using spark-submit.
+// startSuspension moves an application that is to be suspended to SuspendingState. An attempt killed by the suspension
+// does not count as an execution attempt, so the suspension does not consume a retry.
+func (c *Controller) startSuspension(app *v1beta1.SparkApplication) {
+	glog.Infof("Suspending SparkApplication %s/%s", app.Namespace, app.Name)
+	switch app.Status.AppState.State {
+	case v1beta1.SubmittedState, v1beta1.RunningState, v1beta1.UnknownState:
+		if getCurrentAttemptMemory(app) != nil {
+			app.Status.MemoryHistory = app.Status.MemoryHistory[:len(app.Status.MemoryHistory)-1]
+		}
+		if app.Status.ExecutionAttempts > 0 {
+			app.Status.ExecutionAttempts--
+		}
+	}
+	app.Status.AppState.State = v1beta1.SuspendingState
+	app.Status.AppState.ErrorMessage = ""
+	app.Status.NextRetryTime = metav1.Time{}
+	c.recordSparkApplicationEvent(app)
+}
+
+// enqueueForRetry enqueues the SparkApplication again for the time it is going to be retried at.
+func (c *Controller) enqueueForRetry(app *v1beta1.SparkApplication) {
+	if !app.Status.NextRetryTime.IsZero() {
+		c.enqueueAfter(app, time.Until(app.Status.NextRetryTime.Time))
+	}
+}
+
+// increaseMemory increases the driver and executor memory of the next attempt of a failed application as configured
+// by its restart policy. The increased memory is recorded in the status, the spec is not changed.
+func (c *Controller) increaseMemory(app *v1beta1.SparkApplication) {
+	driverFactor, executorFactor := getMemoryIncreaseFactors(app)
+	var maxMemory *string
+	if app.Spec.RestartPolicy.MemoryIncreaseOnOOM != nil {
+		maxMemory = app.Spec.RestartPolicy.MemoryIncreaseOnOOM.MaxMemory
+	}
+
+	if driverFactor > 0 {
+		if memory, err := getIncreasedMemory(getDriverMemory(app), driverFactor, maxMemory); err != nil {
+			glog.Errorf("failed to increase the driver memory of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		} else {
+			glog.Infof("Increasing the driver memory of SparkApplication %s/%s to %s for the next attempt", app.Namespace, app.Name, memory)
+			app.Status.DriverMemory = memory
+		}
+	}
+	if executorFactor > 0 {
+		if memory, err := getIncreasedMemory(getExecutorMemory(app), executorFactor, maxMemory); err != nil {
+			glog.Errorf("failed to increase the executor memory of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		} else {
+			glog.Infof("Increasing the executor memory of SparkApplication %s/%s to %s for the next attempt", app.Namespace, app.Name, memory)
+			app.Status.ExecutorMemory = memory
+		}
+	}
+}
+
+// submitSparkApplication creates a new submission for the given SparkApplication and submits it using the
+// configured Submitter. With a submission pool, the submission is only dispatched to the pool, and the status of the
+// application is updated once the result of the submission is picked up by applySubmissionResult.
 func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1beta1.SparkApplication {
 	if app.PrometheusMonitoringEnabled() {
 		if err := configPrometheusMonitoring(app, c.kubeClient); err != nil {
@@ -610,17 +1084,11 @@ func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1be
 
 	driverPodName := getDriverPodName(app)
 	submissionID := uuid.New().String()
-	submissionCmdArgs, err := buildSubmissionCommandArgs(app, driverPodName, submissionID)
-	if err != nil {
-		app.Status = v1beta1.SparkApplicationStatus{
-			AppState: v1beta1.ApplicationState{
-				State:        v1beta1.FailedSubmissionState,
-				ErrorMessage: err.Error(),
-			},
-			SubmissionAttempts:        app.Status.SubmissionAttempts + 1,
-			LastSubmissionAttemptTime: metav1.Now(),
-		}
-		return app
+
+	if app.Status.DriverMemory != "" || app.Status.ExecutorMemory != "" {
+		//Spark submit will use the memory increased after previous failures(Spec will not be updated into API server)
+		app = app.DeepCopy()
+		app.Spec = *app.EffectiveSpec()
 	}
 
 	// Use batch scheduler to perform scheduling task before submitting.
@@ -634,9 +1102,64 @@ func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1be
 		app = newApp
 	}
 
-	// Try submitting the application by running spark-submit.
-	submitted, err := runSparkSubmit(newSubmission(submissionCmdArgs, app))
+	if c.submissionPool != nil {
+		c.submissionPool.dispatch(createMetaNamespaceKey(app.Namespace, app.Name), &submissionRequest{
+			app:           app.DeepCopy(),
+			driverPodName: driverPodName,
+			submissionID:  submissionID,
+		})
+		return app
+	}
+
+	// Try submitting the application.
+	err := c.submitter.Submit(app, driverPodName, submissionID)
+	return c.updateSubmissionStatus(app, driverPodName, submissionID, err)
+}
+
+// applySubmissionResult updates the status of the given application with the result of its submission by the
+// submission pool. The results of submissions overtaken by changes to the application are discarded.
+func (c *Controller) applySubmissionResult(app *v1beta1.SparkApplication, result *submissionResult) error {
+	if !result.isFor(app) {
+		glog.Warningf("discarding the result of an outdated submission of SparkApplication %s/%s", app.Namespace,
+			app.Name)
+		// The driver of the outdated submission is not tracked by the application, so it must not keep running.
+		if err := c.deleteSubmittedDriverPod(app.Namespace, result); err != nil {
+			return err
+		}
+		c.enqueue(app)
+		return nil
+	}
+
+	appToUpdate := c.updateSubmissionStatus(app.DeepCopy(), result.driverPodName, result.submissionID, result.err)
+	return c.updateStatusAndExportMetrics(app, appToUpdate)
+}
+
+// deleteSubmittedDriverPod deletes the driver pod created by the submission with the given result, if it succeeded.
+func (c *Controller) deleteSubmittedDriverPod(namespace string, result *submissionResult) error {
+	if result.err != nil {
+		return nil
+	}
+	glog.V(2).Infof("Deleting pod %s in namespace %s", result.driverPodName, namespace)
+	err := c.kubeClient.CoreV1().Pods(namespace).Delete(result.driverPodName, metav1.NewDeleteOptions(0))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// updateSubmissionStatus updates the status of the given application with the result of its submission.
+func (c *Controller) updateSubmissionStatus(
+	app *v1beta1.SparkApplication,
+	driverPodName string,
+	submissionID string,
+	err error) *v1beta1.SparkApplication {
 	if err != nil {
+		if IsAlreadySubmitted(err) {
+			// The application may have already been submitted, e.g., when some state update caused
+			// an attempt to re-submit the application. If this is the case, we simply return.
+			glog.Warningf("trying to resubmit an already submitted SparkApplication %s/%s", app.Namespace, app.Name)
+			return app
+		}
 		app.Status = v1beta1.SparkApplicationStatus{
 			AppState: v1beta1.ApplicationState{
 				State:        v1beta1.FailedSubmissionState,
@@ -644,15 +1167,12 @@ func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1be
 			},
 			SubmissionAttempts:        app.Status.SubmissionAttempts + 1,
 			LastSubmissionAttemptTime: metav1.Now(),
+			DriverMemory:              app.Status.DriverMemory,
+			ExecutorMemory:            app.Status.ExecutorMemory,
+			MemoryHistory:             app.Status.MemoryHistory,
 		}
 		c.recordSparkApplicationEvent(app)
-		glog.Errorf("failed to run spark-submit for SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
-		return app
-	}
-	if !submitted {
-		// The application may not have been submitted even if err == nil, e.g., when some
-		// state update caused an attempt to re-submit the application, in which case no
-		// error gets returned from runSparkSubmit. If this is the case, we simply return.
+		glog.Errorf("failed to submit SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
 		return app
 	}
 
@@ -668,7 +1188,11 @@ func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1be
 		SubmissionAttempts:        app.Status.SubmissionAttempts + 1,
 		ExecutionAttempts:         app.Status.ExecutionAttempts + 1,
 		LastSubmissionAttemptTime: metav1.Now(),
+		DriverMemory:              app.Status.DriverMemory,
+		ExecutorMemory:            app.Status.ExecutorMemory,
+		MemoryHistory:             app.Status.MemoryHistory,
 	}
+	recordAttemptMemory(app)
 	c.recordSparkApplicationEvent(app)
 
 	service, err := createSparkUIService(app, c.kubeClient)
@@ -704,6 +1228,16 @@ func (c *Controller) shouldDoBatchScheduling(app *v1beta1.SparkApplication) (boo
 	}
 }
 
+// cleanupBatchSchedulingOnCompletion releases the objects the batch scheduler holds for a terminated application,
+// e.g., its PodGroup. Failures are only logged as the objects are garbage collected with the application anyway.
+func (c *Controller) cleanupBatchSchedulingOnCompletion(app *v1beta1.SparkApplication) {
+	if needScheduling, scheduler := c.shouldDoBatchScheduling(app); needScheduling {
+		if err := scheduler.CleanupOnCompletion(app); err != nil {
+			glog.Errorf("failed to clean up batch scheduling of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		}
+	}
+}
+
 func (c *Controller) updateApplicationStatusWithRetries(
 	original *v1beta1.SparkApplication,
 	updateFunc func(status *v1beta1.SparkApplicationStatus)) (*v1beta1.SparkApplication, error) {
@@ -715,7 +1249,7 @@ func (c *Controller) updateApplicationStatusWithRetries(
 		if equality.Semantic.DeepEqual(original.Status, toUpdate.Status) {
 			return toUpdate, nil
 		}
-		_, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(toUpdate.Namespace).Update(toUpdate)
+		_, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(toUpdate.Namespace).UpdateStatus(toUpdate)
 		if err == nil {
 			return toUpdate, nil
 		}
@@ -743,6 +1277,9 @@ func (c *Controller) updateApplicationStatusWithRetries(
 
 // updateStatusAndExportMetrics updates the status of the SparkApplication and export the metrics.
 func (c *Controller) updateStatusAndExportMetrics(oldApp, newApp *v1beta1.SparkApplication) error {
+	newApp.Status.ObservedGeneration = newApp.Generation
+	updateConditions(newApp, oldApp.Status.Conditions)
+
 	// Skip update if nothing changed.
 	if equality.Semantic.DeepEqual(oldApp, newApp) {
 		return nil
@@ -800,6 +1337,15 @@ func (c *Controller) deleteSparkResources(app *v1beta1.SparkApplication) error {
 		}
 	}
 
+	if hasPrometheusConfigMap(app) {
+		configMapName := config.GetPrometheusConfigMapName(app)
+		glog.V(2).Infof("Deleting Prometheus ConfigMap %s in namespace %s", configMapName, app.Namespace)
+		err := c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Delete(configMapName, metav1.NewDeleteOptions(0))
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -840,6 +1386,13 @@ func (c *Controller) validateSparkResourceDeletion(app *v1beta1.SparkApplication
 		}
 	}
 
+	if hasPrometheusConfigMap(app) {
+		_, err := c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Get(config.GetPrometheusConfigMapName(app), metav1.GetOptions{})
+		if err == nil || !errors.IsNotFound(err) {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -853,6 +1406,28 @@ func (c *Controller) enqueue(obj interface{}) {
 	c.queue.AddRateLimited(key)
 }
 
+// enqueueForPodEvent enqueues the key of an application for processing a change to one of its pods. With a status
+// update interval, the key is only processed once the interval passes, so a burst of changes to the pods of the
+// application results in a single update of its status.
+func (c *Controller) enqueueForPodEvent(key interface{}) {
+	if c.statusUpdateInterval > 0 {
+		// The queue keeps a single delayed item per key, ready at the earliest time it was added for.
+		c.queue.AddAfter(key, c.statusUpdateInterval)
+		return
+	}
+	c.queue.AddRateLimited(key)
+}
+
+func (c *Controller) enqueueAfter(obj interface{}, duration time.Duration) {
+	key, err := keyFunc(obj)
+	if err != nil {
+		glog.Errorf("failed to get key for %v: %v", obj, err)
+		return
+	}
+
+	c.queue.AddAfter(key, duration)
+}
+
 func (c *Controller) recordSparkApplicationEvent(app *v1beta1.SparkApplication) {
 	switch app.Status.AppState.State {
 	case v1beta1.NewState:
@@ -899,6 +1474,20 @@ func (c *Controller) recordSparkApplicationEvent(app *v1beta1.SparkApplication)
 			"SparkApplicationPendingRerun",
 			"SparkApplication %s is pending rerun",
 			app.Name)
+	case v1beta1.SuspendingState:
+		c.recorder.Eventf(
+			app,
+			apiv1.EventTypeNormal,
+			"SparkApplicationSuspending",
+			"SparkApplication %s is being suspended",
+			app.Name)
+	case v1beta1.SuspendedState:
+		c.recorder.Eventf(
+			app,
+			apiv1.EventTypeNormal,
+			"SparkApplicationSuspended",
+			"SparkApplication %s was suspended",
+			app.Name)
 	}
 }
 
@@ -941,6 +1530,14 @@ func (c *Controller) clearStatus(status *v1beta1.SparkApplicationStatus) {
 		status.TerminationTime = metav1.Time{}
 		status.AppState.ErrorMessage = ""
 		status.ExecutorState = nil
+		status.Executors = nil
+		status.OmittedExecutors = 0
+		status.ExecutorCounts = nil
+		status.ExecutorFailures = nil
+		status.NextRetryTime = metav1.Time{}
+		status.DriverMemory = ""
+		status.ExecutorMemory = ""
+		status.MemoryHistory = nil
 	} else if status.AppState.State == v1beta1.PendingRerunState {
 		status.SparkApplicationID = ""
 		status.SubmissionAttempts = 0
@@ -948,5 +1545,10 @@ func (c *Controller) clearStatus(status *v1beta1.SparkApplicationStatus) {
 		status.DriverInfo = v1beta1.DriverInfo{}
 		status.AppState.ErrorMessage = ""
 		status.ExecutorState = nil
+		status.Executors = nil
+		status.OmittedExecutors = 0
+		status.ExecutorCounts = nil
+		status.ExecutorFailures = nil
+		status.NextRetryTime = metav1.Time{}
 	}
 }
@@ -28,7 +28,9 @@ import (
 	prometheus_model "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	kubeclientfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -65,7 +67,7 @@ func newFakeController(app *v1beta1.SparkApplication, pods ...*apiv1.Pod) (*Cont
 
 	podInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0*time.Second)
 	controller := newSparkApplicationController(crdClient, kubeClient, informerFactory, podInformerFactory, recorder,
-		&util.MetricConfig{}, "", nil)
+		&util.MetricConfig{}, "", "", nil, nil, 0, 0)
 
 	informer := informerFactory.Sparkoperator().V1beta1().SparkApplications().Informer()
 	if app != nil {
@@ -170,6 +172,91 @@ func TestOnUpdate(t *testing.T) {
 	app, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(appTemplate.Namespace).Get(appTemplate.Name, metav1.GetOptions{})
 	assert.Nil(t, err)
 	assert.Equal(t, v1beta1.InvalidatingState, app.Status.AppState.State)
+
+	// Case4: Spec update applied in place.
+	runningApp := appTemplate.DeepCopy()
+	runningApp.Name = "bar"
+	runningApp.Status.AppState.State = v1beta1.RunningState
+	ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(runningApp.Namespace).Create(runningApp)
+	copyWithInPlaceUpdate := runningApp.DeepCopy()
+	copyWithInPlaceUpdate.Spec.RestartPolicy = v1beta1.RestartPolicy{Type: v1beta1.OnFailure, OnFailureRetries: int32ptr(3)}
+	copyWithInPlaceUpdate.Spec.ActiveDeadlineSeconds = int64ptr(3600)
+	copyWithInPlaceUpdate.ResourceVersion = "2"
+
+	ctrl.onUpdate(runningApp, copyWithInPlaceUpdate)
+
+	item, _ = ctrl.queue.Get()
+	ctrl.queue.Forget(item)
+	ctrl.queue.Done(item)
+	assert.Equal(t, 1, len(recorder.Events))
+	event = <-recorder.Events
+	assert.True(t, strings.Contains(event, "SparkApplicationSpecUpdateAppliedInPlace"))
+
+	// Verify the current run was not invalidated.
+	app, err = ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(runningApp.Namespace).Get(runningApp.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.RunningState, app.Status.AppState.State)
+}
+
+func TestClassifySpecChange(t *testing.T) {
+	suspend := true
+	oldSpec := v1beta1.SparkApplicationSpec{
+		Image:         stringptr("foo-image:v1"),
+		RestartPolicy: v1beta1.RestartPolicy{Type: v1beta1.Never},
+	}
+
+	type testcase struct {
+		name     string
+		update   func(spec *v1beta1.SparkApplicationSpec)
+		expected specChange
+	}
+	testcases := []testcase{
+		{
+			name:     "no change",
+			update:   func(spec *v1beta1.SparkApplicationSpec) {},
+			expected: noSpecChange,
+		},
+		{
+			name:     "suspension",
+			update:   func(spec *v1beta1.SparkApplicationSpec) { spec.Suspend = &suspend },
+			expected: noSpecChange,
+		},
+		{
+			name: "restart policy and deadlines",
+			update: func(spec *v1beta1.SparkApplicationSpec) {
+				spec.RestartPolicy.Type = v1beta1.Always
+				spec.FailureRetries = int32ptr(3)
+				spec.TimeToLiveBeforeRunning = int64ptr(60)
+				spec.ActiveDeadlineSeconds = int64ptr(3600)
+				spec.TimeToLiveSecondsAfterFinished = int64ptr(86400)
+			},
+			expected: inPlaceSpecChange,
+		},
+		{
+			name:     "image",
+			update:   func(spec *v1beta1.SparkApplicationSpec) { spec.Image = stringptr("foo-image:v2") },
+			expected: resubmissionSpecChange,
+		},
+		{
+			name: "executor resources and restart policy",
+			update: func(spec *v1beta1.SparkApplicationSpec) {
+				spec.Executor.Instances = int32ptr(10)
+				spec.RestartPolicy.Type = v1beta1.Always
+			},
+			expected: resubmissionSpecChange,
+		},
+		{
+			name:     "spark configuration",
+			update:   func(spec *v1beta1.SparkApplicationSpec) { spec.SparkConf = map[string]string{"spark.foo": "bar"} },
+			expected: resubmissionSpecChange,
+		},
+	}
+
+	for _, test := range testcases {
+		newSpec := oldSpec.DeepCopy()
+		test.update(newSpec)
+		assert.Equal(t, test.expected, classifySpecChange(&oldSpec, newSpec), test.name)
+	}
 }
 
 func TestOnDelete(t *testing.T) {
@@ -229,6 +316,193 @@ type executorMetrics struct {
 	failedMetricCount  float64
 }
 
+// blockingSubmitter is a Submitter that blocks each submission until it is released.
+type blockingSubmitter struct {
+	release chan error
+}
+
+func (s *blockingSubmitter) Submit(app *v1beta1.SparkApplication, driverPodName string, submissionID string) error {
+	return <-s.release
+}
+
+func TestSyncSparkApplication_AsyncSubmission(t *testing.T) {
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       "uid",
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State: v1beta1.NewState,
+			},
+		},
+	}
+
+	ctrl, recorder := newFakeController(app)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+	submitter := &blockingSubmitter{release: make(chan error)}
+	ctrl.submissionPool = newSubmissionPool(submitter, ctrl.queue.Add)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	defer ctrl.submissionPool.stop()
+	ctrl.submissionPool.start(1, stopCh)
+
+	// The submission is dispatched to the pool, so the sync does not wait for it.
+	err := ctrl.syncSparkApplication("default/foo")
+	assert.Nil(t, err)
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.NewState, updatedApp.Status.AppState.State)
+
+	// Syncs while the submission is running neither change the application nor dispatch it again.
+	err = ctrl.syncSparkApplication("default/foo")
+	assert.Nil(t, err)
+	_, pending := ctrl.submissionPool.takeResult("default/foo")
+	assert.True(t, pending)
+
+	// The result is reported back through the work queue.
+	submitter.release <- nil
+	err = wait.Poll(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		ctrl.submissionPool.mutex.Lock()
+		defer ctrl.submissionPool.mutex.Unlock()
+		_, ok := ctrl.submissionPool.results["default/foo"]
+		return ok, nil
+	})
+	assert.Nil(t, err)
+	assert.True(t, ctrl.queue.Len() > 0)
+
+	err = ctrl.syncSparkApplication("default/foo")
+	assert.Nil(t, err)
+	updatedApp, err = ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.SubmittedState, updatedApp.Status.AppState.State)
+	assert.Equal(t, int32(1), updatedApp.Status.SubmissionAttempts)
+	assert.Equal(t, "foo-driver", updatedApp.Status.DriverInfo.PodName)
+	assert.NotEmpty(t, updatedApp.Status.SubmissionID)
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+}
+
+func TestApplySubmissionResult_Outdated(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       "uid",
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State: v1beta1.InvalidatingState,
+			},
+		},
+	}
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-driver",
+			Namespace: "default",
+		},
+	}
+
+	ctrl, _ := newFakeController(app, driverPod)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+	ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(driverPod)
+
+	// The application was invalidated while it was being submitted from the New state.
+	result := &submissionResult{uid: "uid", state: v1beta1.NewState, driverPodName: "foo-driver", submissionID: "id"}
+	err := ctrl.applySubmissionResult(app, result)
+	assert.Nil(t, err)
+
+	// The status is left alone and the driver of the outdated submission is deleted.
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.InvalidatingState, updatedApp.Status.AppState.State)
+	_, err = ctrl.kubeClient.CoreV1().Pods(app.Namespace).Get(driverPod.Name, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestSubmissionPool_DeletedApplication(t *testing.T) {
+	newApp := func(name string) *v1beta1.SparkApplication {
+		return &v1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState: v1beta1.ApplicationState{
+					State: v1beta1.NewState,
+				},
+			},
+		}
+	}
+	app := newApp("foo")
+	queuedApp := newApp("bar")
+
+	// Both applications have been deleted, so they are not found by the controller.
+	ctrl, _ := newFakeController(nil)
+	submitter := &blockingSubmitter{release: make(chan error)}
+	ctrl.submissionPool = newSubmissionPool(submitter, ctrl.queue.Add)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	defer ctrl.submissionPool.stop()
+	ctrl.submissionPool.start(1, stopCh)
+
+	ctrl.submissionPool.dispatch("default/foo", &submissionRequest{app: app, driverPodName: "foo-driver"})
+	err := wait.Poll(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		ctrl.submissionPool.mutex.Lock()
+		defer ctrl.submissionPool.mutex.Unlock()
+		return ctrl.submissionPool.requests["default/foo"].running, nil
+	})
+	assert.Nil(t, err)
+	ctrl.submissionPool.dispatch("default/bar", &submissionRequest{app: queuedApp, driverPodName: "bar-driver"})
+
+	// The queued submission is dropped, while the running one cannot be.
+	ctrl.onDelete(queuedApp)
+	ctrl.onDelete(app)
+	ctrl.submissionPool.mutex.Lock()
+	_, queued := ctrl.submissionPool.requests["default/bar"]
+	_, running := ctrl.submissionPool.requests["default/foo"]
+	ctrl.submissionPool.mutex.Unlock()
+	assert.False(t, queued)
+	assert.True(t, running)
+
+	// The submission creates the driver after the application was deleted.
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-driver",
+			Namespace: "default",
+		},
+	}
+	if _, err := ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(driverPod); err != nil {
+		t.Fatal(err)
+	}
+	submitter.release <- nil
+	err = wait.Poll(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		ctrl.submissionPool.mutex.Lock()
+		defer ctrl.submissionPool.mutex.Unlock()
+		_, ok := ctrl.submissionPool.results["default/foo"]
+		return ok, nil
+	})
+	assert.Nil(t, err)
+
+	// The late result is dropped and the driver is deleted.
+	err = ctrl.syncSparkApplication("default/foo")
+	assert.Nil(t, err)
+	_, pending := ctrl.submissionPool.takeResult("default/foo")
+	assert.False(t, pending)
+	assert.Equal(t, 0, len(ctrl.submissionPool.results))
+	_, err = ctrl.kubeClient.CoreV1().Pods(app.Namespace).Get(driverPod.Name, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
 func TestSyncSparkApplication_SubmissionFailed(t *testing.T) {
 	os.Setenv(sparkHomeEnvVar, "/spark")
 	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
@@ -442,6 +716,21 @@ func TestShouldRetry(t *testing.T) {
 		OnSubmissionFailureRetries:       int32ptr(2),
 	}
 
+	restartPolicyAlwaysExceptOOMKilled := v1beta1.RestartPolicy{
+		Type: v1beta1.Always,
+		FailureRules: []v1beta1.FailureRule{
+			{Reasons: []string{"OOMKilled"}, Action: v1beta1.FailureActionNeverRetry},
+		},
+	}
+
+	restartPolicyNeverExceptExitCode := v1beta1.RestartPolicy{
+		Type:             v1beta1.Never,
+		OnFailureRetries: int32ptr(1),
+		FailureRules: []v1beta1.FailureRule{
+			{ExitCodes: []v1beta1.ExitCodeRange{{Min: 100, Max: int32ptr(199)}}, Action: v1beta1.FailureActionRetry},
+		},
+	}
+
 	testcases := []testcase{
 		{
 			app: &v1beta1.SparkApplication{
@@ -570,6 +859,88 @@ func TestShouldRetry(t *testing.T) {
 			},
 			shouldRetry: false,
 		},
+		{
+			app: &v1beta1.SparkApplication{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "default",
+				},
+				Spec: v1beta1.SparkApplicationSpec{
+					RestartPolicy: restartPolicyAlwaysExceptOOMKilled,
+				},
+				Status: v1beta1.SparkApplicationStatus{
+					DriverInfo: v1beta1.DriverInfo{
+						TerminationReason: "OOMKilled",
+					},
+					AppState: v1beta1.ApplicationState{
+						State: v1beta1.FailingState,
+					},
+				},
+			},
+			shouldRetry: false,
+		},
+		{
+			app: &v1beta1.SparkApplication{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "default",
+				},
+				Spec: v1beta1.SparkApplicationSpec{
+					RestartPolicy: restartPolicyAlwaysExceptOOMKilled,
+				},
+				Status: v1beta1.SparkApplicationStatus{
+					DriverInfo: v1beta1.DriverInfo{
+						TerminationReason: "Error",
+					},
+					AppState: v1beta1.ApplicationState{
+						State: v1beta1.FailingState,
+					},
+				},
+			},
+			shouldRetry: true,
+		},
+		{
+			app: &v1beta1.SparkApplication{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "default",
+				},
+				Spec: v1beta1.SparkApplicationSpec{
+					RestartPolicy: restartPolicyNeverExceptExitCode,
+				},
+				Status: v1beta1.SparkApplicationStatus{
+					DriverInfo: v1beta1.DriverInfo{
+						ExitCode: int32ptr(143),
+					},
+					AppState: v1beta1.ApplicationState{
+						State: v1beta1.FailingState,
+					},
+					ExecutionAttempts: 1,
+				},
+			},
+			shouldRetry: true,
+		},
+		{
+			app: &v1beta1.SparkApplication{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "default",
+				},
+				Spec: v1beta1.SparkApplicationSpec{
+					RestartPolicy: restartPolicyNeverExceptExitCode,
+				},
+				Status: v1beta1.SparkApplicationStatus{
+					DriverInfo: v1beta1.DriverInfo{
+						ExitCode: int32ptr(143),
+					},
+					AppState: v1beta1.ApplicationState{
+						State: v1beta1.FailingState,
+					},
+					ExecutionAttempts: 2,
+				},
+			},
+			shouldRetry: false,
+		},
 	}
 
 	for _, test := range testcases {
@@ -1211,14 +1582,1035 @@ func TestSyncSparkApplication_ExecutingState(t *testing.T) {
 	}
 }
 
-func TestHasRetryIntervalPassed(t *testing.T) {
-	// Failure cases.
-	assert.False(t, hasRetryIntervalPassed(nil, 3, metav1.Time{Time: metav1.Now().Add(-100 * time.Second)}))
-	assert.False(t, hasRetryIntervalPassed(int64ptr(5), 0, metav1.Time{Time: metav1.Now().Add(-100 * time.Second)}))
-	assert.False(t, hasRetryIntervalPassed(int64ptr(5), 3, metav1.Time{}))
-	// Not enough time passed.
-	assert.False(t, hasRetryIntervalPassed(int64ptr(50), 3, metav1.Time{Time: metav1.Now().Add(-100 * time.Second)}))
-	assert.True(t, hasRetryIntervalPassed(int64ptr(50), 3, metav1.Time{Time: metav1.Now().Add(-151 * time.Second)}))
+func TestSyncSparkApplication_DynamicAllocationExecutorRemoved(t *testing.T) {
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+
+	appName := "foo"
+	driverPodName := appName + "-driver"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appName,
+			Namespace: "test",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{
+				Type: v1beta1.Never,
+			},
+			DynamicAllocation: &v1beta1.DynamicAllocation{
+				Enabled:      true,
+				MinExecutors: int32ptr(1),
+				MaxExecutors: int32ptr(5),
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State: v1beta1.RunningState,
+			},
+			DriverInfo: v1beta1.DriverInfo{
+				PodName: driverPodName,
+			},
+			ExecutionAttempts: 1,
+			ExecutorState: map[string]v1beta1.ExecutorState{
+				"exec-1": v1beta1.ExecutorRunningState,
+				"exec-2": v1beta1.ExecutorRunningState,
+			},
+		},
+	}
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      driverPodName,
+			Namespace: "test",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkDriverRole,
+				config.SparkAppNameLabel: appName,
+			},
+			ResourceVersion: "1",
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+		},
+	}
+	executorPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "exec-2",
+			Namespace: "test",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkExecutorRole,
+				config.SparkAppNameLabel: appName,
+			},
+			ResourceVersion: "1",
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+		},
+	}
+
+	ctrl, _ := newFakeController(app, driverPod, executorPod)
+	_, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(driverPod)
+	ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(executorPod)
+
+	err = ctrl.syncSparkApplication(fmt.Sprintf("%s/%s", app.Namespace, app.Name))
+	assert.Nil(t, err)
+
+	// The executor removed by dynamic allocation is not considered failed.
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.RunningState, updatedApp.Status.AppState.State)
+	assert.Equal(t, map[string]v1beta1.ExecutorState{
+		"exec-1": v1beta1.ExecutorCompletedState,
+		"exec-2": v1beta1.ExecutorRunningState,
+	}, updatedApp.Status.ExecutorState)
+	assert.Equal(t, float64(0), fetchCounterValue(ctrl.metrics.sparkAppExecutorFailureCount, map[string]string{}))
+}
+
+func TestSyncSparkApplication_ExecutorInfo(t *testing.T) {
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+
+	appName := "foo"
+	driverPodName := appName + "-driver"
+	startTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	finishedAt := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appName,
+			Namespace: "test",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{
+				Type: v1beta1.Never,
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State: v1beta1.RunningState,
+			},
+			DriverInfo: v1beta1.DriverInfo{
+				PodName: driverPodName,
+			},
+			ExecutionAttempts: 1,
+			ExecutorState: map[string]v1beta1.ExecutorState{
+				"exec-1": v1beta1.ExecutorRunningState,
+				"exec-3": v1beta1.ExecutorCompletedState,
+			},
+			Executors: map[string]v1beta1.ExecutorInfo{
+				"exec-1": {ExecutorID: "1", State: v1beta1.ExecutorRunningState},
+			},
+			OmittedExecutors: 1,
+		},
+	}
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      driverPodName,
+			Namespace: "test",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkDriverRole,
+				config.SparkAppNameLabel: appName,
+			},
+			ResourceVersion: "1",
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+		},
+	}
+	newExecutorPod := func(name, id string, phase apiv1.PodPhase) *apiv1.Pod {
+		return &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+				Labels: map[string]string{
+					config.SparkRoleLabel:    config.SparkExecutorRole,
+					config.SparkAppNameLabel: appName,
+					sparkExecutorIDLabel:     id,
+				},
+				ResourceVersion: "1",
+			},
+			Spec: apiv1.PodSpec{
+				NodeName: "node-" + id,
+			},
+			Status: apiv1.PodStatus{
+				Phase:     phase,
+				PodIP:     "10.0.0." + id,
+				StartTime: &startTime,
+			},
+		}
+	}
+	failedPod := newExecutorPod("exec-1", "1", apiv1.PodFailed)
+	failedPod.Status.ContainerStatuses = []apiv1.ContainerStatus{
+		{
+			Name:         config.SparkExecutorContainerName,
+			RestartCount: 1,
+			State: apiv1.ContainerState{
+				Terminated: &apiv1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled", FinishedAt: finishedAt},
+			},
+		},
+	}
+	runningPod := newExecutorPod("exec-2", "2", apiv1.PodRunning)
+	// The details of this executor were dropped before, so they are not recorded again.
+	completedPod := newExecutorPod("exec-3", "3", apiv1.PodSucceeded)
+
+	ctrl, _ := newFakeController(app, driverPod, failedPod, runningPod, completedPod)
+	_, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pod := range []*apiv1.Pod{driverPod, failedPod, runningPod, completedPod} {
+		ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(pod)
+	}
+
+	err = ctrl.syncSparkApplication(fmt.Sprintf("%s/%s", app.Namespace, app.Name))
+	assert.Nil(t, err)
+
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]v1beta1.ExecutorInfo{
+		"exec-1": {
+			ExecutorID:        "1",
+			State:             v1beta1.ExecutorFailedState,
+			NodeName:          "node-1",
+			PodIP:             "10.0.0.1",
+			StartTime:         startTime,
+			TerminationTime:   finishedAt,
+			ExitCode:          int32ptr(137),
+			TerminationReason: "OOMKilled",
+			RestartCount:      1,
+		},
+		"exec-2": {
+			ExecutorID: "2",
+			State:      v1beta1.ExecutorRunningState,
+			NodeName:   "node-2",
+			PodIP:      "10.0.0.2",
+			StartTime:  startTime,
+		},
+	}, updatedApp.Status.Executors)
+	assert.Equal(t, int32(1), updatedApp.Status.OmittedExecutors)
+}
+
+func TestPruneExecutorInfos(t *testing.T) {
+	now := time.Now()
+	app := &v1beta1.SparkApplication{
+		Status: v1beta1.SparkApplicationStatus{
+			Executors: map[string]v1beta1.ExecutorInfo{
+				"exec-1": {State: v1beta1.ExecutorFailedState, TerminationTime: metav1.NewTime(now.Add(-3 * time.Hour))},
+				"exec-2": {State: v1beta1.ExecutorCompletedState, TerminationTime: metav1.NewTime(now.Add(-time.Hour))},
+				"exec-3": {State: v1beta1.ExecutorCompletedState, TerminationTime: metav1.NewTime(now.Add(-2 * time.Hour))},
+				"exec-4": {State: v1beta1.ExecutorRunningState},
+				"exec-5": {State: v1beta1.ExecutorPendingState},
+			},
+		},
+	}
+
+	// Nothing is dropped within the limit.
+	pruneExecutorInfos(app, 5)
+	assert.Equal(t, 5, len(app.Status.Executors))
+	assert.Equal(t, int32(0), app.Status.OmittedExecutors)
+
+	// Completed executors are dropped first, the ones that terminated the longest time ago first.
+	pruneExecutorInfos(app, 4)
+	assert.Equal(t, 4, len(app.Status.Executors))
+	assert.NotContains(t, app.Status.Executors, "exec-3")
+	assert.Equal(t, int32(1), app.Status.OmittedExecutors)
+
+	pruneExecutorInfos(app, 3)
+	assert.NotContains(t, app.Status.Executors, "exec-2")
+	assert.Contains(t, app.Status.Executors, "exec-1")
+
+	// Executors that have not terminated are never dropped.
+	pruneExecutorInfos(app, 1)
+	assert.Equal(t, 2, len(app.Status.Executors))
+	assert.Contains(t, app.Status.Executors, "exec-4")
+	assert.Contains(t, app.Status.Executors, "exec-5")
+	assert.Equal(t, int32(3), app.Status.OmittedExecutors)
+}
+
+func TestCompactExecutorStatus(t *testing.T) {
+	now := time.Now()
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			CompactExecutorStatus: &v1beta1.CompactExecutorStatus{MaxFailedExecutorDetails: int32ptr(1)},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			ExecutorState: map[string]v1beta1.ExecutorState{
+				"exec-1": v1beta1.ExecutorRunningState,
+				"exec-2": v1beta1.ExecutorFailedState,
+				"exec-3": v1beta1.ExecutorCompletedState,
+				"exec-4": v1beta1.ExecutorFailedState,
+				"exec-5": v1beta1.ExecutorCompletedState,
+			},
+			Executors: map[string]v1beta1.ExecutorInfo{
+				"exec-1": {State: v1beta1.ExecutorRunningState},
+				"exec-2": {State: v1beta1.ExecutorFailedState, TerminationTime: metav1.NewTime(now)},
+				"exec-4": {State: v1beta1.ExecutorFailedState, TerminationTime: metav1.NewTime(now.Add(-time.Hour))},
+			},
+			ExecutorCounts: &v1beta1.ExecutorCounts{Completed: 5, Failed: 1},
+		},
+	}
+	oldStates := map[string]v1beta1.ExecutorState{
+		"exec-1": v1beta1.ExecutorRunningState,
+		"exec-2": v1beta1.ExecutorRunningState,
+		"exec-3": v1beta1.ExecutorCompletedState,
+		"exec-4": v1beta1.ExecutorFailedState,
+	}
+	// The pod of exec-3 is gone.
+	podStates := map[string]v1beta1.ExecutorState{
+		"exec-1": v1beta1.ExecutorRunningState,
+		"exec-2": v1beta1.ExecutorFailedState,
+		"exec-4": v1beta1.ExecutorFailedState,
+		"exec-5": v1beta1.ExecutorCompletedState,
+	}
+
+	compactExecutorStatus(app, oldStates, podStates)
+	assert.Equal(t, &v1beta1.ExecutorCounts{Running: 1, Completed: 6, Failed: 2}, app.Status.ExecutorCounts)
+	assert.Equal(t, map[string]v1beta1.ExecutorState{
+		"exec-1": v1beta1.ExecutorRunningState,
+		"exec-2": v1beta1.ExecutorFailedState,
+		"exec-4": v1beta1.ExecutorFailedState,
+		"exec-5": v1beta1.ExecutorCompletedState,
+	}, app.Status.ExecutorState)
+	// Only the details of the most recently failed executor are kept.
+	assert.Equal(t, []string{"exec-2"}, executorInfoNames(app))
+
+	// Terminated executors recorded before the executor status became compact are counted.
+	app = &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{CompactExecutorStatus: &v1beta1.CompactExecutorStatus{}},
+		Status: v1beta1.SparkApplicationStatus{
+			ExecutorState: map[string]v1beta1.ExecutorState{
+				"exec-1": v1beta1.ExecutorCompletedState,
+				"exec-2": v1beta1.ExecutorFailedState,
+				"exec-3": v1beta1.ExecutorPendingState,
+			},
+		},
+	}
+	compactExecutorStatus(app, app.Status.ExecutorState, map[string]v1beta1.ExecutorState{})
+	assert.Equal(t, &v1beta1.ExecutorCounts{Pending: 1, Completed: 1, Failed: 1}, app.Status.ExecutorCounts)
+	assert.Equal(t, 3, len(app.Status.ExecutorState))
+}
+
+func executorInfoNames(app *v1beta1.SparkApplication) []string {
+	var names []string
+	for name := range app.Status.Executors {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestEnqueueForPodEvent(t *testing.T) {
+	ctrl, _ := newFakeController(nil)
+
+	// Without a status update interval, the key is processed right away.
+	ctrl.enqueueForPodEvent("test/foo")
+	assert.Equal(t, 1, ctrl.queue.Len())
+	key, _ := ctrl.queue.Get()
+	ctrl.queue.Done(key)
+	ctrl.queue.Forget(key)
+
+	// With a status update interval, a burst of pod events is processed once after the interval.
+	ctrl.statusUpdateInterval = 100 * time.Millisecond
+	for i := 0; i < 5; i++ {
+		ctrl.enqueueForPodEvent("test/foo")
+	}
+	assert.Equal(t, 0, ctrl.queue.Len())
+	time.Sleep(500 * time.Millisecond)
+	assert.Equal(t, 1, ctrl.queue.Len())
+}
+
+func TestSyncSparkApplication_Deadlines(t *testing.T) {
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+
+	type testcase struct {
+		name                    string
+		driverPhase             apiv1.PodPhase
+		timeToLiveBeforeRunning *int64
+		activeDeadlineSeconds   *int64
+		expectedState           v1beta1.ApplicationStateType
+		expectedReason          string
+	}
+	testcases := []testcase{
+		{
+			name:                    "pending for too long",
+			driverPhase:             apiv1.PodPending,
+			timeToLiveBeforeRunning: int64ptr(100),
+			expectedState:           v1beta1.FailingState,
+			expectedReason:          v1beta1.PendingDeadlineExceededReason,
+		},
+		{
+			name:                    "running within the deadlines",
+			driverPhase:             apiv1.PodRunning,
+			timeToLiveBeforeRunning: int64ptr(100),
+			activeDeadlineSeconds:   int64ptr(300),
+			expectedState:           v1beta1.RunningState,
+		},
+		{
+			name:                  "running for too long",
+			driverPhase:           apiv1.PodRunning,
+			activeDeadlineSeconds: int64ptr(100),
+			expectedState:         v1beta1.FailingState,
+			expectedReason:        v1beta1.ActiveDeadlineExceededReason,
+		},
+	}
+
+	for _, test := range testcases {
+		app := &v1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "test",
+			},
+			Spec: v1beta1.SparkApplicationSpec{
+				TimeToLiveBeforeRunning: test.timeToLiveBeforeRunning,
+				ActiveDeadlineSeconds:   test.activeDeadlineSeconds,
+			},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState: v1beta1.ApplicationState{
+					State: v1beta1.SubmittedState,
+				},
+				DriverInfo: v1beta1.DriverInfo{
+					PodName: "foo-driver",
+				},
+				LastSubmissionAttemptTime: metav1.Time{Time: metav1.Now().Add(-200 * time.Second)},
+				ExecutionAttempts:         1,
+			},
+		}
+		driverPod := &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-driver",
+				Namespace: "test",
+				Labels: map[string]string{
+					config.SparkRoleLabel:    config.SparkDriverRole,
+					config.SparkAppNameLabel: "foo",
+				},
+				ResourceVersion: "1",
+			},
+			Status: apiv1.PodStatus{
+				Phase: test.driverPhase,
+			},
+		}
+
+		ctrl, _ := newFakeController(app, driverPod)
+		if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+			t.Fatal(err)
+		}
+		ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(driverPod)
+
+		err := ctrl.syncSparkApplication("test/foo")
+		assert.Nil(t, err, test.name)
+		updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.expectedState, updatedApp.Status.AppState.State, test.name)
+		assert.Equal(t, test.expectedReason, updatedApp.Status.DriverInfo.TerminationReason, test.name)
+		if test.expectedReason != "" {
+			// The driver is killed.
+			_, err = ctrl.kubeClient.CoreV1().Pods(app.Namespace).Get(driverPod.Name, metav1.GetOptions{})
+			assert.True(t, errors.IsNotFound(err), test.name)
+			assert.False(t, updatedApp.Status.TerminationTime.IsZero(), test.name)
+		}
+	}
+}
+
+func TestSyncSparkApplication_ExecutorFailureBudget(t *testing.T) {
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+
+	type testcase struct {
+		name                 string
+		maxFailures          int32
+		failureWindowSeconds *int64
+		pastFailures         []v1beta1.ExecutorFailure
+		expectedState        v1beta1.ApplicationStateType
+		expectedFailures     int
+	}
+	testcases := []testcase{
+		{
+			name:          "budget exhausted",
+			maxFailures:   1,
+			expectedState: v1beta1.FailingState,
+		},
+		{
+			name:             "within budget",
+			maxFailures:      2,
+			expectedState:    v1beta1.RunningState,
+			expectedFailures: 2,
+		},
+		{
+			name:                 "past failures out of the window",
+			maxFailures:          2,
+			failureWindowSeconds: int64ptr(600),
+			pastFailures: []v1beta1.ExecutorFailure{
+				{PodName: "foo-exec-0", Time: metav1.NewTime(time.Now().Add(-20 * time.Minute))},
+			},
+			expectedState:    v1beta1.RunningState,
+			expectedFailures: 2,
+		},
+		{
+			name:                 "past failures within the window",
+			maxFailures:          2,
+			failureWindowSeconds: int64ptr(600),
+			pastFailures: []v1beta1.ExecutorFailure{
+				{PodName: "foo-exec-0", Time: metav1.NewTime(time.Now().Add(-5 * time.Minute))},
+			},
+			expectedState: v1beta1.FailingState,
+		},
+	}
+
+	for _, test := range testcases {
+		app := &v1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "test",
+			},
+			Spec: v1beta1.SparkApplicationSpec{
+				Executor: v1beta1.ExecutorSpec{
+					MaxFailures:          int32ptr(test.maxFailures),
+					FailureWindowSeconds: test.failureWindowSeconds,
+				},
+			},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState: v1beta1.ApplicationState{
+					State: v1beta1.RunningState,
+				},
+				DriverInfo: v1beta1.DriverInfo{
+					PodName: "foo-driver",
+				},
+				ExecutorState:             map[string]v1beta1.ExecutorState{"foo-exec-0": v1beta1.ExecutorFailedState},
+				ExecutorFailures:          test.pastFailures,
+				LastSubmissionAttemptTime: metav1.Now(),
+				ExecutionAttempts:         1,
+			},
+		}
+		driverPod := &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-driver",
+				Namespace: "test",
+				Labels: map[string]string{
+					config.SparkRoleLabel:    config.SparkDriverRole,
+					config.SparkAppNameLabel: "foo",
+				},
+				ResourceVersion: "1",
+			},
+			Status: apiv1.PodStatus{
+				Phase: apiv1.PodRunning,
+			},
+		}
+		newExecutorPod := func(name string, status apiv1.PodStatus) *apiv1.Pod {
+			return &apiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "test",
+					Labels: map[string]string{
+						config.SparkRoleLabel:    config.SparkExecutorRole,
+						config.SparkAppNameLabel: "foo",
+					},
+					ResourceVersion: "1",
+				},
+				Status: status,
+			}
+		}
+		crashedPod := newExecutorPod("foo-exec-1", apiv1.PodStatus{
+			Phase: apiv1.PodFailed,
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{State: apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{ExitCode: 134, Reason: "Error"}}},
+			},
+		})
+		evictedPod := newExecutorPod("foo-exec-2", apiv1.PodStatus{Phase: apiv1.PodFailed, Reason: "Evicted"})
+
+		ctrl, recorder := newFakeController(app, driverPod, crashedPod, evictedPod)
+		if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+			t.Fatal(err)
+		}
+		ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(driverPod)
+
+		err := ctrl.syncSparkApplication("test/foo")
+		assert.Nil(t, err, test.name)
+		updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.expectedState, updatedApp.Status.AppState.State, test.name)
+		if test.expectedState == v1beta1.FailingState {
+			assert.Equal(t, v1beta1.ExecutorFailureBudgetExhaustedReason, updatedApp.Status.DriverInfo.TerminationReason, test.name)
+			assert.Contains(t, updatedApp.Status.AppState.ErrorMessage, "executor failure budget exhausted", test.name)
+			assert.Contains(t, updatedApp.Status.AppState.ErrorMessage, "foo-exec-1 (ExitCode: 134, Reason: Error)", test.name)
+			assert.Contains(t, updatedApp.Status.AppState.ErrorMessage, "foo-exec-2 (Reason: Evicted)", test.name)
+			// The driver is killed.
+			_, err = ctrl.kubeClient.CoreV1().Pods(app.Namespace).Get(driverPod.Name, metav1.GetOptions{})
+			assert.True(t, errors.IsNotFound(err), test.name)
+		} else {
+			assert.Equal(t, test.expectedFailures, len(updatedApp.Status.ExecutorFailures), test.name)
+		}
+		for len(recorder.Events) > 0 {
+			<-recorder.Events
+		}
+	}
+}
+
+func TestGetPodPendingReason(t *testing.T) {
+	type testcase struct {
+		name           string
+		status         apiv1.PodStatus
+		expectedReason string
+	}
+	testcases := []testcase{
+		{
+			name:   "running",
+			status: apiv1.PodStatus{Phase: apiv1.PodRunning},
+		},
+		{
+			name: "unschedulable",
+			status: apiv1.PodStatus{
+				Phase: apiv1.PodPending,
+				Conditions: []apiv1.PodCondition{
+					{Type: apiv1.PodScheduled, Status: apiv1.ConditionFalse, Reason: "Unschedulable"},
+				},
+			},
+			expectedReason: "Unschedulable",
+		},
+		{
+			name: "creating containers",
+			status: apiv1.PodStatus{
+				Phase: apiv1.PodPending,
+				Conditions: []apiv1.PodCondition{
+					{Type: apiv1.PodScheduled, Status: apiv1.ConditionTrue},
+				},
+				ContainerStatuses: []apiv1.ContainerStatus{
+					{State: apiv1.ContainerState{Waiting: &apiv1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+				},
+			},
+		},
+		{
+			name: "image pull failure",
+			status: apiv1.PodStatus{
+				Phase: apiv1.PodPending,
+				ContainerStatuses: []apiv1.ContainerStatus{
+					{State: apiv1.ContainerState{Waiting: &apiv1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+				},
+			},
+			expectedReason: "ImagePullBackOff",
+		},
+		{
+			name: "init container failure",
+			status: apiv1.PodStatus{
+				Phase: apiv1.PodPending,
+				InitContainerStatuses: []apiv1.ContainerStatus{
+					{State: apiv1.ContainerState{Waiting: &apiv1.ContainerStateWaiting{Reason: "CreateContainerConfigError"}}},
+				},
+				ContainerStatuses: []apiv1.ContainerStatus{
+					{State: apiv1.ContainerState{Waiting: &apiv1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+				},
+			},
+			expectedReason: "CreateContainerConfigError",
+		},
+	}
+
+	for _, test := range testcases {
+		reason, _ := getPodPendingReason(&apiv1.Pod{Status: test.status})
+		assert.Equal(t, test.expectedReason, reason, test.name)
+	}
+}
+
+func TestSyncSparkApplication_UnrecoverablePending(t *testing.T) {
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+
+	type testcase struct {
+		name          string
+		reason        string
+		pendingSince  time.Duration
+		policy        *v1beta1.UnrecoverablePendingPolicy
+		expectedState v1beta1.ApplicationStateType
+	}
+	testcases := []testcase{
+		{
+			name:          "no policy",
+			reason:        "ImagePullBackOff",
+			pendingSince:  time.Hour,
+			expectedState: v1beta1.SubmittedState,
+		},
+		{
+			name:          "unrecoverable for too long",
+			reason:        "ImagePullBackOff",
+			pendingSince:  10 * time.Minute,
+			policy:        &v1beta1.UnrecoverablePendingPolicy{FailAfterMinutes: 5},
+			expectedState: v1beta1.FailingState,
+		},
+		{
+			name:          "unrecoverable for a short time",
+			reason:        "ImagePullBackOff",
+			pendingSince:  time.Minute,
+			policy:        &v1beta1.UnrecoverablePendingPolicy{FailAfterMinutes: 5},
+			expectedState: v1beta1.SubmittedState,
+		},
+		{
+			name:          "recoverable for long",
+			reason:        "Unschedulable",
+			pendingSince:  time.Hour,
+			policy:        &v1beta1.UnrecoverablePendingPolicy{FailAfterMinutes: 5},
+			expectedState: v1beta1.SubmittedState,
+		},
+		{
+			name:          "custom unrecoverable reason",
+			reason:        "Unschedulable",
+			pendingSince:  time.Hour,
+			policy:        &v1beta1.UnrecoverablePendingPolicy{FailAfterMinutes: 5, Reasons: []string{"Unschedulable"}},
+			expectedState: v1beta1.FailingState,
+		},
+	}
+
+	for _, test := range testcases {
+		app := &v1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "test",
+			},
+			Spec: v1beta1.SparkApplicationSpec{
+				UnrecoverablePendingPolicy: test.policy,
+			},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState: v1beta1.ApplicationState{
+					State: v1beta1.SubmittedState,
+				},
+				DriverInfo: v1beta1.DriverInfo{
+					PodName:       "foo-driver",
+					PendingReason: test.reason,
+					PendingSince:  metav1.NewTime(time.Now().Add(-test.pendingSince)),
+				},
+				LastSubmissionAttemptTime: metav1.NewTime(time.Now().Add(-test.pendingSince)),
+				ExecutionAttempts:         1,
+			},
+		}
+		driverPod := &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-driver",
+				Namespace: "test",
+				Labels: map[string]string{
+					config.SparkRoleLabel:    config.SparkDriverRole,
+					config.SparkAppNameLabel: "foo",
+				},
+				ResourceVersion: "1",
+			},
+			Status: apiv1.PodStatus{
+				Phase: apiv1.PodPending,
+			},
+		}
+		if test.reason == "Unschedulable" {
+			driverPod.Status.Conditions = []apiv1.PodCondition{
+				{Type: apiv1.PodScheduled, Status: apiv1.ConditionFalse, Reason: test.reason, Message: "0/3 nodes are available"},
+			}
+		} else {
+			driverPod.Status.ContainerStatuses = []apiv1.ContainerStatus{
+				{State: apiv1.ContainerState{Waiting: &apiv1.ContainerStateWaiting{Reason: test.reason, Message: "Back-off pulling image"}}},
+			}
+		}
+
+		ctrl, recorder := newFakeController(app, driverPod)
+		if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+			t.Fatal(err)
+		}
+		ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(driverPod)
+
+		err := ctrl.syncSparkApplication("test/foo")
+		assert.Nil(t, err, test.name)
+		updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.expectedState, updatedApp.Status.AppState.State, test.name)
+		if test.expectedState == v1beta1.FailingState {
+			assert.Equal(t, test.reason, updatedApp.Status.DriverInfo.TerminationReason, test.name)
+			assert.Contains(t, updatedApp.Status.AppState.ErrorMessage, test.reason, test.name)
+			// The driver is killed.
+			_, err = ctrl.kubeClient.CoreV1().Pods(app.Namespace).Get(driverPod.Name, metav1.GetOptions{})
+			assert.True(t, errors.IsNotFound(err), test.name)
+		} else {
+			// The reason was already recorded, so the time since which the driver is pending for it is kept.
+			assert.Equal(t, test.reason, updatedApp.Status.DriverInfo.PendingReason, test.name)
+			assert.NotEmpty(t, updatedApp.Status.DriverInfo.PendingMessage, test.name)
+			assert.True(t, updatedApp.Status.DriverInfo.PendingSince.Time.Before(time.Now().Add(-test.pendingSince/2)), test.name)
+		}
+		for len(recorder.Events) > 0 {
+			<-recorder.Events
+		}
+	}
+}
+
+func TestUpdateDriverPendingReason(t *testing.T) {
+	app := &v1beta1.SparkApplication{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"}}
+	ctrl, recorder := newFakeController(app)
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-driver", Namespace: "test"},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodPending,
+			Conditions: []apiv1.PodCondition{
+				{Type: apiv1.PodScheduled, Status: apiv1.ConditionFalse, Reason: "Unschedulable", Message: "Insufficient cpu"},
+			},
+		},
+	}
+
+	// A new reason is recorded with a warning event.
+	ctrl.updateDriverPendingReason(app, driverPod)
+	assert.Equal(t, "Unschedulable", app.Status.DriverInfo.PendingReason)
+	assert.Equal(t, "Insufficient cpu", app.Status.DriverInfo.PendingMessage)
+	assert.False(t, app.Status.DriverInfo.PendingSince.IsZero())
+	assert.Equal(t, 1, len(recorder.Events))
+	event := <-recorder.Events
+	assert.True(t, strings.Contains(event, "Warning"))
+	assert.True(t, strings.Contains(event, "Unschedulable"))
+
+	// The same reason is not reported again.
+	ctrl.updateDriverPendingReason(app, driverPod)
+	assert.Equal(t, 0, len(recorder.Events))
+
+	// The reason is cleared once the driver is running.
+	driverPod.Status = apiv1.PodStatus{Phase: apiv1.PodRunning}
+	ctrl.updateDriverPendingReason(app, driverPod)
+	assert.Equal(t, "", app.Status.DriverInfo.PendingReason)
+	assert.True(t, app.Status.DriverInfo.PendingSince.IsZero())
+}
+
+func TestUpdateDriverPendingReason_AlternatingReasons(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec: v1beta1.SparkApplicationSpec{
+			UnrecoverablePendingPolicy: &v1beta1.UnrecoverablePendingPolicy{FailAfterMinutes: 5},
+		},
+	}
+	ctrl, recorder := newFakeController(app)
+	newPendingPod := func(reason string) *apiv1.Pod {
+		return &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo-driver", Namespace: "test"},
+			Status: apiv1.PodStatus{
+				Phase: apiv1.PodPending,
+				ContainerStatuses: []apiv1.ContainerStatus{{
+					Name:  config.SparkDriverContainerName,
+					State: apiv1.ContainerState{Waiting: &apiv1.ContainerStateWaiting{Reason: reason, Message: "image not found"}},
+				}},
+			},
+		}
+	}
+
+	ctrl.updateDriverPendingReason(app, newPendingPod("ErrImagePull"))
+	pendingSince := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	app.Status.DriverInfo.PendingSince = pendingSince
+	assert.Equal(t, 1, len(recorder.Events))
+	<-recorder.Events
+
+	// Alternating between unrecoverable reasons keeps the time since which the driver is pending, without events.
+	for _, reason := range []string{"ImagePullBackOff", "ErrImagePull", "ImagePullBackOff"} {
+		ctrl.updateDriverPendingReason(app, newPendingPod(reason))
+		assert.Equal(t, reason, app.Status.DriverInfo.PendingReason)
+		assert.Equal(t, pendingSince, app.Status.DriverInfo.PendingSince)
+		assert.Equal(t, 0, len(recorder.Events))
+	}
+
+	// A recoverable reason starts over.
+	unschedulablePod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-driver", Namespace: "test"},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodPending,
+			Conditions: []apiv1.PodCondition{
+				{Type: apiv1.PodScheduled, Status: apiv1.ConditionFalse, Reason: "Unschedulable", Message: "Insufficient cpu"},
+			},
+		},
+	}
+	ctrl.updateDriverPendingReason(app, unschedulablePod)
+	assert.True(t, app.Status.DriverInfo.PendingSince.After(pendingSince.Time))
+	assert.Equal(t, 1, len(recorder.Events))
+}
+
+func TestSyncSparkApplication_SuspendAndResume(t *testing.T) {
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+
+	suspend := true
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "test",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Suspend: &suspend,
+			RestartPolicy: v1beta1.RestartPolicy{
+				Type:             v1beta1.OnFailure,
+				OnFailureRetries: int32ptr(1),
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State: v1beta1.RunningState,
+			},
+			DriverInfo: v1beta1.DriverInfo{
+				PodName: "foo-driver",
+			},
+			ExecutionAttempts: 2,
+			MemoryHistory: []v1beta1.AttemptMemory{
+				{Attempt: 1, DriverMemory: "1g", ExecutorMemory: "1g"},
+				{Attempt: 2, DriverMemory: "1g", ExecutorMemory: "1g"},
+			},
+		},
+	}
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-driver",
+			Namespace: "test",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkDriverRole,
+				config.SparkAppNameLabel: "foo",
+			},
+			ResourceVersion: "1",
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+		},
+	}
+
+	ctrl, recorder := newFakeController(app, driverPod)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+	ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(driverPod)
+
+	// The driver is killed and the application suspended without consuming a retry.
+	err := ctrl.syncSparkApplication("test/foo")
+	assert.Nil(t, err)
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.SuspendedState, updatedApp.Status.AppState.State)
+	assert.Equal(t, int32(1), updatedApp.Status.ExecutionAttempts)
+	assert.Equal(t, 1, len(updatedApp.Status.MemoryHistory))
+	_, err = ctrl.kubeClient.CoreV1().Pods(app.Namespace).Get(driverPod.Name, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	assert.Equal(t, 2, len(recorder.Events))
+	<-recorder.Events
+	<-recorder.Events
+
+	// Resuming the application makes it pending rerun.
+	updatedApp.Spec.Suspend = nil
+	ctrl, _ = newFakeController(updatedApp)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(updatedApp); err != nil {
+		t.Fatal(err)
+	}
+	err = ctrl.syncSparkApplication("test/foo")
+	assert.Nil(t, err)
+	updatedApp, err = ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.PendingRerunState, updatedApp.Status.AppState.State)
+	assert.Equal(t, int32(1), updatedApp.Status.ExecutionAttempts)
+}
+
+func TestHasRetryIntervalPassed(t *testing.T) {
+	newApp := func() *v1beta1.SparkApplication { return &v1beta1.SparkApplication{} }
+
+	// Failure cases.
+	assert.False(t, hasRetryIntervalPassed(newApp(), nil, 3, metav1.Time{Time: metav1.Now().Add(-100 * time.Second)}))
+	assert.False(t, hasRetryIntervalPassed(newApp(), int64ptr(5), 0, metav1.Time{Time: metav1.Now().Add(-100 * time.Second)}))
+	assert.False(t, hasRetryIntervalPassed(newApp(), int64ptr(5), 3, metav1.Time{}))
+	// Not enough time passed.
+	assert.False(t, hasRetryIntervalPassed(newApp(), int64ptr(50), 3, metav1.Time{Time: metav1.Now().Add(-100 * time.Second)}))
+	assert.True(t, hasRetryIntervalPassed(newApp(), int64ptr(50), 3, metav1.Time{Time: metav1.Now().Add(-151 * time.Second)}))
+
+	// The time of the retry is recorded in the status.
+	lastEventTime := metav1.Time{Time: metav1.Now().Add(-100 * time.Second)}
+	app := newApp()
+	assert.False(t, hasRetryIntervalPassed(app, int64ptr(50), 3, lastEventTime))
+	assert.Equal(t, lastEventTime.Add(150*time.Second), app.Status.NextRetryTime.Time)
+	// A recorded time of the retry is not computed again.
+	app.Status.NextRetryTime = metav1.Time{Time: metav1.Now().Add(-1 * time.Second)}
+	assert.True(t, hasRetryIntervalPassed(app, int64ptr(50), 3, lastEventTime))
+
+	// The backoff policy takes precedence over the linear back-off.
+	app = newApp()
+	app.Spec.RestartPolicy.Backoff = &v1beta1.BackoffPolicy{InitialIntervalSeconds: 10}
+	assert.False(t, hasRetryIntervalPassed(app, int64ptr(5), 4, lastEventTime))
+	assert.Equal(t, lastEventTime.Add(80*time.Second), app.Status.NextRetryTime.Time)
+	app = newApp()
+	app.Spec.RestartPolicy.Backoff = &v1beta1.BackoffPolicy{InitialIntervalSeconds: 10}
+	assert.True(t, hasRetryIntervalPassed(app, nil, 3, lastEventTime))
+}
+
+func TestSyncSparkApplication_AddsCleanupFinalizer(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State: v1beta1.CompletedState,
+			},
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	_, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ctrl.syncSparkApplication("default/foo")
+	assert.Nil(t, err)
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{config.CleanupFinalizer}, updatedApp.Finalizers)
+	assert.Equal(t, v1beta1.CompletedState, updatedApp.Status.AppState.State)
+}
+
+func TestSyncSparkApplication_Deletion(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "foo",
+			Namespace:         "default",
+			DeletionTimestamp: &deletionTimestamp,
+			Finalizers:        []string{config.CleanupFinalizer},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State: v1beta1.RunningState,
+			},
+			DriverInfo: v1beta1.DriverInfo{
+				PodName:          "foo-driver",
+				WebUIServiceName: "foo-ui-svc",
+			},
+		},
+	}
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-driver",
+			Namespace: "default",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkDriverRole,
+				config.SparkAppNameLabel: "foo",
+			},
+		},
+	}
+	ctrl, _ := newFakeController(app, driverPod)
+	_, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(driverPod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ctrl.kubeClient.CoreV1().Services(app.Namespace).Create(&apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-ui-svc",
+			Namespace: "default",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ctrl.syncSparkApplication("default/foo")
+	assert.Nil(t, err)
+
+	_, err = ctrl.kubeClient.CoreV1().Pods(app.Namespace).Get("foo-driver", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = ctrl.kubeClient.CoreV1().Services(app.Namespace).Get("foo-ui-svc", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(updatedApp.Finalizers))
 }
 
 func stringptr(s string) *string {
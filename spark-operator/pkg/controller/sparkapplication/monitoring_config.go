@@ -135,6 +135,7 @@ func buildPrometheusConfigMap(app *v1beta1.SparkApplication, prometheusConfigMap
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            prometheusConfigMapName,
 			Namespace:       app.Namespace,
+			Labels:          map[string]string{config.SparkAppNameLabel: app.Name},
 			OwnerReferences: []metav1.OwnerReference{*getOwnerReference(app)},
 		},
 		Data: map[string]string{
@@ -0,0 +1,510 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	sparkDriverPortKey                = "spark.driver.port"
+	sparkDriverBlockManagerPortKey    = "spark.driver.blockManager.port"
+	sparkDriverHostKey                = "spark.driver.host"
+	sparkAppIDKey                     = "spark.app.id"
+	sparkMasterKey                    = "spark.master"
+	sparkDeployModeKey                = "spark.submit.deployMode"
+	sparkJarsKey                      = "spark.jars"
+	sparkFilesKey                     = "spark.files"
+	sparkPyFilesKey                   = "spark.submit.pyFiles"
+	sparkDriverCoresKey               = "spark.driver.cores"
+	sparkDriverMemoryKey              = "spark.driver.memory"
+	sparkDriverMemoryOverheadKey      = "spark.driver.memoryOverhead"
+	sparkExecutorPodNamePrefixKey     = "spark.kubernetes.executor.podNamePrefix"
+	sparkUIPortKey                    = "spark.ui.port"
+	defaultDriverPort                 = 7078
+	defaultDriverBlockManagerPort     = 7079
+	defaultSparkUIPort                = 4040
+	driverPortName                    = "driver-rpc-port"
+	driverBlockManagerPortName        = "blockmanager"
+	driverUIPortName                  = "spark-ui"
+	sparkDriverBindAddressEnvVar      = "SPARK_DRIVER_BIND_ADDRESS"
+	sparkConfVolumeName               = "spark-conf-volume"
+	sparkConfMountPath                = "/opt/spark/conf"
+	sparkPropertiesFileName           = "spark.properties"
+	driverServiceNameSuffix           = "-svc"
+	driverConfigMapNameSuffix         = "-conf-map"
+	defaultDriverMemory               = "1g"
+	defaultMemoryOverheadFactor       = 0.1
+	nonJvmDefaultMemoryOverheadFactor = 0.4
+	minMemoryOverheadBytes            = 384 * (1 << 20)
+	maxKubernetesNameLength           = 63
+)
+
+// nativeSubmitter is a Submitter that creates the driver pod, the headless driver service and the driver
+// ConfigMap of an application directly through the Kubernetes API, mirroring what spark-submit does in
+// cluster mode without starting a JVM.
+type nativeSubmitter struct {
+	kubeClient clientset.Interface
+}
+
+// NewNativeSubmitter creates a Submitter that creates the driver resources of applications through the given client.
+func NewNativeSubmitter(kubeClient clientset.Interface) Submitter {
+	return &nativeSubmitter{kubeClient: kubeClient}
+}
+
+func (s *nativeSubmitter) Submit(app *v1beta1.SparkApplication, driverPodName string, submissionID string) error {
+	if app.Spec.Mode != v1beta1.ClusterMode {
+		return newInvalidSpecError(app, fmt.Sprintf("deploy mode %q is not supported", app.Spec.Mode))
+	}
+
+	args, err := buildSubmissionCommandArgs(app, driverPodName, submissionID)
+	if err != nil {
+		return newInvalidSpecError(app, err.Error())
+	}
+	mainClass, properties, err := parseSubmissionCommandArgs(args)
+	if err != nil {
+		return newInvalidSpecError(app, err.Error())
+	}
+
+	serviceName := getResourceName(driverPodName, driverServiceNameSuffix)
+	configMapName := getResourceName(driverPodName, driverConfigMapNameSuffix)
+	appID := fmt.Sprintf("spark-%s", strings.Replace(submissionID, "-", "", -1))
+	properties[sparkAppIDKey] = appID
+	properties[sparkDriverHostKey] = fmt.Sprintf("%s.%s.svc", serviceName, app.Namespace)
+	if _, ok := properties[sparkDriverPortKey]; !ok {
+		properties[sparkDriverPortKey] = strconv.Itoa(defaultDriverPort)
+	}
+	if _, ok := properties[sparkDriverBlockManagerPortKey]; !ok {
+		properties[sparkDriverBlockManagerPortKey] = strconv.Itoa(defaultDriverBlockManagerPort)
+	}
+	if _, ok := properties[sparkExecutorPodNamePrefixKey]; !ok {
+		properties[sparkExecutorPodNamePrefixKey] = getResourceName(app.Name, "-"+submissionID[:8])
+	}
+
+	pod, err := buildDriverPod(app, driverPodName, configMapName, appID, mainClass, properties)
+	if err != nil {
+		return newInvalidSpecError(app, err.Error())
+	}
+	service, err := buildDriverService(app, serviceName, appID, properties)
+	if err != nil {
+		return newInvalidSpecError(app, err.Error())
+	}
+	configMap := buildDriverConfigMap(app, configMapName, properties)
+
+	createdPod, err := s.kubeClient.CoreV1().Pods(app.Namespace).Create(pod)
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return newAlreadySubmittedError(app)
+		}
+		return fmt.Errorf("failed to create driver pod %s/%s: %v", app.Namespace, driverPodName, err)
+	}
+
+	// The driver service and ConfigMap are owned by the driver pod so they get garbage collected with it.
+	ownerReference := getDriverPodOwnerReference(createdPod)
+	service.OwnerReferences = []metav1.OwnerReference{ownerReference}
+	configMap.OwnerReferences = []metav1.OwnerReference{ownerReference}
+	if _, err := s.kubeClient.CoreV1().ConfigMaps(app.Namespace).Create(configMap); err != nil && !errors.IsAlreadyExists(err) {
+		s.deleteDriverPod(app.Namespace, driverPodName)
+		return fmt.Errorf("failed to create driver ConfigMap %s/%s: %v", app.Namespace, configMapName, err)
+	}
+	if _, err := s.kubeClient.CoreV1().Services(app.Namespace).Create(service); err != nil && !errors.IsAlreadyExists(err) {
+		s.deleteDriverPod(app.Namespace, driverPodName)
+		return fmt.Errorf("failed to create driver service %s/%s: %v", app.Namespace, serviceName, err)
+	}
+
+	return nil
+}
+
+func (s *nativeSubmitter) deleteDriverPod(namespace string, name string) {
+	err := s.kubeClient.CoreV1().Pods(namespace).Delete(name, metav1.NewDeleteOptions(0))
+	if err != nil && !errors.IsNotFound(err) {
+		glog.Errorf("failed to delete driver pod %s/%s: %v", namespace, name, err)
+	}
+}
+
+// parseSubmissionCommandArgs turns the spark-submit arguments built by buildSubmissionCommandArgs into the main
+// class and the Spark configuration properties the driver is started with.
+func parseSubmissionCommandArgs(args []string) (string, map[string]string, error) {
+	var mainClass string
+	properties := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "--") {
+			break
+		}
+		if i+1 >= len(args) {
+			return "", nil, fmt.Errorf("missing value for option %s", args[i])
+		}
+		value := args[i+1]
+		switch args[i] {
+		case "--class":
+			mainClass = value
+		case "--master":
+			properties[sparkMasterKey] = value
+		case "--deploy-mode":
+			properties[sparkDeployModeKey] = value
+		case "--jars":
+			properties[sparkJarsKey] = value
+		case "--files":
+			properties[sparkFilesKey] = value
+		case "--py-files":
+			properties[sparkPyFilesKey] = value
+		case "--conf":
+			parts := strings.SplitN(value, "=", 2)
+			if len(parts) != 2 {
+				return "", nil, fmt.Errorf("invalid Spark configuration property %q", value)
+			}
+			properties[parts[0]] = parts[1]
+		default:
+			return "", nil, fmt.Errorf("unsupported option %s", args[i])
+		}
+		i++
+	}
+	return mainClass, properties, nil
+}
+
+func buildDriverPod(
+	app *v1beta1.SparkApplication,
+	driverPodName string,
+	configMapName string,
+	appID string,
+	mainClass string,
+	properties map[string]string) (*apiv1.Pod, error) {
+	image := properties[config.SparkDriverContainerImageKey]
+	if image == "" {
+		image = properties[config.SparkContainerImageKey]
+	}
+	if image == "" {
+		return nil, fmt.Errorf("no image specified for the driver")
+	}
+
+	resources, err := getDriverResourceRequirements(app, properties)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := getDriverPorts(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"driver", "--properties-file", fmt.Sprintf("%s/%s", sparkConfMountPath, sparkPropertiesFileName)}
+	if mainClass != "" {
+		args = append(args, "--class", mainClass)
+	}
+	if app.Spec.MainApplicationFile != nil {
+		args = append(args, *app.Spec.MainApplicationFile)
+	}
+	args = append(args, app.Spec.Arguments...)
+
+	labels := map[string]string{
+		config.SparkApplicationSelectorLabel: appID,
+		config.SparkRoleLabel:                config.SparkDriverRole,
+	}
+	annotations := make(map[string]string)
+	nodeSelector := make(map[string]string)
+	env := []apiv1.EnvVar{
+		{
+			Name: sparkDriverBindAddressEnvVar,
+			ValueFrom: &apiv1.EnvVarSource{
+				FieldRef: &apiv1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "status.podIP"},
+			},
+		},
+	}
+	volumes := []apiv1.Volume{
+		{
+			Name: sparkConfVolumeName,
+			VolumeSource: apiv1.VolumeSource{
+				ConfigMap: &apiv1.ConfigMapVolumeSource{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: configMapName},
+				},
+			},
+		},
+	}
+	volumeMounts := []apiv1.VolumeMount{{Name: sparkConfVolumeName, MountPath: sparkConfMountPath}}
+
+	for _, key := range sortedKeys(properties) {
+		value := properties[key]
+		switch {
+		case strings.HasPrefix(key, config.SparkDriverLabelKeyPrefix):
+			labels[strings.TrimPrefix(key, config.SparkDriverLabelKeyPrefix)] = value
+		case strings.HasPrefix(key, config.SparkDriverAnnotationKeyPrefix):
+			annotations[strings.TrimPrefix(key, config.SparkDriverAnnotationKeyPrefix)] = value
+		case strings.HasPrefix(key, config.SparkNodeSelectorKeyPrefix):
+			nodeSelector[strings.TrimPrefix(key, config.SparkNodeSelectorKeyPrefix)] = value
+		case strings.HasPrefix(key, config.SparkDriverEnvVarConfigKeyPrefix):
+			env = append(env, apiv1.EnvVar{Name: strings.TrimPrefix(key, config.SparkDriverEnvVarConfigKeyPrefix), Value: value})
+		case strings.HasPrefix(key, config.SparkDriverSecretKeyRefKeyPrefix):
+			parts := strings.SplitN(value, ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid secret key reference %q", value)
+			}
+			env = append(env, apiv1.EnvVar{
+				Name: strings.TrimPrefix(key, config.SparkDriverSecretKeyRefKeyPrefix),
+				ValueFrom: &apiv1.EnvVarSource{
+					SecretKeyRef: &apiv1.SecretKeySelector{
+						LocalObjectReference: apiv1.LocalObjectReference{Name: parts[0]},
+						Key:                  parts[1],
+					},
+				},
+			})
+		case strings.HasPrefix(key, config.SparkDriverSecretKeyPrefix):
+			secretName := strings.TrimPrefix(key, config.SparkDriverSecretKeyPrefix)
+			volumeName := fmt.Sprintf("%s-volume", secretName)
+			volumes = append(volumes, apiv1.Volume{
+				Name:         volumeName,
+				VolumeSource: apiv1.VolumeSource{Secret: &apiv1.SecretVolumeSource{SecretName: secretName}},
+			})
+			volumeMounts = append(volumeMounts, apiv1.VolumeMount{Name: volumeName, MountPath: value})
+		}
+	}
+
+	var imagePullSecrets []apiv1.LocalObjectReference
+	if secrets, ok := properties[config.SparkImagePullSecretKey]; ok {
+		for _, secret := range strings.Split(secrets, ",") {
+			if secret = strings.TrimSpace(secret); secret != "" {
+				imagePullSecrets = append(imagePullSecrets, apiv1.LocalObjectReference{Name: secret})
+			}
+		}
+	}
+
+	return &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        driverPodName,
+			Namespace:   app.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: apiv1.PodSpec{
+			RestartPolicy:      apiv1.RestartPolicyNever,
+			ServiceAccountName: properties[config.SparkDriverServiceAccountName],
+			ImagePullSecrets:   imagePullSecrets,
+			NodeSelector:       nodeSelector,
+			Volumes:            volumes,
+			Containers: []apiv1.Container{
+				{
+					Name:            config.SparkDriverContainerName,
+					Image:           image,
+					ImagePullPolicy: apiv1.PullPolicy(properties[config.SparkContainerImagePullPolicyKey]),
+					Args:            args,
+					Env:             env,
+					Ports:           ports,
+					Resources:       resources,
+					VolumeMounts:    volumeMounts,
+				},
+			},
+		},
+	}, nil
+}
+
+func buildDriverService(
+	app *v1beta1.SparkApplication,
+	serviceName string,
+	appID string,
+	properties map[string]string) (*apiv1.Service, error) {
+	driverPort, err := getPortProperty(properties, sparkDriverPortKey, defaultDriverPort)
+	if err != nil {
+		return nil, err
+	}
+	blockManagerPort, err := getPortProperty(properties, sparkDriverBlockManagerPortKey, defaultDriverBlockManagerPort)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName,
+			Namespace: app.Namespace,
+			Labels:    getResourceLabels(app),
+		},
+		Spec: apiv1.ServiceSpec{
+			ClusterIP: apiv1.ClusterIPNone,
+			Selector: map[string]string{
+				config.SparkApplicationSelectorLabel: appID,
+				config.SparkRoleLabel:                config.SparkDriverRole,
+			},
+			Ports: []apiv1.ServicePort{
+				{
+					Name:       driverPortName,
+					Port:       driverPort,
+					TargetPort: intstr.FromInt(int(driverPort)),
+				},
+				{
+					Name:       driverBlockManagerPortName,
+					Port:       blockManagerPort,
+					TargetPort: intstr.FromInt(int(blockManagerPort)),
+				},
+			},
+		},
+	}, nil
+}
+
+func buildDriverConfigMap(app *v1beta1.SparkApplication, configMapName string, properties map[string]string) *apiv1.ConfigMap {
+	var content strings.Builder
+	for _, key := range sortedKeys(properties) {
+		content.WriteString(fmt.Sprintf("%s=%s\n", escapeProperty(key), escapeProperty(properties[key])))
+	}
+
+	return &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName,
+			Namespace: app.Namespace,
+			Labels:    getResourceLabels(app),
+		},
+		Data: map[string]string{sparkPropertiesFileName: content.String()},
+	}
+}
+
+// getDriverResourceRequirements computes the resource requests and limits of the driver container the same way
+// Spark's BasicDriverFeatureStep does.
+func getDriverResourceRequirements(app *v1beta1.SparkApplication, properties map[string]string) (apiv1.ResourceRequirements, error) {
+	cores := "1"
+	if value, ok := properties[sparkDriverCoresKey]; ok {
+		cores = value
+	}
+	cpu, err := resource.ParseQuantity(cores)
+	if err != nil {
+		return apiv1.ResourceRequirements{}, fmt.Errorf("invalid driver cores %q: %v", cores, err)
+	}
+
+	memory := defaultDriverMemory
+	if value, ok := properties[sparkDriverMemoryKey]; ok {
+		memory = value
+	}
+	memoryBytes, err := util.ParseJavaMemoryString(memory)
+	if err != nil {
+		return apiv1.ResourceRequirements{}, err
+	}
+
+	var overheadBytes int64
+	if value, ok := properties[sparkDriverMemoryOverheadKey]; ok {
+		if overheadBytes, err = util.ParseJavaMemoryString(value); err != nil {
+			return apiv1.ResourceRequirements{}, err
+		}
+	} else {
+		factor := defaultMemoryOverheadFactor
+		if app.Spec.Type != v1beta1.JavaApplicationType && app.Spec.Type != v1beta1.ScalaApplicationType {
+			factor = nonJvmDefaultMemoryOverheadFactor
+		}
+		if value, ok := properties[config.SparkMemoryOverheadFactor]; ok {
+			if factor, err = strconv.ParseFloat(value, 64); err != nil {
+				return apiv1.ResourceRequirements{}, fmt.Errorf("invalid memory overhead factor %q: %v", value, err)
+			}
+		}
+		overheadBytes = int64(math.Max(factor*float64(memoryBytes), minMemoryOverheadBytes))
+	}
+	memoryQuantity := *resource.NewQuantity(memoryBytes+overheadBytes, resource.BinarySI)
+
+	requirements := apiv1.ResourceRequirements{
+		Requests: apiv1.ResourceList{
+			apiv1.ResourceCPU:    cpu,
+			apiv1.ResourceMemory: memoryQuantity,
+		},
+		Limits: apiv1.ResourceList{
+			apiv1.ResourceMemory: memoryQuantity,
+		},
+	}
+	if value, ok := properties[config.SparkDriverCoreLimitKey]; ok {
+		limit, err := resource.ParseQuantity(value)
+		if err != nil {
+			return apiv1.ResourceRequirements{}, fmt.Errorf("invalid driver core limit %q: %v", value, err)
+		}
+		requirements.Limits[apiv1.ResourceCPU] = limit
+	}
+	return requirements, nil
+}
+
+func getDriverPorts(properties map[string]string) ([]apiv1.ContainerPort, error) {
+	driverPort, err := getPortProperty(properties, sparkDriverPortKey, defaultDriverPort)
+	if err != nil {
+		return nil, err
+	}
+	blockManagerPort, err := getPortProperty(properties, sparkDriverBlockManagerPortKey, defaultDriverBlockManagerPort)
+	if err != nil {
+		return nil, err
+	}
+	uiPort, err := getPortProperty(properties, sparkUIPortKey, defaultSparkUIPort)
+	if err != nil {
+		return nil, err
+	}
+	return []apiv1.ContainerPort{
+		{Name: driverPortName, ContainerPort: driverPort, Protocol: apiv1.ProtocolTCP},
+		{Name: driverBlockManagerPortName, ContainerPort: blockManagerPort, Protocol: apiv1.ProtocolTCP},
+		{Name: driverUIPortName, ContainerPort: uiPort, Protocol: apiv1.ProtocolTCP},
+	}, nil
+}
+
+func getPortProperty(properties map[string]string, key string, defaultPort int32) (int32, error) {
+	value, ok := properties[key]
+	if !ok {
+		return defaultPort, nil
+	}
+	port, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid port %q for %s: %v", value, key, err)
+	}
+	return int32(port), nil
+}
+
+func getDriverPodOwnerReference(pod *apiv1.Pod) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Name:       pod.Name,
+		UID:        pod.UID,
+		Controller: &controller,
+	}
+}
+
+// getResourceName appends the given suffix to the given name, truncating the name so the result is a valid
+// DNS-1035 label.
+func getResourceName(name string, suffix string) string {
+	if len(name)+len(suffix) > maxKubernetesNameLength {
+		name = strings.TrimRight(name[:maxKubernetesNameLength-len(suffix)], "-.")
+	}
+	return name + suffix
+}
+
+// escapeProperty escapes the characters that have a special meaning in Java properties files.
+func escapeProperty(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return replacer.Replace(value)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
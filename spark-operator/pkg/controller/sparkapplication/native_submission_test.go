@@ -0,0 +1,186 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+const testSubmissionID = "8a8ba4e4-5c6e-4a3c-9f0e-4a3b3f3b9d21"
+
+func newNativeSubmissionTestApp() *v1beta1.SparkApplication {
+	return &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Type:                v1beta1.ScalaApplicationType,
+			Mode:                v1beta1.ClusterMode,
+			Image:               stringptr("spark:2.4.0"),
+			MainClass:           stringptr("org.apache.spark.examples.SparkPi"),
+			MainApplicationFile: stringptr("local:///opt/spark/examples/jars/spark-examples.jar"),
+			Arguments:           []string{"1000"},
+			Deps: v1beta1.Dependencies{
+				Jars: []string{"local:///opt/spark/jars/foo.jar"},
+			},
+			Driver: v1beta1.DriverSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					Memory: stringptr("1g"),
+					Labels: map[string]string{"foo": "bar"},
+				},
+				ServiceAccount: stringptr("spark"),
+			},
+		},
+	}
+}
+
+func setKubernetesServiceEnv() {
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+}
+
+func TestNativeSubmitterSubmit(t *testing.T) {
+	setKubernetesServiceEnv()
+	kubeClient := kubeclientfake.NewSimpleClientset()
+	submitter := NewNativeSubmitter(kubeClient)
+	app := newNativeSubmissionTestApp()
+	driverPodName := getDriverPodName(app)
+
+	err := submitter.Submit(app, driverPodName, testSubmissionID)
+	assert.Nil(t, err)
+
+	pod, err := kubeClient.CoreV1().Pods(app.Namespace).Get(driverPodName, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "spark-8a8ba4e45c6e4a3c9f0e4a3b3f3b9d21", pod.Labels[config.SparkApplicationSelectorLabel])
+	assert.Equal(t, config.SparkDriverRole, pod.Labels[config.SparkRoleLabel])
+	assert.Equal(t, "bar", pod.Labels["foo"])
+	assert.Equal(t, app.Name, pod.Labels[config.SparkAppNameLabel])
+	assert.Equal(t, testSubmissionID, pod.Labels[config.SubmissionIDLabel])
+	assert.Equal(t, "spark", pod.Spec.ServiceAccountName)
+	assert.Equal(t, 1, len(pod.Spec.Containers))
+	container := pod.Spec.Containers[0]
+	assert.Equal(t, config.SparkDriverContainerName, container.Name)
+	assert.Equal(t, "spark:2.4.0", container.Image)
+	assert.Equal(t, []string{
+		"driver",
+		"--properties-file", "/opt/spark/conf/spark.properties",
+		"--class", "org.apache.spark.examples.SparkPi",
+		"local:///opt/spark/examples/jars/spark-examples.jar",
+		"1000",
+	}, container.Args)
+	// 1g of memory plus the minimum overhead of 384m.
+	expectedMemory := resource.MustParse("1408Mi")
+	assert.True(t, expectedMemory.Cmp(container.Resources.Requests[apiv1.ResourceMemory]) == 0)
+	assert.True(t, expectedMemory.Cmp(container.Resources.Limits[apiv1.ResourceMemory]) == 0)
+	expectedCPU := resource.MustParse("1")
+	assert.True(t, expectedCPU.Cmp(container.Resources.Requests[apiv1.ResourceCPU]) == 0)
+	assert.Equal(t, sparkDriverBindAddressEnvVar, container.Env[0].Name)
+	assert.Equal(t, "status.podIP", container.Env[0].ValueFrom.FieldRef.FieldPath)
+
+	service, err := kubeClient.CoreV1().Services(app.Namespace).Get(driverPodName+driverServiceNameSuffix, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, apiv1.ClusterIPNone, service.Spec.ClusterIP)
+	assert.Equal(t, pod.Labels[config.SparkApplicationSelectorLabel], service.Spec.Selector[config.SparkApplicationSelectorLabel])
+	assert.Equal(t, 2, len(service.Spec.Ports))
+	assert.Equal(t, int32(defaultDriverPort), service.Spec.Ports[0].Port)
+	assert.Equal(t, int32(defaultDriverBlockManagerPort), service.Spec.Ports[1].Port)
+	assert.Equal(t, "Pod", service.OwnerReferences[0].Kind)
+	assert.Equal(t, driverPodName, service.OwnerReferences[0].Name)
+
+	configMap, err := kubeClient.CoreV1().ConfigMaps(app.Namespace).Get(driverPodName+driverConfigMapNameSuffix, metav1.GetOptions{})
+	assert.Nil(t, err)
+	properties := configMap.Data[sparkPropertiesFileName]
+	assert.True(t, strings.Contains(properties, "spark.master=k8s://https://localhost:443\n"))
+	assert.True(t, strings.Contains(properties, "spark.jars=local:///opt/spark/jars/foo.jar\n"))
+	assert.True(t, strings.Contains(properties, "spark.driver.host=foo-driver-svc.default.svc\n"))
+	assert.True(t, strings.Contains(properties, "spark.kubernetes.driver.pod.name=foo-driver\n"))
+	assert.True(t, strings.Contains(properties, "spark.kubernetes.executor.podNamePrefix=foo-8a8ba4e4\n"))
+}
+
+func TestNativeSubmitterSubmitAlreadySubmitted(t *testing.T) {
+	setKubernetesServiceEnv()
+	kubeClient := kubeclientfake.NewSimpleClientset()
+	submitter := NewNativeSubmitter(kubeClient)
+	app := newNativeSubmissionTestApp()
+	driverPodName := getDriverPodName(app)
+
+	assert.Nil(t, submitter.Submit(app, driverPodName, testSubmissionID))
+	err := submitter.Submit(app, driverPodName, testSubmissionID)
+	assert.True(t, IsAlreadySubmitted(err))
+}
+
+func TestNativeSubmitterSubmitInvalidSpec(t *testing.T) {
+	setKubernetesServiceEnv()
+	kubeClient := kubeclientfake.NewSimpleClientset()
+	submitter := NewNativeSubmitter(kubeClient)
+
+	app := newNativeSubmissionTestApp()
+	app.Spec.Mode = v1beta1.ClientMode
+	err := submitter.Submit(app, getDriverPodName(app), testSubmissionID)
+	assert.True(t, IsInvalidSpec(err))
+
+	app = newNativeSubmissionTestApp()
+	app.Spec.Image = nil
+	err = submitter.Submit(app, getDriverPodName(app), testSubmissionID)
+	assert.True(t, IsInvalidSpec(err))
+
+	app = newNativeSubmissionTestApp()
+	app.Spec.Driver.Memory = stringptr("lots")
+	err = submitter.Submit(app, getDriverPodName(app), testSubmissionID)
+	assert.True(t, IsInvalidSpec(err))
+
+	pods, err := kubeClient.CoreV1().Pods("default").List(metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(pods.Items))
+}
+
+func TestParseSubmissionCommandArgs(t *testing.T) {
+	mainClass, properties, err := parseSubmissionCommandArgs([]string{
+		"--class", "Main",
+		"--master", "k8s://https://localhost:443",
+		"--deploy-mode", "cluster",
+		"--py-files", "a.py,b.py",
+		"--conf", "spark.driver.extraJavaOptions=-Dfoo=bar",
+		"main.py",
+		"--conf", "not-an-option",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Main", mainClass)
+	assert.Equal(t, map[string]string{
+		sparkMasterKey:                "k8s://https://localhost:443",
+		sparkDeployModeKey:            "cluster",
+		sparkPyFilesKey:               "a.py,b.py",
+		config.SparkDriverJavaOptions: "-Dfoo=bar",
+	}, properties)
+
+	_, _, err = parseSubmissionCommandArgs([]string{"--conf"})
+	assert.NotNil(t, err)
+	_, _, err = parseSubmissionCommandArgs([]string{"--conf", "foo"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// stateReasons maps application states to the CamelCase reasons used in conditions.
+var stateReasons = map[v1beta1.ApplicationStateType]string{
+	v1beta1.SubmittedState:        "Submitted",
+	v1beta1.RunningState:          "Running",
+	v1beta1.CompletedState:        "Completed",
+	v1beta1.FailedState:           "Failed",
+	v1beta1.FailedSubmissionState: "SubmissionFailed",
+	v1beta1.PendingRerunState:     "PendingRerun",
+	v1beta1.InvalidatingState:     "Invalidating",
+	v1beta1.SucceedingState:       "Succeeding",
+	v1beta1.FailingState:          "Failing",
+	v1beta1.UnknownState:          "Unknown",
+}
+
+// updateConditions computes the conditions of the application from its current state. The last transition time of
+// a condition is carried over from the previous conditions if the status of the condition has not changed.
+func updateConditions(app *v1beta1.SparkApplication, previous []v1beta1.SparkApplicationCondition) {
+	state := app.Status.AppState.State
+	if state == v1beta1.NewState {
+		app.Status.Conditions = nil
+		return
+	}
+
+	stateReason := stateReasons[state]
+	newCondition := func(
+		conditionType v1beta1.SparkApplicationConditionType,
+		status apiv1.ConditionStatus,
+		reason string,
+		message string) v1beta1.SparkApplicationCondition {
+		condition := v1beta1.SparkApplicationCondition{
+			Type:               conditionType,
+			Status:             status,
+			ObservedGeneration: app.Generation,
+			LastTransitionTime: metav1.Now(),
+			Reason:             reason,
+			Message:            message,
+		}
+		for _, c := range previous {
+			if c.Type == conditionType && c.Status == status {
+				condition.LastTransitionTime = c.LastTransitionTime
+			}
+		}
+		return condition
+	}
+
+	var submitted v1beta1.SparkApplicationCondition
+	switch {
+	case state == v1beta1.FailedSubmissionState || (state == v1beta1.FailedState && app.Status.SubmissionID == ""):
+		submitted = newCondition(v1beta1.SparkApplicationSubmitted, apiv1.ConditionFalse, stateReasons[v1beta1.FailedSubmissionState],
+			app.Status.AppState.ErrorMessage)
+	case state == v1beta1.PendingRerunState || state == v1beta1.InvalidatingState:
+		submitted = newCondition(v1beta1.SparkApplicationSubmitted, apiv1.ConditionFalse, stateReason, "")
+	default:
+		submitted = newCondition(v1beta1.SparkApplicationSubmitted, apiv1.ConditionTrue, "Submitted", "")
+	}
+
+	var driverReady, executorsReady v1beta1.SparkApplicationCondition
+	switch state {
+	case v1beta1.RunningState:
+		driverReady = newCondition(v1beta1.SparkApplicationDriverReady, apiv1.ConditionTrue, "DriverRunning", "")
+		expected := int32(1)
+		if app.Spec.Executor.Instances != nil {
+			expected = *app.Spec.Executor.Instances
+		}
+		// With dynamic allocation, only the minimum number of executors is guaranteed to be running.
+		if app.DynamicAllocationEnabled() {
+			expected = 0
+			if app.Spec.DynamicAllocation.MinExecutors != nil {
+				expected = *app.Spec.DynamicAllocation.MinExecutors
+			}
+		}
+		running := int32(0)
+		for _, executorState := range app.Status.ExecutorState {
+			if executorState == v1beta1.ExecutorRunningState {
+				running++
+			}
+		}
+		message := fmt.Sprintf("%d/%d executors running", running, expected)
+		if running >= expected {
+			executorsReady = newCondition(v1beta1.SparkApplicationExecutorsReady, apiv1.ConditionTrue, "ExecutorsRunning", message)
+		} else {
+			executorsReady = newCondition(v1beta1.SparkApplicationExecutorsReady, apiv1.ConditionFalse, "ExecutorsPending", message)
+		}
+	case v1beta1.UnknownState:
+		driverReady = newCondition(v1beta1.SparkApplicationDriverReady, apiv1.ConditionUnknown, stateReason, "")
+		executorsReady = newCondition(v1beta1.SparkApplicationExecutorsReady, apiv1.ConditionUnknown, stateReason, "")
+	default:
+		driverReady = newCondition(v1beta1.SparkApplicationDriverReady, apiv1.ConditionFalse, stateReason, "")
+		executorsReady = newCondition(v1beta1.SparkApplicationExecutorsReady, apiv1.ConditionFalse, stateReason, "")
+	}
+
+	completed := newCondition(v1beta1.SparkApplicationCompleted, apiv1.ConditionFalse, stateReason, "")
+	if state == v1beta1.CompletedState {
+		completed = newCondition(v1beta1.SparkApplicationCompleted, apiv1.ConditionTrue, stateReason, "")
+	}
+	failed := newCondition(v1beta1.SparkApplicationFailed, apiv1.ConditionFalse, stateReason, "")
+	if state == v1beta1.FailedState {
+		failed = newCondition(v1beta1.SparkApplicationFailed, apiv1.ConditionTrue, stateReason, app.Status.AppState.ErrorMessage)
+	}
+
+	app.Status.Conditions = []v1beta1.SparkApplicationCondition{submitted, driverReady, executorsReady, completed, failed}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func getCondition(app *v1beta1.SparkApplication, conditionType v1beta1.SparkApplicationConditionType) *v1beta1.SparkApplicationCondition {
+	for i := range app.Status.Conditions {
+		if app.Status.Conditions[i].Type == conditionType {
+			return &app.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func TestUpdateConditions(t *testing.T) {
+	type testcase struct {
+		name                   string
+		state                  v1beta1.ApplicationStateType
+		submissionID           string
+		executorState          map[string]v1beta1.ExecutorState
+		expectedSubmitted      apiv1.ConditionStatus
+		expectedDriverReady    apiv1.ConditionStatus
+		expectedExecutorsReady apiv1.ConditionStatus
+		expectedCompleted      apiv1.ConditionStatus
+		expectedFailed         apiv1.ConditionStatus
+	}
+
+	testcases := []testcase{
+		{
+			name:                   "submission failed",
+			state:                  v1beta1.FailedSubmissionState,
+			expectedSubmitted:      apiv1.ConditionFalse,
+			expectedDriverReady:    apiv1.ConditionFalse,
+			expectedExecutorsReady: apiv1.ConditionFalse,
+			expectedCompleted:      apiv1.ConditionFalse,
+			expectedFailed:         apiv1.ConditionFalse,
+		},
+		{
+			name:                   "running with pending executors",
+			state:                  v1beta1.RunningState,
+			submissionID:           "id",
+			executorState:          map[string]v1beta1.ExecutorState{"exec-1": v1beta1.ExecutorRunningState, "exec-2": v1beta1.ExecutorPendingState},
+			expectedSubmitted:      apiv1.ConditionTrue,
+			expectedDriverReady:    apiv1.ConditionTrue,
+			expectedExecutorsReady: apiv1.ConditionFalse,
+			expectedCompleted:      apiv1.ConditionFalse,
+			expectedFailed:         apiv1.ConditionFalse,
+		},
+		{
+			name:                   "running with all executors",
+			state:                  v1beta1.RunningState,
+			submissionID:           "id",
+			executorState:          map[string]v1beta1.ExecutorState{"exec-1": v1beta1.ExecutorRunningState, "exec-2": v1beta1.ExecutorRunningState},
+			expectedSubmitted:      apiv1.ConditionTrue,
+			expectedDriverReady:    apiv1.ConditionTrue,
+			expectedExecutorsReady: apiv1.ConditionTrue,
+			expectedCompleted:      apiv1.ConditionFalse,
+			expectedFailed:         apiv1.ConditionFalse,
+		},
+		{
+			name:                   "completed",
+			state:                  v1beta1.CompletedState,
+			submissionID:           "id",
+			expectedSubmitted:      apiv1.ConditionTrue,
+			expectedDriverReady:    apiv1.ConditionFalse,
+			expectedExecutorsReady: apiv1.ConditionFalse,
+			expectedCompleted:      apiv1.ConditionTrue,
+			expectedFailed:         apiv1.ConditionFalse,
+		},
+		{
+			name:                   "failed without submission",
+			state:                  v1beta1.FailedState,
+			expectedSubmitted:      apiv1.ConditionFalse,
+			expectedDriverReady:    apiv1.ConditionFalse,
+			expectedExecutorsReady: apiv1.ConditionFalse,
+			expectedCompleted:      apiv1.ConditionFalse,
+			expectedFailed:         apiv1.ConditionTrue,
+		},
+	}
+
+	for _, test := range testcases {
+		app := &v1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", Generation: 2},
+			Spec: v1beta1.SparkApplicationSpec{
+				Executor: v1beta1.ExecutorSpec{Instances: int32ptr(2)},
+			},
+			Status: v1beta1.SparkApplicationStatus{
+				SubmissionID:  test.submissionID,
+				AppState:      v1beta1.ApplicationState{State: test.state},
+				ExecutorState: test.executorState,
+			},
+		}
+		updateConditions(app, nil)
+		assert.Equal(t, 5, len(app.Status.Conditions), test.name)
+		assert.Equal(t, test.expectedSubmitted, getCondition(app, v1beta1.SparkApplicationSubmitted).Status, test.name)
+		assert.Equal(t, test.expectedDriverReady, getCondition(app, v1beta1.SparkApplicationDriverReady).Status, test.name)
+		assert.Equal(t, test.expectedExecutorsReady, getCondition(app, v1beta1.SparkApplicationExecutorsReady).Status, test.name)
+		assert.Equal(t, test.expectedCompleted, getCondition(app, v1beta1.SparkApplicationCompleted).Status, test.name)
+		assert.Equal(t, test.expectedFailed, getCondition(app, v1beta1.SparkApplicationFailed).Status, test.name)
+		for _, condition := range app.Status.Conditions {
+			assert.Equal(t, int64(2), condition.ObservedGeneration, test.name)
+		}
+	}
+}
+
+func TestUpdateConditions_KeepsTransitionTime(t *testing.T) {
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	app := &v1beta1.SparkApplication{
+		Status: v1beta1.SparkApplicationStatus{
+			SubmissionID: "id",
+			AppState:     v1beta1.ApplicationState{State: v1beta1.RunningState},
+		},
+	}
+	previous := []v1beta1.SparkApplicationCondition{
+		{Type: v1beta1.SparkApplicationSubmitted, Status: apiv1.ConditionTrue, LastTransitionTime: transitionTime},
+		{Type: v1beta1.SparkApplicationDriverReady, Status: apiv1.ConditionFalse, LastTransitionTime: transitionTime},
+	}
+
+	updateConditions(app, previous)
+	assert.Equal(t, transitionTime, getCondition(app, v1beta1.SparkApplicationSubmitted).LastTransitionTime)
+	assert.NotEqual(t, transitionTime, getCondition(app, v1beta1.SparkApplicationDriverReady).LastTransitionTime)
+
+	app.Status.AppState.State = v1beta1.NewState
+	updateConditions(app, app.Status.Conditions)
+	assert.Nil(t, app.Status.Conditions)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"reflect"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// collectOrphanedResources deletes the pods, Services, Ingresses and ConfigMaps labeled with the name of a
+// SparkApplication that no longer exists. Such resources are left behind if an application was deleted while
+// the operator was not running. This must be called after the application cache has synced.
+func (c *Controller) collectOrphanedResources() {
+	glog.Info("Collecting resources of deleted SparkApplications")
+	listOptions := metav1.ListOptions{LabelSelector: config.SparkAppNameLabel}
+
+	pods, err := c.kubeClient.CoreV1().Pods(c.namespace).List(listOptions)
+	if err != nil {
+		glog.Errorf("failed to list pods of SparkApplications: %v", err)
+	} else {
+		for _, pod := range pods.Items {
+			if c.isOrphaned(&pod.ObjectMeta) {
+				glog.Infof("Deleting orphaned pod %s/%s", pod.Namespace, pod.Name)
+				err := c.kubeClient.CoreV1().Pods(pod.Namespace).Delete(pod.Name, metav1.NewDeleteOptions(0))
+				logOrphanDeletionError("pod", pod.Namespace, pod.Name, err)
+			}
+		}
+	}
+
+	services, err := c.kubeClient.CoreV1().Services(c.namespace).List(listOptions)
+	if err != nil {
+		glog.Errorf("failed to list Services of SparkApplications: %v", err)
+	} else {
+		for _, service := range services.Items {
+			if c.isOrphaned(&service.ObjectMeta) {
+				glog.Infof("Deleting orphaned Service %s/%s", service.Namespace, service.Name)
+				err := c.kubeClient.CoreV1().Services(service.Namespace).Delete(service.Name, metav1.NewDeleteOptions(0))
+				logOrphanDeletionError("Service", service.Namespace, service.Name, err)
+			}
+		}
+	}
+
+	ingresses, err := c.kubeClient.ExtensionsV1beta1().Ingresses(c.namespace).List(listOptions)
+	if err != nil {
+		glog.Errorf("failed to list Ingresses of SparkApplications: %v", err)
+	} else {
+		for _, ingress := range ingresses.Items {
+			if c.isOrphaned(&ingress.ObjectMeta) {
+				glog.Infof("Deleting orphaned Ingress %s/%s", ingress.Namespace, ingress.Name)
+				err := c.kubeClient.ExtensionsV1beta1().Ingresses(ingress.Namespace).Delete(ingress.Name, metav1.NewDeleteOptions(0))
+				logOrphanDeletionError("Ingress", ingress.Namespace, ingress.Name, err)
+			}
+		}
+	}
+
+	configMaps, err := c.kubeClient.CoreV1().ConfigMaps(c.namespace).List(listOptions)
+	if err != nil {
+		glog.Errorf("failed to list ConfigMaps of SparkApplications: %v", err)
+	} else {
+		for _, configMap := range configMaps.Items {
+			if c.isOrphaned(&configMap.ObjectMeta) {
+				glog.Infof("Deleting orphaned ConfigMap %s/%s", configMap.Namespace, configMap.Name)
+				err := c.kubeClient.CoreV1().ConfigMaps(configMap.Namespace).Delete(configMap.Name, metav1.NewDeleteOptions(0))
+				logOrphanDeletionError("ConfigMap", configMap.Namespace, configMap.Name, err)
+			}
+		}
+	}
+}
+
+// isOrphaned tells if the SparkApplication a resource was created for no longer exists. A resource owned by an
+// older SparkApplication of the same name is also considered orphaned.
+func (c *Controller) isOrphaned(meta *metav1.ObjectMeta) bool {
+	appName, ok := meta.Labels[config.SparkAppNameLabel]
+	if !ok {
+		return false
+	}
+	app, err := c.applicationLister.SparkApplications(meta.Namespace).Get(appName)
+	if err != nil {
+		return errors.IsNotFound(err)
+	}
+	appKind := reflect.TypeOf(v1beta1.SparkApplication{}).Name()
+	for _, owner := range meta.OwnerReferences {
+		if owner.Kind == appKind && owner.Name == app.Name && owner.UID != app.UID {
+			return true
+		}
+	}
+	return false
+}
+
+func logOrphanDeletionError(kind string, namespace string, name string, err error) {
+	if err != nil && !errors.IsNotFound(err) {
+		glog.Errorf("failed to delete orphaned %s %s/%s: %v", kind, namespace, name, err)
+	}
+}
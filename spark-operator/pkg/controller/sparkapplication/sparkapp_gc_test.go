@@ -0,0 +1,77 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestCollectOrphanedResources(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       "foo-uid",
+		},
+	}
+	ctrl, _ := newFakeController(app)
+
+	appLabels := map[string]string{config.SparkAppNameLabel: "foo"}
+	deletedAppLabels := map[string]string{config.SparkAppNameLabel: "bar"}
+	ctrl.kubeClient.CoreV1().Pods("default").Create(&apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-driver", Namespace: "default", Labels: appLabels},
+	})
+	ctrl.kubeClient.CoreV1().Pods("default").Create(&apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar-driver", Namespace: "default", Labels: deletedAppLabels},
+	})
+	ctrl.kubeClient.CoreV1().Pods("default").Create(&apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"},
+	})
+	ctrl.kubeClient.CoreV1().Services("default").Create(&apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar-ui-svc", Namespace: "default", Labels: deletedAppLabels},
+	})
+	// Owned by an earlier SparkApplication with the same name.
+	ctrl.kubeClient.CoreV1().ConfigMaps("default").Create(&apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "foo-prom-conf",
+			Namespace:       "default",
+			Labels:          appLabels,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "SparkApplication", Name: "foo", UID: "old-foo-uid"}},
+		},
+	})
+
+	ctrl.collectOrphanedResources()
+
+	_, err := ctrl.kubeClient.CoreV1().Pods("default").Get("foo-driver", metav1.GetOptions{})
+	assert.Nil(t, err)
+	_, err = ctrl.kubeClient.CoreV1().Pods("default").Get("unrelated", metav1.GetOptions{})
+	assert.Nil(t, err)
+	_, err = ctrl.kubeClient.CoreV1().Pods("default").Get("bar-driver", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = ctrl.kubeClient.CoreV1().Services("default").Get("bar-ui-svc", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = ctrl.kubeClient.CoreV1().ConfigMaps("default").Get("foo-prom-conf", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
@@ -140,7 +140,7 @@ func (sm *sparkAppMetrics) registerMetrics() {
 }
 
 func (sm *sparkAppMetrics) exportMetrics(oldApp, newApp *v1beta1.SparkApplication) {
-	metricLabels := fetchMetricLabels(newApp.Labels, sm.labels)
+	metricLabels := util.FetchMetricLabels(newApp.Labels, sm.labels)
 	glog.V(2).Infof("Exporting metrics for %s; old status: %v new status: %v", newApp.Name,
 		oldApp.Status, newApp.Status)
 
@@ -193,6 +193,10 @@ func (sm *sparkAppMetrics) exportMetrics(oldApp, newApp *v1beta1.SparkApplicatio
 			} else {
 				m.Inc()
 			}
+		case v1beta1.SuspendingState:
+			if oldState == v1beta1.RunningState || oldState == v1beta1.UnknownState {
+				sm.sparkAppRunningCount.Dec(metricLabels)
+			}
 		}
 	}
 
@@ -230,22 +234,3 @@ func (sm *sparkAppMetrics) exportMetrics(oldApp, newApp *v1beta1.SparkApplicatio
 		}
 	}
 }
-
-func fetchMetricLabels(specLabels map[string]string, labels []string) map[string]string {
-	// Transform spec labels since our labels names might be not same as specLabels if we removed invalid characters.
-	validSpecLabels := make(map[string]string)
-	for labelKey, v := range specLabels {
-		newKey := util.CreateValidMetricNameLabel("", labelKey)
-		validSpecLabels[newKey] = v
-	}
-
-	metricLabels := make(map[string]string)
-	for _, label := range labels {
-		if value, ok := validSpecLabels[label]; ok {
-			metricLabels[label] = value
-		} else {
-			metricLabels[label] = "Unknown"
-		}
-	}
-	return metricLabels
-}
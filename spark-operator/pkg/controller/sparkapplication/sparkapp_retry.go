@@ -0,0 +1,234 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	defaultBackoffMultiplier = 2.0
+	// defaultMaxBackoffIntervalSeconds caps the backoff interval if the backoff policy does not set a maximum.
+	defaultMaxBackoffIntervalSeconds = 24 * 60 * 60
+	defaultMemoryIncreaseFactor      = 1.5
+	// defaultMemory is the driver and executor memory Spark uses if spark.driver.memory or spark.executor.memory
+	// is not set.
+	defaultMemory = "1g"
+	mebibyte      = 1 << 20
+	// maxMemoryHistoryLength is the number of most recent execution attempts the memory is recorded of.
+	maxMemoryHistoryLength = 10
+	oomKilledReason        = "OOMKilled"
+)
+
+// getNextRetryTime returns the time an application can be retried at after the given number of attempts since the
+// last event. A zero time is returned if no retry interval is configured.
+func getNextRetryTime(restartPolicy *v1beta1.RestartPolicy, retryInterval *int64, attemptsDone int32, lastEventTime metav1.Time) metav1.Time {
+	if lastEventTime.IsZero() || attemptsDone <= 0 {
+		return metav1.Time{}
+	}
+	if restartPolicy.Backoff != nil {
+		return metav1.NewTime(lastEventTime.Add(getBackoffInterval(restartPolicy.Backoff, attemptsDone, rand.Float64())))
+	}
+	if retryInterval == nil {
+		return metav1.Time{}
+	}
+	// We do a linear back-off without a backoff policy.
+	return metav1.NewTime(lastEventTime.Add(time.Duration(*retryInterval) * time.Second * time.Duration(attemptsDone)))
+}
+
+// getBackoffInterval returns the interval before the retry following the given number of attempts. The random value
+// in [0, 1) determines where the interval falls in the jitter range.
+func getBackoffInterval(backoff *v1beta1.BackoffPolicy, attemptsDone int32, random float64) time.Duration {
+	multiplier := defaultBackoffMultiplier
+	if backoff.Multiplier != nil {
+		multiplier = *backoff.Multiplier
+	}
+	maxSeconds := float64(defaultMaxBackoffIntervalSeconds)
+	if backoff.MaxIntervalSeconds != nil {
+		maxSeconds = float64(*backoff.MaxIntervalSeconds)
+	}
+	// The interval is capped before it is converted to a Duration as it grows without bounds with the attempts.
+	seconds := float64(backoff.InitialIntervalSeconds) * math.Pow(multiplier, float64(attemptsDone-1))
+	if seconds > maxSeconds {
+		seconds = maxSeconds
+	}
+	if backoff.JitterFactor != nil {
+		seconds *= 1 + *backoff.JitterFactor*(2*random-1)
+	}
+	if seconds >= float64(math.MaxInt64)/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// getFailureRule returns the first failure rule of the application matching the termination of its driver, or nil
+// if none matches.
+func getFailureRule(app *v1beta1.SparkApplication) *v1beta1.FailureRule {
+	for i := range app.Spec.RestartPolicy.FailureRules {
+		rule := &app.Spec.RestartPolicy.FailureRules[i]
+		if failureRuleMatches(rule, app.Status.DriverInfo.TerminationReason, app.Status.DriverInfo.ExitCode) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func failureRuleMatches(rule *v1beta1.FailureRule, reason string, exitCode *int32) bool {
+	if len(rule.Reasons) > 0 {
+		matches := false
+		for _, r := range rule.Reasons {
+			if r == reason {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+	if len(rule.ExitCodes) > 0 {
+		if exitCode == nil {
+			return false
+		}
+		matches := false
+		for _, codes := range rule.ExitCodes {
+			max := codes.Min
+			if codes.Max != nil {
+				max = *codes.Max
+			}
+			if *exitCode >= codes.Min && *exitCode <= max {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
+
+// getDriverMemory returns the driver memory of the current attempt of the application.
+func getDriverMemory(app *v1beta1.SparkApplication) string {
+	if app.Status.DriverMemory != "" {
+		return app.Status.DriverMemory
+	}
+	if app.Spec.Driver.Memory != nil {
+		return *app.Spec.Driver.Memory
+	}
+	return defaultMemory
+}
+
+// getExecutorMemory returns the executor memory of the current attempt of the application.
+func getExecutorMemory(app *v1beta1.SparkApplication) string {
+	if app.Status.ExecutorMemory != "" {
+		return app.Status.ExecutorMemory
+	}
+	if app.Spec.Executor.Memory != nil {
+		return *app.Spec.Executor.Memory
+	}
+	return defaultMemory
+}
+
+// getIncreasedMemory multiplies the given memory by the factor, up to the maximum memory if there is one.
+func getIncreasedMemory(memory string, factor float64, maxMemory *string) (string, error) {
+	bytes, err := util.ParseJavaMemoryString(memory)
+	if err != nil {
+		return "", err
+	}
+	increased := int64(math.Ceil(float64(bytes) * factor / mebibyte))
+	if maxMemory != nil {
+		maxBytes, err := util.ParseJavaMemoryString(*maxMemory)
+		if err != nil {
+			return "", err
+		}
+		if increased*mebibyte > maxBytes {
+			if bytes >= maxBytes {
+				// Already at the ceiling, there is nothing to increase.
+				return memory, nil
+			}
+			return *maxMemory, nil
+		}
+	}
+	return fmt.Sprintf("%dm", increased), nil
+}
+
+// getMemoryIncreaseFactors returns the factors to increase the driver and executor memory by for the next attempt
+// of a failed application, or 0 to keep the memory as it is. Driver increases by RetryWithMoreMemory failure rules
+// take precedence over the ones on OOMs.
+func getMemoryIncreaseFactors(app *v1beta1.SparkApplication) (driverFactor float64, executorFactor float64) {
+	if rule := getFailureRule(app); rule != nil && rule.Action == v1beta1.FailureActionRetryWithMoreMemory {
+		driverFactor = defaultMemoryIncreaseFactor
+		if rule.MemoryIncreaseFactor != nil {
+			driverFactor = *rule.MemoryIncreaseFactor
+		}
+	}
+
+	policy := app.Spec.RestartPolicy.MemoryIncreaseOnOOM
+	if policy == nil {
+		return driverFactor, 0
+	}
+	attempt := getCurrentAttemptMemory(app)
+	if driverFactor == 0 && (app.Status.DriverInfo.TerminationReason == oomKilledReason || (attempt != nil && attempt.DriverOOMKilled)) {
+		driverFactor = policy.Factor
+	}
+	if attempt != nil && attempt.ExecutorOOMKilled {
+		executorFactor = policy.Factor
+	}
+	return driverFactor, executorFactor
+}
+
+// getCurrentAttemptMemory returns the memory record of the current execution attempt, or nil if there is none.
+func getCurrentAttemptMemory(app *v1beta1.SparkApplication) *v1beta1.AttemptMemory {
+	history := app.Status.MemoryHistory
+	if len(history) == 0 || history[len(history)-1].Attempt != app.Status.ExecutionAttempts {
+		return nil
+	}
+	return &history[len(history)-1]
+}
+
+// recordAttemptMemory records the memory of the current execution attempt, keeping the records of the most recent
+// attempts only.
+func recordAttemptMemory(app *v1beta1.SparkApplication) {
+	app.Status.MemoryHistory = append(app.Status.MemoryHistory, v1beta1.AttemptMemory{
+		Attempt:        app.Status.ExecutionAttempts,
+		DriverMemory:   getDriverMemory(app),
+		ExecutorMemory: getExecutorMemory(app),
+	})
+	if len(app.Status.MemoryHistory) > maxMemoryHistoryLength {
+		app.Status.MemoryHistory = app.Status.MemoryHistory[len(app.Status.MemoryHistory)-maxMemoryHistoryLength:]
+	}
+}
+
+// isOOMKilled tells if a container of the pod was killed for running out of memory.
+func isOOMKilled(pod *apiv1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.Reason == oomKilledReason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,420 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestGetBackoffInterval(t *testing.T) {
+	multiplier := 3.0
+	jitterFactor := 0.1
+	backoff := &v1beta1.BackoffPolicy{InitialIntervalSeconds: 10}
+
+	assert.Equal(t, 10*time.Second, getBackoffInterval(backoff, 1, 0.5))
+	assert.Equal(t, 20*time.Second, getBackoffInterval(backoff, 2, 0.5))
+	assert.Equal(t, 40*time.Second, getBackoffInterval(backoff, 3, 0.5))
+
+	backoff.Multiplier = &multiplier
+	assert.Equal(t, 90*time.Second, getBackoffInterval(backoff, 3, 0.5))
+
+	// The interval is capped.
+	backoff.MaxIntervalSeconds = int64ptr(60)
+	assert.Equal(t, 60*time.Second, getBackoffInterval(backoff, 3, 0.5))
+
+	// The jitter applies to the capped interval.
+	backoff.JitterFactor = &jitterFactor
+	assert.Equal(t, 54*time.Second, getBackoffInterval(backoff, 3, 0))
+	assert.Equal(t, 60*time.Second, getBackoffInterval(backoff, 3, 0.5))
+	assert.Equal(t, 63*time.Second, getBackoffInterval(backoff, 3, 0.75))
+
+	// The interval does not overflow after many attempts, with or without a maximum interval.
+	assert.Equal(t, 60*time.Second, getBackoffInterval(backoff, 1000, 0.5))
+	backoff.MaxIntervalSeconds = nil
+	backoff.JitterFactor = nil
+	assert.Equal(t, 24*time.Hour, getBackoffInterval(backoff, 1000, 0.5))
+	backoff.MaxIntervalSeconds = int64ptr(math.MaxInt64)
+	assert.Equal(t, time.Duration(math.MaxInt64), getBackoffInterval(backoff, 1000, 0.5))
+}
+
+func TestGetFailureRule(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{
+				FailureRules: []v1beta1.FailureRule{
+					{Reasons: []string{"OOMKilled"}, Action: v1beta1.FailureActionRetryWithMoreMemory},
+					{Reasons: []string{"Error"}, ExitCodes: []v1beta1.ExitCodeRange{{Min: 1}}, Action: v1beta1.FailureActionNeverRetry},
+					{ExitCodes: []v1beta1.ExitCodeRange{{Min: 128, Max: int32ptr(255)}}, Action: v1beta1.FailureActionRetry},
+				},
+			},
+		},
+	}
+
+	type testcase struct {
+		reason         string
+		exitCode       *int32
+		expectedAction v1beta1.FailureAction
+	}
+	testcases := []testcase{
+		{reason: "OOMKilled", exitCode: int32ptr(137), expectedAction: v1beta1.FailureActionRetryWithMoreMemory},
+		{reason: "Error", exitCode: int32ptr(1), expectedAction: v1beta1.FailureActionNeverRetry},
+		{reason: "Error", exitCode: int32ptr(143), expectedAction: v1beta1.FailureActionRetry},
+		{reason: "Evicted", expectedAction: ""},
+		{reason: "Error", exitCode: int32ptr(2), expectedAction: ""},
+	}
+
+	for _, test := range testcases {
+		app.Status.DriverInfo.TerminationReason = test.reason
+		app.Status.DriverInfo.ExitCode = test.exitCode
+		var action v1beta1.FailureAction
+		if rule := getFailureRule(app); rule != nil {
+			action = rule.Action
+		}
+		assert.Equal(t, test.expectedAction, action, test.reason)
+	}
+}
+
+func TestGetIncreasedMemory(t *testing.T) {
+	maxMemory := "2g"
+
+	increased, err := getIncreasedMemory("512m", 1.5, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "768m", increased)
+
+	increased, err = getIncreasedMemory("1g", 2, &maxMemory)
+	assert.Nil(t, err)
+	assert.Equal(t, "2048m", increased)
+
+	// The increased memory is capped.
+	increased, err = getIncreasedMemory("1536m", 2, &maxMemory)
+	assert.Nil(t, err)
+	assert.Equal(t, "2g", increased)
+	increased, err = getIncreasedMemory("3g", 2, &maxMemory)
+	assert.Nil(t, err)
+	assert.Equal(t, "3g", increased)
+
+	_, err = getIncreasedMemory("lots", 2, nil)
+	assert.NotNil(t, err)
+}
+
+func TestGetMemoryIncreaseFactors(t *testing.T) {
+	factor := 3.0
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{
+				FailureRules: []v1beta1.FailureRule{
+					{Reasons: []string{"OOMKilled"}, Action: v1beta1.FailureActionRetryWithMoreMemory},
+				},
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			DriverInfo:        v1beta1.DriverInfo{TerminationReason: "OOMKilled"},
+			ExecutionAttempts: 2,
+			MemoryHistory: []v1beta1.AttemptMemory{
+				{Attempt: 1},
+				{Attempt: 2, ExecutorOOMKilled: true},
+			},
+		},
+	}
+
+	// Only the failure rule applies without a memory increase policy.
+	driverFactor, executorFactor := getMemoryIncreaseFactors(app)
+	assert.Equal(t, defaultMemoryIncreaseFactor, driverFactor)
+	assert.Equal(t, float64(0), executorFactor)
+
+	// The failure rule takes precedence for the driver.
+	app.Spec.RestartPolicy.MemoryIncreaseOnOOM = &v1beta1.MemoryIncreasePolicy{Factor: factor}
+	driverFactor, executorFactor = getMemoryIncreaseFactors(app)
+	assert.Equal(t, defaultMemoryIncreaseFactor, driverFactor)
+	assert.Equal(t, factor, executorFactor)
+
+	app.Spec.RestartPolicy.FailureRules = nil
+	driverFactor, executorFactor = getMemoryIncreaseFactors(app)
+	assert.Equal(t, factor, driverFactor)
+	assert.Equal(t, factor, executorFactor)
+
+	// OOMs of previous attempts do not count.
+	app.Status.DriverInfo.TerminationReason = "Error"
+	app.Status.MemoryHistory[1].ExecutorOOMKilled = false
+	app.Status.MemoryHistory[0].ExecutorOOMKilled = true
+	driverFactor, executorFactor = getMemoryIncreaseFactors(app)
+	assert.Equal(t, float64(0), driverFactor)
+	assert.Equal(t, float64(0), executorFactor)
+}
+
+func TestRecordAttemptMemory(t *testing.T) {
+	memory := "2g"
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{Memory: &memory},
+			},
+		},
+	}
+
+	for attempt := int32(1); attempt <= maxMemoryHistoryLength+2; attempt++ {
+		app.Status.ExecutionAttempts = attempt
+		app.Status.DriverMemory = fmt.Sprintf("%dm", attempt*100)
+		recordAttemptMemory(app)
+	}
+
+	// Only the most recent attempts are recorded.
+	assert.Equal(t, maxMemoryHistoryLength, len(app.Status.MemoryHistory))
+	assert.Equal(t, v1beta1.AttemptMemory{Attempt: 3, DriverMemory: "300m", ExecutorMemory: "2g"}, app.Status.MemoryHistory[0])
+	assert.Equal(t, &v1beta1.AttemptMemory{Attempt: 12, DriverMemory: "1200m", ExecutorMemory: "2g"}, getCurrentAttemptMemory(app))
+}
+
+func TestSyncSparkApplication_FailureRules(t *testing.T) {
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+
+	appName := "foo"
+	driverPodName := appName + "-driver"
+	memory := "512m"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appName,
+			Namespace: "test",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{
+				Type:                   v1beta1.Never,
+				OnFailureRetries:       int32ptr(3),
+				OnFailureRetryInterval: int64ptr(10),
+				FailureRules: []v1beta1.FailureRule{
+					{Reasons: []string{"OOMKilled"}, Action: v1beta1.FailureActionRetryWithMoreMemory},
+				},
+			},
+			Driver: v1beta1.DriverSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{Memory: &memory},
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State: v1beta1.RunningState,
+			},
+			DriverInfo: v1beta1.DriverInfo{
+				PodName: driverPodName,
+			},
+			ExecutionAttempts: 1,
+		},
+	}
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      driverPodName,
+			Namespace: "test",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkDriverRole,
+				config.SparkAppNameLabel: appName,
+			},
+			ResourceVersion: "1",
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodFailed,
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{
+					Name: config.SparkDriverContainerName,
+					State: apiv1.ContainerState{
+						Terminated: &apiv1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"},
+					},
+				},
+			},
+		},
+	}
+
+	// The termination of the driver is recorded.
+	ctrl, _ := newFakeController(app, driverPod)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+	ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(driverPod)
+
+	err := ctrl.syncSparkApplication(fmt.Sprintf("%s/%s", app.Namespace, app.Name))
+	assert.Nil(t, err)
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.FailingState, updatedApp.Status.AppState.State)
+	assert.Equal(t, "OOMKilled", updatedApp.Status.DriverInfo.TerminationReason)
+	assert.Equal(t, int32ptr(137), updatedApp.Status.DriverInfo.ExitCode)
+
+	// The application is retried with more driver memory once the retry interval has passed.
+	failingApp := updatedApp.DeepCopy()
+	failingApp.Status.TerminationTime = metav1.Time{Time: metav1.Now().Add(-100 * time.Second)}
+	ctrl, _ = newFakeController(failingApp)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(failingApp); err != nil {
+		t.Fatal(err)
+	}
+
+	err = ctrl.syncSparkApplication(fmt.Sprintf("%s/%s", app.Namespace, app.Name))
+	assert.Nil(t, err)
+	updatedApp, err = ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.PendingRerunState, updatedApp.Status.AppState.State)
+	assert.Equal(t, "768m", updatedApp.Status.DriverMemory)
+	assert.False(t, updatedApp.Status.NextRetryTime.IsZero())
+	// The spec is left as it is.
+	assert.Equal(t, "512m", *updatedApp.Spec.Driver.Memory)
+}
+
+func TestSyncSparkApplication_MemoryIncreaseOnOOM(t *testing.T) {
+	os.Setenv(sparkHomeEnvVar, "/spark")
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+
+	appName := "foo"
+	driverPodName := appName + "-driver"
+	maxMemory := "1g"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appName,
+			Namespace: "test",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{
+				Type:                   v1beta1.OnFailure,
+				OnFailureRetries:       int32ptr(3),
+				OnFailureRetryInterval: int64ptr(10),
+				MemoryIncreaseOnOOM:    &v1beta1.MemoryIncreasePolicy{Factor: 2, MaxMemory: &maxMemory},
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State: v1beta1.RunningState,
+			},
+			DriverInfo: v1beta1.DriverInfo{
+				PodName: driverPodName,
+			},
+			ExecutionAttempts: 1,
+			DriverMemory:      "768m",
+			MemoryHistory: []v1beta1.AttemptMemory{
+				{Attempt: 1, DriverMemory: "768m", ExecutorMemory: "1g"},
+			},
+		},
+	}
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      driverPodName,
+			Namespace: "test",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkDriverRole,
+				config.SparkAppNameLabel: appName,
+			},
+			ResourceVersion: "1",
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodFailed,
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{
+					Name: config.SparkDriverContainerName,
+					State: apiv1.ContainerState{
+						Terminated: &apiv1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"},
+					},
+				},
+			},
+		},
+	}
+	executorPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "exec-1",
+			Namespace: "test",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkExecutorRole,
+				config.SparkAppNameLabel: appName,
+			},
+			ResourceVersion: "1",
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodFailed,
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{
+					Name: config.SparkExecutorContainerName,
+					State: apiv1.ContainerState{
+						Terminated: &apiv1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"},
+					},
+				},
+			},
+		},
+	}
+
+	// The OOMs of the driver and executor are detected.
+	ctrl, _ := newFakeController(app, driverPod, executorPod)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+	ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(driverPod)
+	ctrl.kubeClient.CoreV1().Pods(app.Namespace).Create(executorPod)
+
+	err := ctrl.syncSparkApplication(fmt.Sprintf("%s/%s", app.Namespace, app.Name))
+	assert.Nil(t, err)
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.FailingState, updatedApp.Status.AppState.State)
+	assert.Equal(t, []v1beta1.AttemptMemory{
+		{Attempt: 1, DriverMemory: "768m", ExecutorMemory: "1g", DriverOOMKilled: true, ExecutorOOMKilled: true},
+	}, updatedApp.Status.MemoryHistory)
+
+	// The memory of the next attempt is increased up to the maximum memory.
+	failingApp := updatedApp.DeepCopy()
+	failingApp.Status.TerminationTime = metav1.Time{Time: metav1.Now().Add(-100 * time.Second)}
+	ctrl, _ = newFakeController(failingApp)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(failingApp); err != nil {
+		t.Fatal(err)
+	}
+
+	err = ctrl.syncSparkApplication(fmt.Sprintf("%s/%s", app.Namespace, app.Name))
+	assert.Nil(t, err)
+	updatedApp, err = ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.PendingRerunState, updatedApp.Status.AppState.State)
+	assert.Equal(t, "1g", updatedApp.Status.DriverMemory)
+	assert.Equal(t, "1g", updatedApp.Status.ExecutorMemory)
+
+	// The next attempt is submitted with the increased memory, which is recorded.
+	pendingApp := updatedApp.DeepCopy()
+	ctrl, _ = newFakeController(pendingApp)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(pendingApp); err != nil {
+		t.Fatal(err)
+	}
+	execCommand = func(command string, args ...string) *exec.Cmd {
+		cs := []string{"-test.run=TestHelperProcessSuccess", "--", command}
+		cs = append(cs, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		return cmd
+	}
+
+	err = ctrl.syncSparkApplication(fmt.Sprintf("%s/%s", app.Namespace, app.Name))
+	assert.Nil(t, err)
+	updatedApp, err = ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.SubmittedState, updatedApp.Status.AppState.State)
+	assert.Equal(t, int32(2), updatedApp.Status.ExecutionAttempts)
+	assert.Equal(t, v1beta1.AttemptMemory{Attempt: 2, DriverMemory: "1g", ExecutorMemory: "1g"}, updatedApp.Status.MemoryHistory[1])
+	assert.Nil(t, updatedApp.Spec.Driver.Memory)
+}
@@ -18,10 +18,15 @@ package sparkapplication
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Helper method to create a key with namespace and appName
@@ -114,3 +119,363 @@ func driverStateToApplicationState(podStatus apiv1.PodStatus) v1beta1.Applicatio
 		return v1beta1.UnknownState
 	}
 }
+
+// hasPrometheusConfigMap tells if the operator creates a ConfigMap for the Prometheus configuration of the application.
+func hasPrometheusConfigMap(app *v1beta1.SparkApplication) bool {
+	return app.PrometheusMonitoringEnabled() && !app.HasPrometheusConfigFile()
+}
+
+func removeString(slice []string, s string) []string {
+	var result []string
+	for _, item := range slice {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func isSuspended(app *v1beta1.SparkApplication) bool {
+	return app.Spec.Suspend != nil && *app.Spec.Suspend
+}
+
+// canBeSuspended tells if an application in the given state has a run or a pending run to suspend. An application
+// being invalidated is suspended once it is pending rerun.
+func canBeSuspended(state v1beta1.ApplicationStateType) bool {
+	switch state {
+	case v1beta1.CompletedState, v1beta1.FailedState, v1beta1.InvalidatingState, v1beta1.SuspendingState,
+		v1beta1.SuspendedState:
+		return false
+	}
+	return true
+}
+
+// specChange is the class of a change to the spec of an application.
+type specChange int
+
+const (
+	noSpecChange specChange = iota
+	// inPlaceSpecChange only changes how the controller handles the application, so it applies to the current run.
+	inPlaceSpecChange
+	// resubmissionSpecChange changes what is submitted, e.g., the image, configuration, resources or dependencies,
+	// so it requires a new run.
+	resubmissionSpecChange
+)
+
+// classifySpecChange tells which class of change the new spec of an application is. Suspending or resuming the
+// application is not considered a spec change.
+func classifySpecChange(oldSpec, newSpec *v1beta1.SparkApplicationSpec) specChange {
+	oldSpec = oldSpec.DeepCopy()
+	newSpec = newSpec.DeepCopy()
+	oldSpec.Suspend = nil
+	newSpec.Suspend = nil
+	if equality.Semantic.DeepEqual(oldSpec, newSpec) {
+		return noSpecChange
+	}
+	clearInPlaceFields(oldSpec)
+	clearInPlaceFields(newSpec)
+	if equality.Semantic.DeepEqual(oldSpec, newSpec) {
+		return inPlaceSpecChange
+	}
+	return resubmissionSpecChange
+}
+
+// clearInPlaceFields clears the fields of the spec changes of which apply in place.
+func clearInPlaceFields(spec *v1beta1.SparkApplicationSpec) {
+	spec.RestartPolicy = v1beta1.RestartPolicy{}
+	spec.FailureRetries = nil
+	spec.RetryInterval = nil
+	spec.TimeToLiveBeforeRunning = nil
+	spec.ActiveDeadlineSeconds = nil
+	spec.TimeToLiveSecondsAfterFinished = nil
+	spec.UnrecoverablePendingPolicy = nil
+	spec.Executor.MaxFailures = nil
+	spec.Executor.FailureWindowSeconds = nil
+	spec.CompactExecutorStatus = nil
+}
+
+// defaultUnrecoverablePendingReasons are the reasons pods stay pending for that will not go away by themselves.
+var defaultUnrecoverablePendingReasons = []string{
+	"ErrImagePull",
+	"ImagePullBackOff",
+	"InvalidImageName",
+	"CreateContainerConfigError",
+	"CreateContainerError",
+}
+
+// getPodPendingReason returns the reason a pending pod is pending for and the message explaining it, taken from the
+// waiting states of its containers or from its PodScheduled condition. Volumes that cannot be mounted, e.g., of
+// missing ConfigMaps or Secrets, are only reported in events while the containers are ContainerCreating, so they are
+// not detected.
+func getPodPendingReason(pod *apiv1.Pod) (string, string) {
+	if pod.Status.Phase != apiv1.PodPending {
+		return "", ""
+	}
+	var statuses []apiv1.ContainerStatus
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		waiting := status.State.Waiting
+		// Containers are waiting for these reasons while the pod starts normally.
+		if waiting != nil && waiting.Reason != "" && waiting.Reason != "ContainerCreating" &&
+			waiting.Reason != "PodInitializing" {
+			return waiting.Reason, waiting.Message
+		}
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == apiv1.PodScheduled && condition.Status == apiv1.ConditionFalse {
+			return condition.Reason, condition.Message
+		}
+	}
+	return "", ""
+}
+
+func isUnrecoverablePendingReason(reason string, policy *v1beta1.UnrecoverablePendingPolicy) bool {
+	if reason == "" {
+		return false
+	}
+	reasons := policy.Reasons
+	if len(reasons) == 0 {
+		reasons = defaultUnrecoverablePendingReasons
+	}
+	for _, r := range reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// executorPodNotFoundReason is the reason of the failures of executors the pods of which disappeared.
+const executorPodNotFoundReason = "PodNotFound"
+
+// getExecutorTermination returns the exit code and the reason the given terminated executor pod terminated with, and
+// the time it terminated at if known.
+func getExecutorTermination(pod *apiv1.Pod) (*int32, string, metav1.Time) {
+	var exitCode *int32
+	var reason string
+	var finishedAt metav1.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		terminated := status.State.Terminated
+		if terminated == nil {
+			continue
+		}
+		code := terminated.ExitCode
+		exitCode = &code
+		reason = terminated.Reason
+		finishedAt = terminated.FinishedAt
+		// Prefer the container that failed over sidecars that were merely killed with the pod.
+		if code != 0 {
+			break
+		}
+	}
+	// Reasons of pod failures, e.g., Evicted, take precedence over the container's.
+	if pod.Status.Reason != "" {
+		reason = pod.Status.Reason
+	}
+	return exitCode, reason, finishedAt
+}
+
+// newExecutorFailure describes the failure of the given failed executor pod.
+func newExecutorFailure(pod *apiv1.Pod) v1beta1.ExecutorFailure {
+	exitCode, reason, finishedAt := getExecutorTermination(pod)
+	failure := v1beta1.ExecutorFailure{PodName: pod.Name, Time: finishedAt, ExitCode: exitCode, Reason: reason}
+	if failure.Time.IsZero() {
+		failure.Time = metav1.Now()
+	}
+	return failure
+}
+
+// newExecutorInfo describes the given executor pod in the given state.
+func newExecutorInfo(pod *apiv1.Pod, state v1beta1.ExecutorState) v1beta1.ExecutorInfo {
+	info := v1beta1.ExecutorInfo{
+		ExecutorID: pod.Labels[sparkExecutorIDLabel],
+		State:      state,
+		NodeName:   pod.Spec.NodeName,
+		PodIP:      pod.Status.PodIP,
+	}
+	if pod.Status.StartTime != nil {
+		info.StartTime = *pod.Status.StartTime
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == config.SparkExecutorContainerName {
+			info.RestartCount = status.RestartCount
+		}
+	}
+	if isExecutorTerminated(state) {
+		info.ExitCode, info.TerminationReason, info.TerminationTime = getExecutorTermination(pod)
+	}
+	return info
+}
+
+// markExecutorInfoTerminated records that the executor with the given pod name, if it has details recorded,
+// terminated in the given state for the given reason because its pod disappeared.
+func markExecutorInfoTerminated(app *v1beta1.SparkApplication, name string, state v1beta1.ExecutorState, reason string) {
+	info, ok := app.Status.Executors[name]
+	if !ok {
+		return
+	}
+	info.State = state
+	info.TerminationReason = reason
+	info.TerminationTime = metav1.Now()
+	app.Status.Executors[name] = info
+}
+
+// maxExecutorInfos is the maximum number of executors the details of which are recorded in the status of an
+// application.
+const maxExecutorInfos = 200
+
+// pruneExecutorInfos drops the details of terminated executors once the application has more than max executors,
+// completed executors before failed ones and otherwise the ones that terminated the longest time ago first. The
+// dropped executors are counted in OmittedExecutors.
+func pruneExecutorInfos(app *v1beta1.SparkApplication, max int) {
+	excess := len(app.Status.Executors) - max
+	if excess <= 0 {
+		return
+	}
+
+	var terminated []string
+	for name, info := range app.Status.Executors {
+		if isExecutorTerminated(info.State) {
+			terminated = append(terminated, name)
+		}
+	}
+	sort.Slice(terminated, func(i, j int) bool {
+		a, b := app.Status.Executors[terminated[i]], app.Status.Executors[terminated[j]]
+		if a.State != b.State {
+			return a.State == v1beta1.ExecutorCompletedState
+		}
+		if !a.TerminationTime.Equal(&b.TerminationTime) {
+			return a.TerminationTime.Before(&b.TerminationTime)
+		}
+		return terminated[i] < terminated[j]
+	})
+
+	if excess > len(terminated) {
+		excess = len(terminated)
+	}
+	for _, name := range terminated[:excess] {
+		delete(app.Status.Executors, name)
+		app.Status.OmittedExecutors++
+	}
+}
+
+// pruneExecutorFailures drops the executor failures that no longer count against the executor failure budget of
+// the application, because they happened before its failure window or because it has no budget anymore. No more
+// failures than needed to exhaust the budget are kept.
+func pruneExecutorFailures(app *v1beta1.SparkApplication, now time.Time) {
+	maxFailures := app.Spec.Executor.MaxFailures
+	if maxFailures == nil {
+		app.Status.ExecutorFailures = nil
+		return
+	}
+	failures := app.Status.ExecutorFailures
+	if window := app.Spec.Executor.FailureWindowSeconds; window != nil {
+		start := now.Add(-time.Duration(*window) * time.Second)
+		var recent []v1beta1.ExecutorFailure
+		for _, failure := range failures {
+			if !failure.Time.Time.Before(start) {
+				recent = append(recent, failure)
+			}
+		}
+		failures = recent
+	}
+	if limit := int(*maxFailures) + 1; len(failures) > limit {
+		failures = failures[len(failures)-limit:]
+	}
+	app.Status.ExecutorFailures = failures
+}
+
+// describeExecutorFailures returns a human-readable list of the given executor failures.
+func describeExecutorFailures(failures []v1beta1.ExecutorFailure) string {
+	descriptions := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		var details []string
+		if failure.ExitCode != nil {
+			details = append(details, fmt.Sprintf("ExitCode: %d", *failure.ExitCode))
+		}
+		if failure.Reason != "" {
+			details = append(details, fmt.Sprintf("Reason: %s", failure.Reason))
+		}
+		if len(details) == 0 {
+			descriptions = append(descriptions, failure.PodName)
+		} else {
+			descriptions = append(descriptions, fmt.Sprintf("%s (%s)", failure.PodName, strings.Join(details, ", ")))
+		}
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// defaultMaxFailedExecutorDetails is the default number of the most recently failed executors the details of which
+// are kept in a compact executor status.
+const defaultMaxFailedExecutorDetails = 10
+
+func getMaxFailedExecutorDetails(compact *v1beta1.CompactExecutorStatus) int {
+	if compact.MaxFailedExecutorDetails != nil {
+		return int(*compact.MaxFailedExecutorDetails)
+	}
+	return defaultMaxFailedExecutorDetails
+}
+
+// compactExecutorStatus compacts the executor status of an application after its executor states were updated from
+// the given previous states and the states of the executor pods that still exist. Terminated executors are counted
+// when they are first seen terminated, and dropped from ExecutorState once their pods are gone in a later update,
+// so the transition is still seen by the metrics. Only the details of the most recently failed executors are kept.
+func compactExecutorStatus(
+	app *v1beta1.SparkApplication,
+	oldStates map[string]v1beta1.ExecutorState,
+	podStates map[string]v1beta1.ExecutorState) {
+	counts := v1beta1.ExecutorCounts{}
+	if app.Status.ExecutorCounts != nil {
+		counts.Completed = app.Status.ExecutorCounts.Completed
+		counts.Failed = app.Status.ExecutorCounts.Failed
+	} else {
+		// Count the terminated executors recorded before the executor status became compact.
+		oldStates = nil
+	}
+	for name, state := range app.Status.ExecutorState {
+		oldState, existed := oldStates[name]
+		counted := existed && isExecutorTerminated(oldState)
+		switch state {
+		case v1beta1.ExecutorPendingState:
+			counts.Pending++
+		case v1beta1.ExecutorRunningState:
+			counts.Running++
+		case v1beta1.ExecutorCompletedState:
+			if !counted {
+				counts.Completed++
+			}
+		case v1beta1.ExecutorFailedState:
+			if !counted {
+				counts.Failed++
+			}
+		default:
+			counts.Unknown++
+		}
+		if _, exists := podStates[name]; counted && !exists {
+			delete(app.Status.ExecutorState, name)
+		}
+	}
+	app.Status.ExecutorCounts = &counts
+
+	var failed []string
+	for name, info := range app.Status.Executors {
+		if info.State == v1beta1.ExecutorFailedState {
+			failed = append(failed, name)
+		} else {
+			delete(app.Status.Executors, name)
+		}
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		a, b := app.Status.Executors[failed[i]], app.Status.Executors[failed[j]]
+		if !a.TerminationTime.Equal(&b.TerminationTime) {
+			return b.TerminationTime.Before(&a.TerminationTime)
+		}
+		return failed[i] < failed[j]
+	})
+	if max := getMaxFailedExecutorDetails(app.Spec.CompactExecutorStatus); len(failed) > max {
+		for _, name := range failed[max:] {
+			delete(app.Status.Executors, name)
+		}
+	}
+}
@@ -17,12 +17,14 @@ limitations under the License.
 package sparkapplication
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,6 +39,82 @@ const (
 	kubernetesServicePortEnvVar = "KUBERNETES_SERVICE_PORT"
 )
 
+// Submitter submits a SparkApplication to run by creating its driver.
+type Submitter interface {
+	// Submit submits the given SparkApplication with the given driver pod name and submission ID. It returns an
+	// AlreadySubmittedError if the driver pod already exists, and an InvalidSpecError if the application spec
+	// cannot be turned into a valid submission.
+	Submit(app *v1beta1.SparkApplication, driverPodName string, submissionID string) error
+}
+
+// AlreadySubmittedError is returned by a Submitter if the driver pod of the application already exists.
+type AlreadySubmittedError struct {
+	namespace string
+	name      string
+}
+
+func (e *AlreadySubmittedError) Error() string {
+	return fmt.Sprintf("SparkApplication %s/%s has already been submitted", e.namespace, e.name)
+}
+
+// InvalidSpecError is returned by a Submitter if the spec of the application cannot be submitted.
+type InvalidSpecError struct {
+	namespace string
+	name      string
+	reason    string
+}
+
+func (e *InvalidSpecError) Error() string {
+	return fmt.Sprintf("invalid spec of SparkApplication %s/%s: %s", e.namespace, e.name, e.reason)
+}
+
+// IsAlreadySubmitted returns true if the given error is an AlreadySubmittedError.
+func IsAlreadySubmitted(err error) bool {
+	_, ok := err.(*AlreadySubmittedError)
+	return ok
+}
+
+// IsInvalidSpec returns true if the given error is an InvalidSpecError.
+func IsInvalidSpec(err error) bool {
+	_, ok := err.(*InvalidSpecError)
+	return ok
+}
+
+func newAlreadySubmittedError(app *v1beta1.SparkApplication) error {
+	return &AlreadySubmittedError{namespace: app.Namespace, name: app.Name}
+}
+
+func newInvalidSpecError(app *v1beta1.SparkApplication, reason string) error {
+	return &InvalidSpecError{namespace: app.Namespace, name: app.Name, reason: reason}
+}
+
+// sparkSubmitter is a Submitter that forks the spark-submit script.
+type sparkSubmitter struct {
+	// timeout is the period of time spark-submit may run for before it is killed. No timeout applies if zero.
+	timeout time.Duration
+}
+
+// NewSparkSubmitter creates a Submitter that submits applications by running $SPARK_HOME/bin/spark-submit, killing
+// it if it does not finish within the given timeout if positive.
+func NewSparkSubmitter(timeout time.Duration) Submitter {
+	return &sparkSubmitter{timeout: timeout}
+}
+
+func (s *sparkSubmitter) Submit(app *v1beta1.SparkApplication, driverPodName string, submissionID string) error {
+	submissionCmdArgs, err := buildSubmissionCommandArgs(app, driverPodName, submissionID)
+	if err != nil {
+		return newInvalidSpecError(app, err.Error())
+	}
+	submitted, err := runSparkSubmit(newSubmission(submissionCmdArgs, app), s.timeout)
+	if err != nil {
+		return err
+	}
+	if !submitted {
+		return newAlreadySubmittedError(app)
+	}
+	return nil
+}
+
 // submission includes information of a Spark application to be submitted.
 type submission struct {
 	namespace string
@@ -52,7 +130,7 @@ func newSubmission(args []string, app *v1beta1.SparkApplication) *submission {
 	}
 }
 
-func runSparkSubmit(submission *submission) (bool, error) {
+func runSparkSubmit(submission *submission, timeout time.Duration) (bool, error) {
 	sparkHome, present := os.LookupEnv(sparkHomeEnvVar)
 	if !present {
 		glog.Error("SPARK_HOME is not specified")
@@ -61,12 +139,19 @@ func runSparkSubmit(submission *submission) (bool, error) {
 
 	cmd := execCommand(command, submission.args...)
 	glog.V(2).Infof("spark-submit arguments: %v", cmd.Args)
-	output, err := cmd.Output()
-	glog.V(3).Infof("spark-submit output: %s", string(output))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	timedOut, err := runCommand(cmd, timeout)
+	glog.V(3).Infof("spark-submit output: %s", stdout.String())
+	if timedOut {
+		return false, fmt.Errorf("spark-submit for SparkApplication %s/%s did not finish within %v and was killed",
+			submission.namespace, submission.name, timeout)
+	}
 	if err != nil {
 		var errorMsg string
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			errorMsg = string(exitErr.Stderr)
+		if _, ok := err.(*exec.ExitError); ok {
+			errorMsg = stderr.String()
 		}
 		// The driver pod of the application already exists.
 		if strings.Contains(errorMsg, podAlreadyExistsErrorCode) {
@@ -82,6 +167,34 @@ func runSparkSubmit(submission *submission) (bool, error) {
 	return true, nil
 }
 
+// runCommand runs the given command and waits for it to finish. If the timeout is positive and the command does not
+// finish within it, the command is killed and reported as timed out.
+func runCommand(cmd *exec.Cmd, timeout time.Duration) (bool, error) {
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+	if timeout <= 0 {
+		return false, cmd.Wait()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return false, err
+	case <-timer.C:
+		if err := cmd.Process.Kill(); err != nil {
+			glog.Errorf("failed to kill process %d: %v", cmd.Process.Pid, err)
+		}
+		<-done
+		return true, nil
+	}
+}
+
 func buildSubmissionCommandArgs(app *v1beta1.SparkApplication, driverPodName string, submissionID string) ([]string, error) {
 	var args []string
 	if app.Spec.MainClass != nil {
@@ -315,6 +428,10 @@ func addExecutorConfOptions(app *v1beta1.SparkApplication, submissionID string)
 		executorConfOptions = append(executorConfOptions, conf)
 	}
 
+	if app.DynamicAllocationEnabled() {
+		executorConfOptions = append(executorConfOptions, addDynamicAllocationConfOptions(app.Spec.DynamicAllocation)...)
+	}
+
 	if app.Spec.Executor.Image != nil {
 		executorConfOptions = append(executorConfOptions,
 			fmt.Sprintf("%s=%s", config.SparkExecutorContainerImageKey, *app.Spec.Executor.Image))
@@ -367,3 +484,30 @@ func addExecutorConfOptions(app *v1beta1.SparkApplication, submissionID string)
 
 	return executorConfOptions, nil
 }
+
+func addDynamicAllocationConfOptions(dynamicAllocation *v1beta1.DynamicAllocation) []string {
+	confOptions := []string{fmt.Sprintf("%s=true", config.SparkDynamicAllocationEnabled)}
+
+	// Shuffle tracking is enabled by default so dynamic allocation works without an external shuffle service.
+	shuffleTrackingEnabled := true
+	if dynamicAllocation.ShuffleTrackingEnabled != nil {
+		shuffleTrackingEnabled = *dynamicAllocation.ShuffleTrackingEnabled
+	}
+	confOptions = append(confOptions,
+		fmt.Sprintf("%s=%t", config.SparkDynamicAllocationShuffleTrackingEnabled, shuffleTrackingEnabled))
+
+	if dynamicAllocation.InitialExecutors != nil {
+		confOptions = append(confOptions,
+			fmt.Sprintf("%s=%d", config.SparkDynamicAllocationInitialExecutors, *dynamicAllocation.InitialExecutors))
+	}
+	if dynamicAllocation.MinExecutors != nil {
+		confOptions = append(confOptions,
+			fmt.Sprintf("%s=%d", config.SparkDynamicAllocationMinExecutors, *dynamicAllocation.MinExecutors))
+	}
+	if dynamicAllocation.MaxExecutors != nil {
+		confOptions = append(confOptions,
+			fmt.Sprintf("%s=%d", config.SparkDynamicAllocationMaxExecutors, *dynamicAllocation.MaxExecutors))
+	}
+
+	return confOptions
+}
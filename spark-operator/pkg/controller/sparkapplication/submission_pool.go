@@ -0,0 +1,183 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// submissionRequest is a submission of a SparkApplication waiting to be run or being run by a submissionPool.
+type submissionRequest struct {
+	app           *v1beta1.SparkApplication
+	driverPodName string
+	submissionID  string
+	// running tells if a worker has started running the submission, which then cannot be cancelled anymore.
+	running bool
+}
+
+// submissionResult is the result of a submission run by a submissionPool.
+type submissionResult struct {
+	// uid, state and submissionAttempts identify the application and the state it was submitted from, so results
+	// of submissions that were overtaken by changes to the application can be told apart.
+	uid                types.UID
+	state              v1beta1.ApplicationStateType
+	submissionAttempts int32
+	driverPodName      string
+	submissionID       string
+	err                error
+}
+
+// isFor tells if the result is the one of the submission of the given application in its current state.
+func (r *submissionResult) isFor(app *v1beta1.SparkApplication) bool {
+	return r.uid == app.UID && r.state == app.Status.AppState.State &&
+		r.submissionAttempts == app.Status.SubmissionAttempts
+}
+
+// submissionPool runs submissions of SparkApplications on a bounded number of workers of its own, so slow
+// submissions do not hold up the workers of the controller. The result of a submission is reported back by
+// enqueueing the key of the application, for the controller to pick it up the next time it syncs the application.
+type submissionPool struct {
+	submitter Submitter
+	queue     workqueue.Interface
+	enqueue   func(key interface{})
+
+	mutex sync.Mutex
+	// requests are the submissions waiting to be run or being run, by application keys.
+	requests map[string]*submissionRequest
+	// results are the results of the submissions the controller has not picked up yet, by application keys.
+	results map[string]*submissionResult
+}
+
+func newSubmissionPool(submitter Submitter, enqueue func(key interface{})) *submissionPool {
+	return &submissionPool{
+		submitter: submitter,
+		queue:     workqueue.NewNamed("spark-submission"),
+		enqueue:   enqueue,
+		requests:  make(map[string]*submissionRequest),
+		results:   make(map[string]*submissionResult),
+	}
+}
+
+func (p *submissionPool) start(workers int, stopCh <-chan struct{}) {
+	glog.Infof("Starting %d submission workers", workers)
+	for i := 0; i < workers; i++ {
+		go wait.Until(p.runWorker, time.Second, stopCh)
+	}
+}
+
+func (p *submissionPool) stop() {
+	p.queue.ShutDown()
+}
+
+// dispatch queues the given submission of the application with the given key. The submission is ignored if one of
+// the application is already queued or running, or has a result the controller has not picked up yet.
+func (p *submissionPool) dispatch(key string, request *submissionRequest) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.requests[key]; ok {
+		return
+	}
+	if _, ok := p.results[key]; ok {
+		return
+	}
+	p.requests[key] = request
+	p.queue.Add(key)
+}
+
+// takeResult returns the result of the submission of the application with the given key if it has finished, which is
+// then forgotten. It also tells if a submission of the application is still queued or running.
+func (p *submissionPool) takeResult(key string) (*submissionResult, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.requests[key]; ok {
+		return nil, true
+	}
+	result := p.results[key]
+	delete(p.results, key)
+	return result, false
+}
+
+// cancel drops the submission of the application with the given key if it is still queued, along with the result of
+// its submission the controller has not picked up yet, which is returned if there is one. It also tells if a
+// submission of the application is running, whose result is reported back once it finishes.
+func (p *submissionPool) cancel(key string) (*submissionResult, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if request, ok := p.requests[key]; ok {
+		if request.running {
+			return nil, true
+		}
+		delete(p.requests, key)
+	}
+	result := p.results[key]
+	delete(p.results, key)
+	return result, false
+}
+
+func (p *submissionPool) runWorker() {
+	defer utilruntime.HandleCrash()
+	for p.processNextItem() {
+	}
+}
+
+func (p *submissionPool) processNextItem() bool {
+	key, quit := p.queue.Get()
+	if quit {
+		return false
+	}
+	defer p.queue.Done(key)
+
+	p.mutex.Lock()
+	request, ok := p.requests[key.(string)]
+	if ok {
+		request.running = true
+	}
+	p.mutex.Unlock()
+	if !ok {
+		// The submission was cancelled before it started running.
+		return true
+	}
+
+	app := request.app
+	glog.V(2).Infof("Submitting SparkApplication %s/%s", app.Namespace, app.Name)
+	err := p.submitter.Submit(app, request.driverPodName, request.submissionID)
+
+	p.mutex.Lock()
+	delete(p.requests, key.(string))
+	p.results[key.(string)] = &submissionResult{
+		uid:                app.UID,
+		state:              app.Status.AppState.State,
+		submissionAttempts: app.Status.SubmissionAttempts,
+		driverPodName:      request.driverPodName,
+		submissionID:       request.submissionID,
+		err:                err,
+	}
+	p.mutex.Unlock()
+
+	p.enqueue(key)
+	return true
+}
@@ -15,3 +15,95 @@ limitations under the License.
 */
 
 package sparkapplication
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestAddExecutorConfOptions_DynamicAllocation(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Executor: v1beta1.ExecutorSpec{Instances: int32ptr(2)},
+			DynamicAllocation: &v1beta1.DynamicAllocation{
+				Enabled:          false,
+				InitialExecutors: int32ptr(1),
+				MinExecutors:     int32ptr(1),
+				MaxExecutors:     int32ptr(10),
+			},
+		},
+	}
+
+	// Nothing is added if dynamic allocation is disabled.
+	options, err := addExecutorConfOptions(app, "submission-id")
+	assert.Nil(t, err)
+	assert.NotContains(t, options, fmt.Sprintf("%s=true", config.SparkDynamicAllocationEnabled))
+	assert.NotContains(t, options, fmt.Sprintf("%s=10", config.SparkDynamicAllocationMaxExecutors))
+
+	app.Spec.DynamicAllocation.Enabled = true
+	options, err = addExecutorConfOptions(app, "submission-id")
+	assert.Nil(t, err)
+	assert.Contains(t, options, "spark.executor.instances=2")
+	assert.Contains(t, options, fmt.Sprintf("%s=true", config.SparkDynamicAllocationEnabled))
+	assert.Contains(t, options, fmt.Sprintf("%s=true", config.SparkDynamicAllocationShuffleTrackingEnabled))
+	assert.Contains(t, options, fmt.Sprintf("%s=1", config.SparkDynamicAllocationInitialExecutors))
+	assert.Contains(t, options, fmt.Sprintf("%s=1", config.SparkDynamicAllocationMinExecutors))
+	assert.Contains(t, options, fmt.Sprintf("%s=10", config.SparkDynamicAllocationMaxExecutors))
+
+	shuffleTrackingEnabled := false
+	app.Spec.DynamicAllocation.ShuffleTrackingEnabled = &shuffleTrackingEnabled
+	options, err = addExecutorConfOptions(app, "submission-id")
+	assert.Nil(t, err)
+	assert.Contains(t, options, fmt.Sprintf("%s=false", config.SparkDynamicAllocationShuffleTrackingEnabled))
+}
+
+func TestHelperProcessHang(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+func TestRunSparkSubmit_Timeout(t *testing.T) {
+	os.Setenv(sparkHomeEnvVar, "/spark")
+	defer func() { execCommand = exec.Command }()
+	execCommand = func(command string, args ...string) *exec.Cmd {
+		cs := []string{"-test.run=TestHelperProcessHang", "--", command}
+		cs = append(cs, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		return cmd
+	}
+
+	submission := &submission{namespace: "default", name: "foo"}
+	start := time.Now()
+	submitted, err := runSparkSubmit(submission, 200*time.Millisecond)
+	assert.False(t, submitted)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "was killed")
+	// The hanging spark-submit is killed instead of being waited for.
+	assert.True(t, time.Since(start) < 30*time.Second)
+
+	// Without a timeout, spark-submit is waited for until it finishes.
+	execCommand = func(command string, args ...string) *exec.Cmd {
+		cs := []string{"-test.run=TestHelperProcessSuccess", "--", command}
+		cs = append(cs, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		return cmd
+	}
+	submitted, err = runSparkSubmit(submission, 0)
+	assert.True(t, submitted)
+	assert.Nil(t, err)
+}
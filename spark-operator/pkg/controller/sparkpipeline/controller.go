@@ -0,0 +1,330 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkpipeline
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+var (
+	keyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
+)
+
+// Controller runs the steps of SparkPipelines as SparkApplications, each once the steps it depends on have
+// completed.
+type Controller struct {
+	crdClient      crdclientset.Interface
+	queue          workqueue.RateLimitingInterface
+	cacheSynced    cache.InformerSynced
+	pipelineLister crdlisters.SparkPipelineLister
+	saLister       crdlisters.SparkApplicationLister
+	clock          clock.Clock
+}
+
+// NewController creates a new Controller.
+func NewController(
+	crdClient crdclientset.Interface,
+	informerFactory crdinformers.SharedInformerFactory,
+	clock clock.Clock) *Controller {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(),
+		"spark-pipeline-controller")
+
+	controller := &Controller{
+		crdClient: crdClient,
+		queue:     queue,
+		clock:     clock,
+	}
+
+	pipelineInformer := informerFactory.Sparkoperator().V1beta1().SparkPipelines()
+	pipelineInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.onPipelineAdd,
+		UpdateFunc: controller.onPipelineUpdate,
+	})
+	controller.pipelineLister = pipelineInformer.Lister()
+
+	// The states of the steps are derived from the states of their SparkApplications, so a change to any of
+	// the SparkApplications of a pipeline triggers a sync of the pipeline.
+	saInformer := informerFactory.Sparkoperator().V1beta1().SparkApplications()
+	saInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.onApplicationChange,
+		UpdateFunc: func(oldObj, newObj interface{}) { controller.onApplicationChange(newObj) },
+		DeleteFunc: controller.onApplicationChange,
+	})
+	controller.saLister = saInformer.Lister()
+
+	controller.cacheSynced = func() bool {
+		return pipelineInformer.Informer().HasSynced() && saInformer.Informer().HasSynced()
+	}
+
+	return controller
+}
+
+// Start starts the workers of the Controller.
+func (c *Controller) Start(workers int, stopCh <-chan struct{}) error {
+	glog.Info("Starting the SparkPipeline controller")
+
+	if !cache.WaitForCacheSync(stopCh, c.cacheSynced) {
+		return fmt.Errorf("timed out waiting for cache to sync")
+	}
+
+	glog.Info("Starting the workers of the SparkPipeline controller")
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	return nil
+}
+
+// Stop stops the Controller.
+func (c *Controller) Stop() {
+	glog.Info("Stopping the SparkPipeline controller")
+	c.queue.ShutDown()
+}
+
+func (c *Controller) onPipelineAdd(obj interface{}) {
+	c.enqueue(obj)
+}
+
+func (c *Controller) onPipelineUpdate(oldObj, newObj interface{}) {
+	c.enqueue(newObj)
+}
+
+func (c *Controller) onApplicationChange(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	app, ok := obj.(*v1beta1.SparkApplication)
+	if !ok {
+		return
+	}
+	pipelineName, ok := app.Labels[config.SparkPipelineNameLabel]
+	if !ok {
+		return
+	}
+	c.queue.Add(app.Namespace + "/" + pipelineName)
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := keyFunc(obj)
+	if err != nil {
+		glog.Errorf("failed to get key for %v: %v", obj, err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *Controller) runWorker() {
+	defer utilruntime.HandleCrash()
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncSparkPipeline(key.(string))
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+
+	utilruntime.HandleError(fmt.Errorf("failed to sync SparkPipeline %q: %v", key, err))
+	c.queue.AddRateLimited(key)
+	return true
+}
+
+func (c *Controller) syncSparkPipeline(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	pipeline, err := c.pipelineLister.SparkPipelines(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !pipeline.DeletionTimestamp.IsZero() || isPipelineFinished(pipeline.Status.State) {
+		return nil
+	}
+
+	glog.V(2).Infof("Syncing SparkPipeline %s/%s", pipeline.Namespace, pipeline.Name)
+	status := pipeline.Status.DeepCopy()
+	if status.StartTime.IsZero() {
+		status.StartTime = metav1.NewTime(c.clock.Now())
+	}
+
+	sortedSteps, err := sortSteps(pipeline.Spec.Steps)
+	if err != nil {
+		glog.Errorf("invalid steps of SparkPipeline %s/%s: %v", pipeline.Namespace, pipeline.Name, err)
+		status.State = v1beta1.PipelineFailedValidationState
+		status.Reason = err.Error()
+		status.CompletionTime = metav1.NewTime(c.clock.Now())
+		return c.updateSparkPipelineStatus(pipeline, status)
+	}
+
+	apps, err := c.listStepApplications(pipeline)
+	if err != nil {
+		return err
+	}
+	status.StepStatuses = computeStepStatuses(sortedSteps, apps, status.StepStatuses, pipeline.Spec.FailurePolicy)
+
+	for _, step := range sortedSteps {
+		if !isStepReady(step, status.StepStatuses) {
+			continue
+		}
+		app, err := c.createStepApplication(pipeline, step)
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(app, pipeline) {
+			// The step would never finish, as SparkApplications not controlled by the pipeline are ignored.
+			reason := fmt.Sprintf("SparkApplication %s exists already and is not controlled by the pipeline", app.Name)
+			glog.Errorf("step %s of SparkPipeline %s/%s failed: %s", step.Name, pipeline.Namespace, pipeline.Name,
+				reason)
+			status.StepStatuses[step.Name] = v1beta1.PipelineStepStatus{
+				State:  v1beta1.PipelineStepFailedState,
+				Reason: reason,
+			}
+			continue
+		}
+		status.StepStatuses[step.Name] = v1beta1.PipelineStepStatus{
+			State:           v1beta1.PipelineStepRunningState,
+			ApplicationName: app.Name,
+		}
+	}
+
+	status.State, status.Reason = aggregateStepStates(status.StepStatuses)
+	if isPipelineFinished(status.State) {
+		status.CompletionTime = metav1.NewTime(c.clock.Now())
+	}
+
+	return c.updateSparkPipelineStatus(pipeline, status)
+}
+
+// listStepApplications returns the SparkApplications of the pipeline keyed by the names of their steps.
+func (c *Controller) listStepApplications(pipeline *v1beta1.SparkPipeline) (map[string]*v1beta1.SparkApplication, error) {
+	set := labels.Set{config.SparkPipelineNameLabel: pipeline.Name}
+	apps, err := c.saLister.SparkApplications(pipeline.Namespace).List(set.AsSelector())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SparkApplications: %v", err)
+	}
+	appsByStep := make(map[string]*v1beta1.SparkApplication)
+	for _, app := range apps {
+		if !metav1.IsControlledBy(app, pipeline) {
+			continue
+		}
+		appsByStep[app.Labels[config.SparkPipelineStepLabel]] = app
+	}
+	return appsByStep, nil
+}
+
+// createStepApplication creates the SparkApplication of a step of the pipeline, or gets it if it exists already. The
+// SparkApplication that exists already may not be controlled by the pipeline.
+func (c *Controller) createStepApplication(
+	pipeline *v1beta1.SparkPipeline,
+	step v1beta1.PipelineStep) (*v1beta1.SparkApplication, error) {
+	app := &v1beta1.SparkApplication{}
+	app.Spec = *step.Template.DeepCopy()
+	app.Name = getStepApplicationName(pipeline, step.Name)
+	app.Namespace = pipeline.Namespace
+	controller := true
+	app.OwnerReferences = append(app.OwnerReferences, metav1.OwnerReference{
+		APIVersion: v1beta1.SchemeGroupVersion.String(),
+		Kind:       reflect.TypeOf(v1beta1.SparkPipeline{}).Name(),
+		Name:       pipeline.Name,
+		UID:        pipeline.UID,
+		Controller: &controller,
+	})
+	app.Labels = make(map[string]string)
+	for key, value := range pipeline.Labels {
+		app.Labels[key] = value
+	}
+	// The SparkApplications of a pipeline started by a ScheduledSparkApplication are not runs of the
+	// ScheduledSparkApplication themselves.
+	delete(app.Labels, config.ScheduledSparkAppNameLabel)
+	app.Labels[config.SparkPipelineNameLabel] = pipeline.Name
+	app.Labels[config.SparkPipelineStepLabel] = step.Name
+
+	glog.Infof("Creating SparkApplication %s/%s for step %s of SparkPipeline %s", app.Namespace, app.Name, step.Name,
+		pipeline.Name)
+	created, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+	if errors.IsAlreadyExists(err) {
+		// The SparkApplication may have been created by a previous sync the cache has not caught up with yet.
+		existing, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name,
+			metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get SparkApplication for step %s: %v", step.Name, err)
+		}
+		return existing, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SparkApplication for step %s: %v", step.Name, err)
+	}
+	return created, nil
+}
+
+func (c *Controller) updateSparkPipelineStatus(
+	pipeline *v1beta1.SparkPipeline,
+	newStatus *v1beta1.SparkPipelineStatus) error {
+	// If the status has not changed, do not perform an update.
+	if reflect.DeepEqual(*newStatus, pipeline.Status) {
+		return nil
+	}
+
+	toUpdate := pipeline.DeepCopy()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		toUpdate.Status = *newStatus
+		_, updateErr := c.crdClient.SparkoperatorV1beta1().SparkPipelines(toUpdate.Namespace).UpdateStatus(toUpdate)
+		if updateErr == nil {
+			return nil
+		}
+
+		result, err := c.crdClient.SparkoperatorV1beta1().SparkPipelines(toUpdate.Namespace).Get(
+			toUpdate.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		toUpdate = result
+
+		return updateErr
+	})
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkpipeline
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestSortSteps(t *testing.T) {
+	type testcase struct {
+		name          string
+		steps         []v1beta1.PipelineStep
+		expectedOrder []string
+		expectError   bool
+	}
+
+	testcases := []testcase{
+		{
+			name: "fan-out and fan-in",
+			steps: []v1beta1.PipelineStep{
+				{Name: "join", DependsOn: []string{"left", "right"}},
+				{Name: "left", DependsOn: []string{"extract"}},
+				{Name: "right", DependsOn: []string{"extract"}},
+				{Name: "extract"},
+			},
+			expectedOrder: []string{"extract", "left", "right", "join"},
+		},
+		{
+			name:        "no steps",
+			expectError: true,
+		},
+		{
+			name:        "duplicated step",
+			steps:       []v1beta1.PipelineStep{{Name: "a"}, {Name: "a"}},
+			expectError: true,
+		},
+		{
+			name:        "unknown dependency",
+			steps:       []v1beta1.PipelineStep{{Name: "a", DependsOn: []string{"b"}}},
+			expectError: true,
+		},
+		{
+			name: "cycle",
+			steps: []v1beta1.PipelineStep{
+				{Name: "a"},
+				{Name: "b", DependsOn: []string{"a", "c"}},
+				{Name: "c", DependsOn: []string{"b"}},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range testcases {
+		sorted, err := sortSteps(test.steps)
+		assert.Equal(t, test.expectError, err != nil, test.name)
+		var order []string
+		for _, step := range sorted {
+			order = append(order, step.Name)
+		}
+		assert.Equal(t, test.expectedOrder, order, test.name)
+	}
+}
+
+func TestSyncSparkPipeline(t *testing.T) {
+	pipeline := newPipeline(v1beta1.PipelineStopOnFailure)
+	c := newFakeController(pipeline)
+	key, _ := cache.MetaNamespaceKeyFunc(pipeline)
+
+	// Only the step without dependencies starts.
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineRunningState, pipeline.Status.State)
+	assert.False(t, pipeline.Status.StartTime.IsZero())
+	assert.Equal(t, v1beta1.PipelineStepRunningState, pipeline.Status.StepStatuses["extract"].State)
+	assert.Equal(t, getStepApplicationName(pipeline, "extract"), pipeline.Status.StepStatuses["extract"].ApplicationName)
+	assert.Equal(t, v1beta1.PipelineStepPendingState, pipeline.Status.StepStatuses["left"].State)
+	assert.Equal(t, v1beta1.PipelineStepPendingState, pipeline.Status.StepStatuses["join"].State)
+
+	extractName := getStepApplicationName(pipeline, "extract")
+	app, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(pipeline.Namespace).Get(extractName,
+		metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "etl", app.Labels[config.SparkPipelineNameLabel])
+	assert.Equal(t, "extract", app.Labels[config.SparkPipelineStepLabel])
+	assert.True(t, metav1.IsControlledBy(app, pipeline))
+	assert.Equal(t, "extract.py", *app.Spec.MainApplicationFile)
+
+	// Completing the first step fans out to the two steps depending on it.
+	setApplicationState(t, c, getStepApplicationName(pipeline, "extract"), v1beta1.CompletedState)
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineStepCompletedState, pipeline.Status.StepStatuses["extract"].State)
+	assert.Equal(t, v1beta1.PipelineStepRunningState, pipeline.Status.StepStatuses["left"].State)
+	assert.Equal(t, v1beta1.PipelineStepRunningState, pipeline.Status.StepStatuses["right"].State)
+	assert.Equal(t, v1beta1.PipelineStepPendingState, pipeline.Status.StepStatuses["join"].State)
+
+	// The last step waits for both of the steps it depends on.
+	setApplicationState(t, c, getStepApplicationName(pipeline, "left"), v1beta1.CompletedState)
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineStepPendingState, pipeline.Status.StepStatuses["join"].State)
+	setApplicationState(t, c, getStepApplicationName(pipeline, "right"), v1beta1.CompletedState)
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineStepRunningState, pipeline.Status.StepStatuses["join"].State)
+
+	setApplicationState(t, c, getStepApplicationName(pipeline, "join"), v1beta1.CompletedState)
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineCompletedState, pipeline.Status.State)
+	assert.False(t, pipeline.Status.CompletionTime.IsZero())
+}
+
+func TestSyncSparkPipeline_StopOnFailure(t *testing.T) {
+	pipeline := newPipeline(v1beta1.PipelineStopOnFailure)
+	c := newFakeController(pipeline)
+	key, _ := cache.MetaNamespaceKeyFunc(pipeline)
+
+	syncAndGet(t, c, key)
+	setApplicationState(t, c, getStepApplicationName(pipeline, "extract"), v1beta1.CompletedState)
+	syncAndGet(t, c, key)
+	setApplicationState(t, c, getStepApplicationName(pipeline, "left"), v1beta1.FailedState)
+
+	// The running step is left alone, but no other step starts.
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineRunningState, pipeline.Status.State)
+	assert.Equal(t, v1beta1.PipelineStepFailedState, pipeline.Status.StepStatuses["left"].State)
+	assert.Equal(t, v1beta1.PipelineStepRunningState, pipeline.Status.StepStatuses["right"].State)
+	assert.Equal(t, v1beta1.PipelineStepSkippedState, pipeline.Status.StepStatuses["join"].State)
+	assert.Equal(t, v1beta1.PipelineStepSkippedState, pipeline.Status.StepStatuses["report"].State)
+
+	setApplicationState(t, c, getStepApplicationName(pipeline, "right"), v1beta1.CompletedState)
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineFailedState, pipeline.Status.State)
+	assert.Equal(t, "steps failed: left", pipeline.Status.Reason)
+}
+
+func TestSyncSparkPipeline_ContinueOnFailure(t *testing.T) {
+	pipeline := newPipeline(v1beta1.PipelineContinueOnFailure)
+	c := newFakeController(pipeline)
+	key, _ := cache.MetaNamespaceKeyFunc(pipeline)
+
+	syncAndGet(t, c, key)
+	setApplicationState(t, c, getStepApplicationName(pipeline, "extract"), v1beta1.CompletedState)
+	syncAndGet(t, c, key)
+	setApplicationState(t, c, getStepApplicationName(pipeline, "left"), v1beta1.FailedState)
+	setApplicationState(t, c, getStepApplicationName(pipeline, "right"), v1beta1.CompletedState)
+
+	// Only the steps depending on the failed step, directly or not, are skipped.
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineRunningState, pipeline.Status.State)
+	assert.Equal(t, v1beta1.PipelineStepSkippedState, pipeline.Status.StepStatuses["join"].State)
+	assert.Equal(t, v1beta1.PipelineStepRunningState, pipeline.Status.StepStatuses["report"].State)
+
+	setApplicationState(t, c, getStepApplicationName(pipeline, "report"), v1beta1.CompletedState)
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineFailedState, pipeline.Status.State)
+}
+
+func TestSyncSparkPipeline_DeletedStepApplication(t *testing.T) {
+	pipeline := newPipeline(v1beta1.PipelineStopOnFailure)
+	c := newFakeController(pipeline)
+	key, _ := cache.MetaNamespaceKeyFunc(pipeline)
+
+	syncAndGet(t, c, key)
+	setApplicationState(t, c, getStepApplicationName(pipeline, "extract"), v1beta1.CompletedState)
+	syncAndGet(t, c, key)
+
+	// A finished step is not run again once its SparkApplication is deleted, e.g., after its time to live.
+	extractName := getStepApplicationName(pipeline, "extract")
+	app, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(pipeline.Namespace).Get(extractName,
+		metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, c.crdClient.SparkoperatorV1beta1().SparkApplications(pipeline.Namespace).Delete(app.Name, nil))
+	c.saInformer.GetIndexer().Delete(app)
+
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineStepCompletedState, pipeline.Status.StepStatuses["extract"].State)
+	assert.Equal(t, getStepApplicationName(pipeline, "extract"), pipeline.Status.StepStatuses["extract"].ApplicationName)
+	assert.Equal(t, v1beta1.PipelineStepRunningState, pipeline.Status.StepStatuses["left"].State)
+	_, err = c.crdClient.SparkoperatorV1beta1().SparkApplications(pipeline.Namespace).Get(extractName, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestSyncSparkPipeline_StepApplicationNotControlled(t *testing.T) {
+	pipeline := newPipeline(v1beta1.PipelineStopOnFailure)
+	c := newFakeController(pipeline)
+	key, _ := cache.MetaNamespaceKeyFunc(pipeline)
+
+	// A SparkApplication of the same name not controlled by the pipeline fails the step instead of being waited for.
+	other := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: getStepApplicationName(pipeline, "extract"), Namespace: pipeline.Namespace},
+	}
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(pipeline.Namespace).Create(other); err != nil {
+		t.Fatal(err)
+	}
+
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineStepFailedState, pipeline.Status.StepStatuses["extract"].State)
+	assert.Contains(t, pipeline.Status.StepStatuses["extract"].Reason, "not controlled by the pipeline")
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineFailedState, pipeline.Status.State)
+	assert.Equal(t, v1beta1.PipelineStepSkippedState, pipeline.Status.StepStatuses["join"].State)
+}
+
+func TestGetStepApplicationName(t *testing.T) {
+	first := &v1beta1.SparkPipeline{ObjectMeta: metav1.ObjectMeta{Name: "a-b", UID: "first-uid"}}
+	second := &v1beta1.SparkPipeline{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: "second-uid"}}
+	recreated := &v1beta1.SparkPipeline{ObjectMeta: metav1.ObjectMeta{Name: "a-b", UID: "recreated-uid"}}
+
+	name := getStepApplicationName(first, "c")
+	assert.True(t, strings.HasPrefix(name, "a-b-c-"))
+	assert.Equal(t, name, getStepApplicationName(first, "c"))
+	assert.NotEqual(t, name, getStepApplicationName(second, "b-c"))
+	assert.NotEqual(t, name, getStepApplicationName(recreated, "c"))
+}
+
+func TestSyncSparkPipeline_InvalidSteps(t *testing.T) {
+	pipeline := newPipeline(v1beta1.PipelineStopOnFailure)
+	pipeline.Spec.Steps[0].DependsOn = []string{"join"}
+	c := newFakeController(pipeline)
+	key, _ := cache.MetaNamespaceKeyFunc(pipeline)
+
+	pipeline = syncAndGet(t, c, key)
+	assert.Equal(t, v1beta1.PipelineFailedValidationState, pipeline.Status.State)
+	assert.NotEmpty(t, pipeline.Status.Reason)
+	apps, _ := c.crdClient.SparkoperatorV1beta1().SparkApplications(pipeline.Namespace).List(metav1.ListOptions{})
+	assert.Empty(t, apps.Items)
+}
+
+// newPipeline returns a pipeline where left and right depend on extract, join depends on left and right, and report
+// depends on right only.
+func newPipeline(policy v1beta1.PipelineFailurePolicy) *v1beta1.SparkPipeline {
+	newStep := func(name string, dependsOn ...string) v1beta1.PipelineStep {
+		file := name + ".py"
+		return v1beta1.PipelineStep{
+			Name:      name,
+			DependsOn: dependsOn,
+			Template:  v1beta1.SparkApplicationSpec{MainApplicationFile: &file},
+		}
+	}
+	return &v1beta1.SparkPipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "etl", Namespace: "default", UID: getStepApplicationName(pipeline, "uid")},
+		Spec: v1beta1.SparkPipelineSpec{
+			Steps: []v1beta1.PipelineStep{
+				newStep("extract"),
+				newStep("left", "extract"),
+				newStep("right", "extract"),
+				newStep("join", "left", "right"),
+				newStep("report", "right"),
+			},
+			FailurePolicy: policy,
+		},
+	}
+}
+
+// syncAndGet syncs the pipeline of the given key and returns it with the updated status, keeping the cache in sync
+// with the fake clientset.
+func syncAndGet(t *testing.T, c *fakeController, key string) *v1beta1.SparkPipeline {
+	assert.Nil(t, c.syncSparkPipeline(key))
+	namespace, name, _ := cache.SplitMetaNamespaceKey(key)
+	pipeline, err := c.crdClient.SparkoperatorV1beta1().SparkPipelines(namespace).Get(name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	c.pipelineInformer.GetIndexer().Update(pipeline)
+
+	apps, _ := c.crdClient.SparkoperatorV1beta1().SparkApplications(namespace).List(metav1.ListOptions{})
+	for i := range apps.Items {
+		c.saInformer.GetIndexer().Update(&apps.Items[i])
+	}
+	return pipeline
+}
+
+func setApplicationState(t *testing.T, c *fakeController, name string, state v1beta1.ApplicationStateType) {
+	app, err := c.crdClient.SparkoperatorV1beta1().SparkApplications("default").Get(name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	app.Status.AppState.State = state
+	app, err = c.crdClient.SparkoperatorV1beta1().SparkApplications("default").Update(app)
+	assert.Nil(t, err)
+	c.saInformer.GetIndexer().Update(app)
+}
+
+type fakeController struct {
+	*Controller
+	pipelineInformer cache.SharedIndexInformer
+	saInformer       cache.SharedIndexInformer
+}
+
+func newFakeController(pipeline *v1beta1.SparkPipeline) *fakeController {
+	crdClient := crdclientfake.NewSimpleClientset()
+	informerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0*time.Second)
+	controller := &fakeController{
+		Controller:       NewController(crdClient, informerFactory, clock.NewFakeClock(time.Now())),
+		pipelineInformer: informerFactory.Sparkoperator().V1beta1().SparkPipelines().Informer(),
+		saInformer:       informerFactory.Sparkoperator().V1beta1().SparkApplications().Informer(),
+	}
+
+	crdClient.SparkoperatorV1beta1().SparkPipelines(pipeline.Namespace).Create(pipeline)
+	controller.pipelineInformer.GetIndexer().Add(pipeline)
+	return controller
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkpipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// getStepApplicationName returns the name of the SparkApplication of a step of the pipeline. The name ends with a
+// hash of the UID of the pipeline, so it differs between pipelines the names of which and of their steps join into the
+// same string, e.g., pipeline a-b with step c and pipeline a with step b-c, and between a pipeline and the one it was
+// re-created as.
+func getStepApplicationName(pipeline *v1beta1.SparkPipeline, stepName string) string {
+	hasher := util.NewHash32()
+	hasher.Write([]byte(pipeline.UID))
+	return fmt.Sprintf("%s-%s-%08x", pipeline.Name, stepName, hasher.Sum32())
+}
+
+func isPipelineFinished(state v1beta1.PipelineState) bool {
+	return state == v1beta1.PipelineCompletedState ||
+		state == v1beta1.PipelineFailedState ||
+		state == v1beta1.PipelineFailedValidationState
+}
+
+func isStepFinished(state v1beta1.PipelineStepState) bool {
+	return state == v1beta1.PipelineStepCompletedState ||
+		state == v1beta1.PipelineStepFailedState ||
+		state == v1beta1.PipelineStepSkippedState
+}
+
+// sortSteps validates the steps and sorts them topologically, so that every step comes after the steps it depends
+// on. Steps are otherwise kept in the order they are declared in.
+func sortSteps(steps []v1beta1.PipelineStep) ([]v1beta1.PipelineStep, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("the pipeline has no steps")
+	}
+
+	names := make(map[string]bool)
+	for _, step := range steps {
+		if step.Name == "" {
+			return nil, fmt.Errorf("a step has no name")
+		}
+		if names[step.Name] {
+			return nil, fmt.Errorf("step %s is defined more than once", step.Name)
+		}
+		names[step.Name] = true
+	}
+	for _, step := range steps {
+		for _, dep := range step.DependsOn {
+			if !names[dep] {
+				return nil, fmt.Errorf("step %s depends on unknown step %s", step.Name, dep)
+			}
+		}
+	}
+
+	sorted := make([]v1beta1.PipelineStep, 0, len(steps))
+	added := make(map[string]bool)
+	for len(sorted) < len(steps) {
+		progressed := false
+		for _, step := range steps {
+			if added[step.Name] {
+				continue
+			}
+			ready := true
+			for _, dep := range step.DependsOn {
+				if !added[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				sorted = append(sorted, step)
+				added[step.Name] = true
+				progressed = true
+			}
+		}
+		if !progressed {
+			var cyclic []string
+			for _, step := range steps {
+				if !added[step.Name] {
+					cyclic = append(cyclic, step.Name)
+				}
+			}
+			return nil, fmt.Errorf("the dependencies of steps %s form a cycle", strings.Join(cyclic, ", "))
+		}
+	}
+	return sorted, nil
+}
+
+// computeStepStatuses computes the statuses of the topologically sorted steps from their SparkApplications, keyed
+// by the step names. Steps that finished according to the previous statuses keep their statuses, as their
+// SparkApplications may have been deleted since, e.g., after their time to live. Steps that have not started and will
+// never start because of failed steps are skipped.
+func computeStepStatuses(
+	sortedSteps []v1beta1.PipelineStep,
+	apps map[string]*v1beta1.SparkApplication,
+	previousStatuses map[string]v1beta1.PipelineStepStatus,
+	policy v1beta1.PipelineFailurePolicy) map[string]v1beta1.PipelineStepStatus {
+	statuses := make(map[string]v1beta1.PipelineStepStatus)
+	anyFailed := false
+	for _, step := range sortedSteps {
+		if previous, ok := previousStatuses[step.Name]; ok && isStepFinished(previous.State) {
+			statuses[step.Name] = previous
+			if previous.State == v1beta1.PipelineStepFailedState {
+				anyFailed = true
+			}
+			continue
+		}
+		app, ok := apps[step.Name]
+		if !ok {
+			statuses[step.Name] = v1beta1.PipelineStepStatus{State: v1beta1.PipelineStepPendingState}
+			continue
+		}
+		status := v1beta1.PipelineStepStatus{State: v1beta1.PipelineStepRunningState, ApplicationName: app.Name}
+		switch app.Status.AppState.State {
+		case v1beta1.CompletedState:
+			status.State = v1beta1.PipelineStepCompletedState
+		case v1beta1.FailedState:
+			status.State = v1beta1.PipelineStepFailedState
+			anyFailed = true
+		}
+		statuses[step.Name] = status
+	}
+
+	for _, step := range sortedSteps {
+		if statuses[step.Name].State != v1beta1.PipelineStepPendingState {
+			continue
+		}
+		skip := anyFailed && policy != v1beta1.PipelineContinueOnFailure
+		for _, dep := range step.DependsOn {
+			// Dependencies come first in the sorted steps, so they have been skipped already if needed.
+			if state := statuses[dep].State; state == v1beta1.PipelineStepFailedState ||
+				state == v1beta1.PipelineStepSkippedState {
+				skip = true
+			}
+		}
+		if skip {
+			statuses[step.Name] = v1beta1.PipelineStepStatus{State: v1beta1.PipelineStepSkippedState}
+		}
+	}
+	return statuses
+}
+
+// isStepReady tells if the step has not started yet and all the steps it depends on have completed.
+func isStepReady(step v1beta1.PipelineStep, statuses map[string]v1beta1.PipelineStepStatus) bool {
+	if statuses[step.Name].State != v1beta1.PipelineStepPendingState {
+		return false
+	}
+	for _, dep := range step.DependsOn {
+		if statuses[dep].State != v1beta1.PipelineStepCompletedState {
+			return false
+		}
+	}
+	return true
+}
+
+// aggregateStepStates returns the state of a pipeline with steps of the given statuses, and the reason of the state.
+func aggregateStepStates(statuses map[string]v1beta1.PipelineStepStatus) (v1beta1.PipelineState, string) {
+	var failed []string
+	for name, status := range statuses {
+		if !isStepFinished(status.State) {
+			return v1beta1.PipelineRunningState, ""
+		}
+		if status.State == v1beta1.PipelineStepFailedState {
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return v1beta1.PipelineFailedState, fmt.Sprintf("steps failed: %s", strings.Join(failed, ", "))
+	}
+	return v1beta1.PipelineCompletedState, ""
+}
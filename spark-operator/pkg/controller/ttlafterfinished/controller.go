@@ -0,0 +1,267 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ttlafterfinished
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+var (
+	keyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
+)
+
+// Controller deletes SparkApplications once the time to live after they finished has passed.
+type Controller struct {
+	crdClient   crdclientset.Interface
+	queue       workqueue.RateLimitingInterface
+	cacheSynced cache.InformerSynced
+	saLister    crdlisters.SparkApplicationLister
+	defaultTTL  *int64
+	clock       clock.Clock
+
+	metricLabels  []string
+	deletionCount *prometheus.CounterVec
+}
+
+// NewController creates a new Controller. The default TTL applies to SparkApplications not setting
+// spec.timeToLiveSecondsAfterFinished. SparkApplications without a TTL are never deleted.
+func NewController(
+	crdClient crdclientset.Interface,
+	informerFactory crdinformers.SharedInformerFactory,
+	metricsConfig *util.MetricConfig,
+	defaultTTL *int64,
+	clock clock.Clock) *Controller {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(),
+		"spark-application-ttl-controller")
+
+	controller := &Controller{
+		crdClient:  crdClient,
+		queue:      queue,
+		defaultTTL: defaultTTL,
+		clock:      clock,
+	}
+
+	if metricsConfig != nil {
+		controller.metricLabels = make([]string, len(metricsConfig.MetricsLabels))
+		for i, label := range metricsConfig.MetricsLabels {
+			controller.metricLabels[i] = util.CreateValidMetricNameLabel("", label)
+		}
+		controller.deletionCount = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: util.CreateValidMetricNameLabel(metricsConfig.MetricsPrefix, "spark_app_ttl_deletion_count"),
+				Help: "Spark App Deletions after their TTL via the Operator",
+			},
+			controller.metricLabels,
+		)
+		util.RegisterMetric(controller.deletionCount)
+	}
+
+	informer := informerFactory.Sparkoperator().V1beta1().SparkApplications()
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.onAdd,
+		UpdateFunc: controller.onUpdate,
+	})
+	controller.cacheSynced = informer.Informer().HasSynced
+	controller.saLister = informer.Lister()
+
+	return controller
+}
+
+// Start starts the workers of the Controller.
+func (c *Controller) Start(workers int, stopCh <-chan struct{}) error {
+	glog.Info("Starting the SparkApplication TTL controller")
+
+	if !cache.WaitForCacheSync(stopCh, c.cacheSynced) {
+		return fmt.Errorf("timed out waiting for cache to sync")
+	}
+
+	glog.Info("Starting the workers of the SparkApplication TTL controller")
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	return nil
+}
+
+// Stop stops the Controller.
+func (c *Controller) Stop() {
+	glog.Info("Stopping the SparkApplication TTL controller")
+	c.queue.ShutDown()
+}
+
+func (c *Controller) onAdd(obj interface{}) {
+	c.enqueueIfFinished(obj.(*v1beta1.SparkApplication))
+}
+
+func (c *Controller) onUpdate(oldObj, newObj interface{}) {
+	c.enqueueIfFinished(newObj.(*v1beta1.SparkApplication))
+}
+
+func (c *Controller) enqueueIfFinished(app *v1beta1.SparkApplication) {
+	if !app.DeletionTimestamp.IsZero() || c.getTTL(app) == nil || !isFinished(app) {
+		return
+	}
+	key, err := keyFunc(app)
+	if err != nil {
+		glog.Errorf("failed to get key for %v: %v", app, err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *Controller) runWorker() {
+	defer utilruntime.HandleCrash()
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncSparkApplication(key.(string))
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+
+	utilruntime.HandleError(fmt.Errorf("failed to delete expired SparkApplication %q: %v", key, err))
+	c.queue.AddRateLimited(key)
+	return true
+}
+
+// syncSparkApplication deletes the SparkApplication of the given key if its TTL has passed, or enqueues it again
+// for the time the TTL passes at otherwise.
+func (c *Controller) syncSparkApplication(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	app, err := c.saLister.SparkApplications(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	expired, err := c.processTTL(app)
+	if err != nil || !expired {
+		return err
+	}
+
+	// The cached SparkApplication may be stale, so check the latest one before deleting it, in case it was
+	// re-run or its TTL was changed in the meantime.
+	app, err = c.crdClient.SparkoperatorV1beta1().SparkApplications(namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if expired, err = c.processTTL(app); err != nil || !expired {
+		return err
+	}
+
+	glog.Infof("Deleting SparkApplication %s/%s as its time to live after finishing has passed", namespace, name)
+	uid := app.UID
+	err = c.crdClient.SparkoperatorV1beta1().SparkApplications(namespace).Delete(name, &metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &uid},
+	})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	c.exportDeletion(app)
+	return nil
+}
+
+// processTTL tells if the TTL of the SparkApplication has passed. If the application has finished but its TTL has
+// not passed yet, it is enqueued again for the time the TTL passes at.
+func (c *Controller) processTTL(app *v1beta1.SparkApplication) (bool, error) {
+	ttl := c.getTTL(app)
+	if !app.DeletionTimestamp.IsZero() || ttl == nil || !isFinished(app) {
+		return false, nil
+	}
+
+	expireTime := getFinishTime(app).Add(time.Duration(*ttl) * time.Second)
+	now := c.clock.Now()
+	if !now.Before(expireTime) {
+		return true, nil
+	}
+
+	key, err := keyFunc(app)
+	if err != nil {
+		return false, err
+	}
+	c.queue.AddAfter(key, expireTime.Sub(now))
+	return false, nil
+}
+
+func (c *Controller) getTTL(app *v1beta1.SparkApplication) *int64 {
+	if app.Spec.TimeToLiveSecondsAfterFinished != nil {
+		return app.Spec.TimeToLiveSecondsAfterFinished
+	}
+	return c.defaultTTL
+}
+
+func (c *Controller) exportDeletion(app *v1beta1.SparkApplication) {
+	if c.deletionCount == nil {
+		return
+	}
+	if m, err := c.deletionCount.GetMetricWith(util.FetchMetricLabels(app.Labels, c.metricLabels)); err != nil {
+		glog.Errorf("Error while exporting metrics: %v", err)
+	} else {
+		m.Inc()
+	}
+}
+
+func isFinished(app *v1beta1.SparkApplication) bool {
+	return app.Status.AppState.State == v1beta1.CompletedState || app.Status.AppState.State == v1beta1.FailedState
+}
+
+// getFinishTime returns the time the SparkApplication finished at. An application failing before its driver
+// terminated has no termination time, the time of its last submission attempt or its creation is used instead.
+func getFinishTime(app *v1beta1.SparkApplication) time.Time {
+	if !app.Status.TerminationTime.IsZero() {
+		return app.Status.TerminationTime.Time
+	}
+	if !app.Status.LastSubmissionAttemptTime.IsZero() {
+		return app.Status.LastSubmissionAttemptTime.Time
+	}
+	return app.CreationTimestamp.Time
+}
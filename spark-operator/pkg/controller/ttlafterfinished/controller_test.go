@@ -0,0 +1,152 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ttlafterfinished
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+)
+
+func TestSyncSparkApplication(t *testing.T) {
+	ttl := int64(60)
+	defaultTTL := int64(3600)
+	now := time.Now()
+
+	type testcase struct {
+		name          string
+		state         v1beta1.ApplicationStateType
+		ttl           *int64
+		defaultTTL    *int64
+		finishedSince time.Duration
+		expectDeleted bool
+	}
+
+	testcases := []testcase{
+		{
+			name:          "completed application past its TTL",
+			state:         v1beta1.CompletedState,
+			ttl:           &ttl,
+			finishedSince: 2 * time.Minute,
+			expectDeleted: true,
+		},
+		{
+			name:          "failed application past its TTL",
+			state:         v1beta1.FailedState,
+			ttl:           &ttl,
+			defaultTTL:    &defaultTTL,
+			finishedSince: 2 * time.Minute,
+			expectDeleted: true,
+		},
+		{
+			name:          "completed application within its TTL",
+			state:         v1beta1.CompletedState,
+			ttl:           &ttl,
+			finishedSince: 30 * time.Second,
+			expectDeleted: false,
+		},
+		{
+			name:          "running application",
+			state:         v1beta1.RunningState,
+			ttl:           &ttl,
+			finishedSince: 2 * time.Minute,
+			expectDeleted: false,
+		},
+		{
+			name:          "completed application without TTL",
+			state:         v1beta1.CompletedState,
+			finishedSince: 2 * time.Hour,
+			expectDeleted: false,
+		},
+		{
+			name:          "completed application past the default TTL",
+			state:         v1beta1.CompletedState,
+			defaultTTL:    &defaultTTL,
+			finishedSince: 2 * time.Hour,
+			expectDeleted: true,
+		},
+	}
+
+	for _, test := range testcases {
+		app := &v1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec:       v1beta1.SparkApplicationSpec{TimeToLiveSecondsAfterFinished: test.ttl},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState:        v1beta1.ApplicationState{State: test.state},
+				TerminationTime: metav1.NewTime(now.Add(-test.finishedSince)),
+			},
+		}
+		c := newFakeController(test.defaultTTL, clock.NewFakeClock(now), app)
+		key, _ := cache.MetaNamespaceKeyFunc(app)
+
+		assert.Nil(t, c.syncSparkApplication(key), test.name)
+		_, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+		assert.Equal(t, test.expectDeleted, errors.IsNotFound(err), test.name)
+	}
+}
+
+func TestSyncSparkApplication_AfterTTL(t *testing.T) {
+	ttl := int64(60)
+	now := time.Now()
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       v1beta1.SparkApplicationSpec{TimeToLiveSecondsAfterFinished: &ttl},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: v1beta1.FailedState},
+			LastSubmissionAttemptTime: metav1.NewTime(now),
+		},
+	}
+	clk := clock.NewFakeClock(now)
+	c := newFakeController(nil, clk, app)
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+
+	// The application failed to be submitted, so the TTL counts from the last submission attempt.
+	assert.Nil(t, c.syncSparkApplication(key))
+	_, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	clk.Step(time.Minute)
+	assert.Nil(t, c.syncSparkApplication(key))
+	_, err = c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	// Syncing a deleted application is a no-op.
+	assert.Nil(t, c.syncSparkApplication(key))
+}
+
+func newFakeController(defaultTTL *int64, clk clock.Clock, apps ...*v1beta1.SparkApplication) *Controller {
+	crdClient := crdclientfake.NewSimpleClientset()
+	informerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0*time.Second)
+	controller := NewController(crdClient, informerFactory, nil, defaultTTL, clk)
+
+	informer := informerFactory.Sparkoperator().V1beta1().SparkApplications().Informer()
+	for _, app := range apps {
+		crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+		informer.GetIndexer().Add(app)
+	}
+	return controller
+}
@@ -32,20 +32,32 @@ import (
 
 	ssacrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/scheduledsparkapplication"
 	sacrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkapplication"
+	spcrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkpipeline"
 )
 
-// CreateOrUpdateCRDs creates or updates the relevant CRDs used by the operator.
-func CreateOrUpdateCRDs(clientset apiextensionsclient.Interface) error {
-	err := createOrUpdateCRD(clientset, sacrd.GetCRD())
+// CreateOrUpdateCRDs creates or updates the relevant CRDs used by the operator. The given conversion is used to
+// convert objects between the served versions. If it is nil, only v1beta1 is served, with the "None" conversion
+// strategy.
+func CreateOrUpdateCRDs(clientset apiextensionsclient.Interface, conversion *apiextensionsv1beta1.CustomResourceConversion) error {
+	if conversion == nil {
+		conversion = &apiextensionsv1beta1.CustomResourceConversion{Strategy: apiextensionsv1beta1.NoneConverter}
+	}
+
+	err := createOrUpdateCRD(clientset, sacrd.GetCRD(conversion))
 	if err != nil {
 		return fmt.Errorf("failed to create or update CustomResourceDefinition %s: %v", sacrd.FullName, err)
 	}
 
-	err = createOrUpdateCRD(clientset, ssacrd.GetCRD())
+	err = createOrUpdateCRD(clientset, ssacrd.GetCRD(conversion))
 	if err != nil {
 		return fmt.Errorf("failed to create or update CustomResourceDefinition %s: %v", ssacrd.FullName, err)
 	}
 
+	err = createOrUpdateCRD(clientset, spcrd.GetCRD())
+	if err != nil {
+		return fmt.Errorf("failed to create or update CustomResourceDefinition %s: %v", spcrd.FullName, err)
+	}
+
 	return nil
 }
 
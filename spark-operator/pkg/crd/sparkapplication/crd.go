@@ -23,6 +23,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1alpha1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 )
 
@@ -36,15 +37,30 @@ const (
 	FullName  = Plural + "." + Group
 )
 
-func GetCRD() *apiextensionsv1beta1.CustomResourceDefinition {
+// GetCRD returns the CustomResourceDefinition of SparkApplication. v1beta1 is the storage version. v1alpha1 is only
+// served if objects are converted between the versions by a webhook according to the given conversion, as the
+// API server would otherwise store v1alpha1 objects as they are.
+func GetCRD(conversion *apiextensionsv1beta1.CustomResourceConversion) *apiextensionsv1beta1.CustomResourceDefinition {
+	versions := []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+		{Name: v1beta1.Version, Served: true, Storage: true},
+	}
+	if conversion != nil && conversion.Strategy == apiextensionsv1beta1.WebhookConverter {
+		versions = append(versions, apiextensionsv1beta1.CustomResourceDefinitionVersion{
+			Name:    v1alpha1.Version,
+			Served:  true,
+			Storage: false,
+		})
+	}
+
 	return &apiextensionsv1beta1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: FullName,
 		},
 		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
-			Group:   Group,
-			Version: Version,
-			Scope:   apiextensionsv1beta1.NamespaceScoped,
+			Group:    Group,
+			Version:  Version,
+			Versions: versions,
+			Scope:    apiextensionsv1beta1.NamespaceScoped,
 			Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
 				Plural:     Plural,
 				Singular:   Singular,
@@ -52,6 +68,10 @@ func GetCRD() *apiextensionsv1beta1.CustomResourceDefinition {
 				Kind:       reflect.TypeOf(v1beta1.SparkApplication{}).Name(),
 			},
 			Validation: getCustomResourceValidation(),
+			Subresources: &apiextensionsv1beta1.CustomResourceSubresources{
+				Status: &apiextensionsv1beta1.CustomResourceSubresourceStatus{},
+			},
+			Conversion: conversion,
 		},
 	}
 }
@@ -108,6 +128,14 @@ func getCustomResourceValidation() *apiextensionsv1beta1.CustomResourceValidatio
 									Type:    "integer",
 									Minimum: float64Ptr(1),
 								},
+								"maxFailures": {
+									Type:    "integer",
+									Minimum: float64Ptr(0),
+								},
+								"failureWindowSeconds": {
+									Type:    "integer",
+									Minimum: float64Ptr(1),
+								},
 							},
 						},
 						"deps": {
@@ -147,6 +175,55 @@ func getCustomResourceValidation() *apiextensionsv1beta1.CustomResourceValidatio
 									Type:    "integer",
 									Minimum: float64Ptr(1),
 								},
+								"backoff": {
+									Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+										"initialIntervalSeconds": {
+											Type:    "integer",
+											Minimum: float64Ptr(1),
+										},
+										"multiplier": {
+											Type:    "number",
+											Minimum: float64Ptr(1),
+										},
+										"maxIntervalSeconds": {
+											Type:    "integer",
+											Minimum: float64Ptr(1),
+										},
+										"jitterFactor": {
+											Type:    "number",
+											Minimum: float64Ptr(0),
+											Maximum: float64Ptr(1),
+										},
+									},
+								},
+								"memoryIncreaseOnOOM": {
+									Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+										"factor": {
+											Type:    "number",
+											Minimum: float64Ptr(1),
+										},
+									},
+								},
+								"failureRules": {
+									Type: "array",
+									Items: &apiextensionsv1beta1.JSONSchemaPropsOrArray{
+										Schema: &apiextensionsv1beta1.JSONSchemaProps{
+											Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+												"action": {
+													Enum: []apiextensionsv1beta1.JSON{
+														{Raw: []byte(`"Retry"`)},
+														{Raw: []byte(`"NeverRetry"`)},
+														{Raw: []byte(`"RetryWithMoreMemory"`)},
+													},
+												},
+												"memoryIncreaseFactor": {
+													Type:    "number",
+													Minimum: float64Ptr(1),
+												},
+											},
+										},
+									},
+								},
 							},
 						},
 						"pythonVersion": {
@@ -155,6 +232,50 @@ func getCustomResourceValidation() *apiextensionsv1beta1.CustomResourceValidatio
 								{Raw: []byte(`"3"`)},
 							},
 						},
+						"timeToLiveBeforeRunning": {
+							Type:    "integer",
+							Minimum: float64Ptr(1),
+						},
+						"activeDeadlineSeconds": {
+							Type:    "integer",
+							Minimum: float64Ptr(1),
+						},
+						"timeToLiveSecondsAfterFinished": {
+							Type:    "integer",
+							Minimum: float64Ptr(0),
+						},
+						"compactExecutorStatus": {
+							Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+								"maxFailedExecutorDetails": {
+									Type:    "integer",
+									Minimum: float64Ptr(0),
+								},
+							},
+						},
+						"unrecoverablePendingPolicy": {
+							Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+								"failAfterMinutes": {
+									Type:    "integer",
+									Minimum: float64Ptr(1),
+								},
+							},
+						},
+						"dynamicAllocation": {
+							Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+								"initialExecutors": {
+									Type:    "integer",
+									Minimum: float64Ptr(0),
+								},
+								"minExecutors": {
+									Type:    "integer",
+									Minimum: float64Ptr(0),
+								},
+								"maxExecutors": {
+									Type:    "integer",
+									Minimum: float64Ptr(0),
+								},
+							},
+						},
 						"monitoring": {
 							Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
 								"prometheus": {
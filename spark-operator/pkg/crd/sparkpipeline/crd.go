@@ -0,0 +1,115 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkpipeline
+
+import (
+	"reflect"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// CRD metadata.
+const (
+	Plural    = "sparkpipelines"
+	Singular  = "sparkpipeline"
+	ShortName = "sparkpipeline"
+	Group     = sparkoperator.GroupName
+	Version   = v1beta1.Version
+	FullName  = Plural + "." + Group
+)
+
+// GetCRD returns the CustomResourceDefinition of SparkPipeline, which only has the v1beta1 version.
+func GetCRD() *apiextensionsv1beta1.CustomResourceDefinition {
+	return &apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: FullName,
+		},
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Group:   Group,
+			Version: Version,
+			Versions: []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+				{Name: v1beta1.Version, Served: true, Storage: true},
+			},
+			Scope: apiextensionsv1beta1.NamespaceScoped,
+			Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
+				Plural:     Plural,
+				Singular:   Singular,
+				ShortNames: []string{ShortName},
+				Kind:       reflect.TypeOf(v1beta1.SparkPipeline{}).Name(),
+			},
+			Validation: getCustomResourceValidation(),
+			Subresources: &apiextensionsv1beta1.CustomResourceSubresources{
+				Status: &apiextensionsv1beta1.CustomResourceSubresourceStatus{},
+			},
+		},
+	}
+}
+
+func getCustomResourceValidation() *apiextensionsv1beta1.CustomResourceValidation {
+	return &apiextensionsv1beta1.CustomResourceValidation{
+		OpenAPIV3Schema: &apiextensionsv1beta1.JSONSchemaProps{
+			Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+				"spec": *GetSpecSchema(),
+			},
+		},
+	}
+}
+
+// GetSpecSchema returns the validation schema of a SparkPipelineSpec, which is also used for the pipeline templates
+// of ScheduledSparkApplications.
+func GetSpecSchema() *apiextensionsv1beta1.JSONSchemaProps {
+	return &apiextensionsv1beta1.JSONSchemaProps{
+		Required: []string{"steps"},
+		Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+			"steps": {
+				Type:     "array",
+				MinItems: int64Ptr(1),
+				Items: &apiextensionsv1beta1.JSONSchemaPropsOrArray{
+					Schema: &apiextensionsv1beta1.JSONSchemaProps{
+						Required: []string{"name", "template"},
+						Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+							"name": {
+								Type:    "string",
+								Pattern: "^[a-z0-9]([-a-z0-9]*[a-z0-9])?$",
+							},
+							"dependsOn": {
+								Type: "array",
+								Items: &apiextensionsv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextensionsv1beta1.JSONSchemaProps{Type: "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+			"failurePolicy": {
+				Enum: []apiextensionsv1beta1.JSON{
+					{Raw: []byte(`"StopOnFailure"`)},
+					{Raw: []byte(`"ContinueOnFailure"`)},
+				},
+			},
+		},
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var javaStringSuffixes = map[string]int64{
+	"b":  1,
+	"kb": 1 << 10,
+	"k":  1 << 10,
+	"mb": 1 << 20,
+	"m":  1 << 20,
+	"gb": 1 << 30,
+	"g":  1 << 30,
+	"tb": 1 << 40,
+	"t":  1 << 40,
+	"pb": 1 << 50,
+	"p":  1 << 50,
+}
+
+var javaStringPattern = regexp.MustCompile(`^([0-9]+)([a-z]+)?$`)
+var javaFractionStringPattern = regexp.MustCompile(`^([0-9]+\.[0-9]+)([a-z]+)?$`)
+
+// ParseJavaMemoryString parses a Java-style memory string, e.g., 512m, 1.5g or 1g, into the number of bytes. The
+// whole string must be a memory value.
+// Logic copied from https://github.com/apache/spark/blob/5264164a67df498b73facae207eda12ee133be7d/common/network-common/src/main/java/org/apache/spark/network/util/JavaUtils.java#L276
+func ParseJavaMemoryString(str string) (int64, error) {
+	lower := strings.TrimSpace(strings.ToLower(str))
+	if matches := javaFractionStringPattern.FindStringSubmatch(lower); matches != nil {
+		value, err := strconv.ParseFloat(matches[1], 64)
+		if err != nil {
+			return 0, err
+		}
+		suffix := matches[2]
+		if multiplier, present := javaStringSuffixes[suffix]; present {
+			return int64(float64(multiplier) * value), nil
+		}
+	} else if matches = javaStringPattern.FindStringSubmatch(lower); matches != nil {
+		value, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		suffix := matches[2]
+		if multiplier, present := javaStringSuffixes[suffix]; present {
+			return multiplier * value, nil
+		}
+	}
+	return 0, fmt.Errorf("could not parse string '%s' as a Java-style memory value. Examples: 100kb, 1.5mb, 1g", str)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertMemory(memoryString string, expectedBytes int64, t *testing.T) {
+	m, err := ParseJavaMemoryString(memoryString)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if m != expectedBytes {
+		t.Errorf("%s: expected %v bytes, got %v bytes", memoryString, expectedBytes, m)
+		return
+	}
+}
+
+func TestJavaMemoryString(t *testing.T) {
+	assertMemory("1b", 1, t)
+	assertMemory("100k", 100*1024, t)
+	assertMemory("1gb", 1024*1024*1024, t)
+	assertMemory("10TB", 10*1024*1024*1024*1024, t)
+	assertMemory("10PB", 10*1024*1024*1024*1024*1024, t)
+}
+
+func TestFractionalJavaMemoryString(t *testing.T) {
+	assertMemory("1.5g", 3*512*1024*1024, t)
+	assertMemory("1.5mb", 3*512*1024, t)
+	assertMemory("0.5K", 512, t)
+}
+
+func TestInvalidJavaMemoryString(t *testing.T) {
+	testcases := []string{
+		"",
+		"1",
+		"1.5",
+		"lots",
+		"lots1g",
+		"2g of ram",
+		"1.5.2g",
+		"-1g",
+		"1x",
+	}
+
+	for _, test := range testcases {
+		_, err := ParseJavaMemoryString(test)
+		assert.Error(t, err, test)
+	}
+}
@@ -55,6 +55,27 @@ type MetricConfig struct {
 	MetricsLabels   []string
 }
 
+// FetchMetricLabels returns the values of the given metric labels taken from the labels of an object, with "Unknown"
+// for the ones the object does not have.
+func FetchMetricLabels(specLabels map[string]string, labels []string) map[string]string {
+	// Transform spec labels since our labels names might be not same as specLabels if we removed invalid characters.
+	validSpecLabels := make(map[string]string)
+	for labelKey, v := range specLabels {
+		newKey := CreateValidMetricNameLabel("", labelKey)
+		validSpecLabels[newKey] = v
+	}
+
+	metricLabels := make(map[string]string)
+	for _, label := range labels {
+		if value, ok := validSpecLabels[label]; ok {
+			metricLabels[label] = value
+		} else {
+			metricLabels[label] = "Unknown"
+		}
+	}
+	return metricLabels
+}
+
 // A variant of Prometheus Gauge that only holds non-negative values.
 type PositiveGauge struct {
 	mux         sync.RWMutex
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// scheduleTimeZonePrefixes are the prefixes a cron schedule may name its time zone with, e.g.,
+// "CRON_TZ=America/New_York 0 2 * * *".
+var scheduleTimeZonePrefixes = []string{"CRON_TZ=", "TZ="}
+
+// GetScheduleLocation splits the time zone a cron schedule is evaluated in off the schedule. The time zone is the
+// one named by the CRON_TZ= or TZ= prefix of the schedule, or by timeZone if the schedule has no such prefix, or the
+// local time zone of the operator if neither names one. It returns the schedule without the prefix and the location
+// of the time zone.
+func GetScheduleLocation(schedule string, timeZone string) (string, *time.Location, error) {
+	schedule = strings.TrimSpace(schedule)
+	for _, prefix := range scheduleTimeZonePrefixes {
+		if !strings.HasPrefix(schedule, prefix) {
+			continue
+		}
+		fields := strings.SplitN(strings.TrimPrefix(schedule, prefix), " ", 2)
+		if len(fields) < 2 {
+			return "", nil, fmt.Errorf("schedule %q names a time zone but no schedule", schedule)
+		}
+		if timeZone != "" && timeZone != fields[0] {
+			return "", nil, fmt.Errorf("schedule %q names time zone %s which conflicts with time zone %s",
+				schedule, fields[0], timeZone)
+		}
+		timeZone = fields[0]
+		schedule = strings.TrimSpace(fields[1])
+		break
+	}
+
+	if timeZone == "" {
+		return schedule, time.Local, nil
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return "", nil, fmt.Errorf("unknown time zone %s: %v", timeZone, err)
+	}
+	return schedule, location, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetScheduleLocation(t *testing.T) {
+	type testcase struct {
+		schedule         string
+		timeZone         string
+		expectedSchedule string
+		expectedLocation string
+		expectError      bool
+	}
+
+	testcases := []testcase{
+		{schedule: "0 2 * * *", expectedSchedule: "0 2 * * *", expectedLocation: time.Local.String()},
+		{schedule: "@daily", timeZone: "UTC", expectedSchedule: "@daily", expectedLocation: "UTC"},
+		{
+			schedule:         "0 2 * * *",
+			timeZone:         "America/New_York",
+			expectedSchedule: "0 2 * * *",
+			expectedLocation: "America/New_York",
+		},
+		{
+			schedule:         "CRON_TZ=Europe/Berlin 0 2 * * *",
+			expectedSchedule: "0 2 * * *",
+			expectedLocation: "Europe/Berlin",
+		},
+		{
+			schedule:         "TZ=Europe/Berlin @hourly",
+			timeZone:         "Europe/Berlin",
+			expectedSchedule: "@hourly",
+			expectedLocation: "Europe/Berlin",
+		},
+		{schedule: "CRON_TZ=Europe/Berlin 0 2 * * *", timeZone: "America/New_York", expectError: true},
+		{schedule: "CRON_TZ=Europe/Berlin", expectError: true},
+		{schedule: "0 2 * * *", timeZone: "Mars/Olympus_Mons", expectError: true},
+	}
+
+	for _, test := range testcases {
+		schedule, location, err := GetScheduleLocation(test.schedule, test.timeZone)
+		if test.expectError {
+			assert.Error(t, err, test.schedule)
+			continue
+		}
+		assert.Nil(t, err, test.schedule)
+		assert.Equal(t, test.expectedSchedule, schedule, test.schedule)
+		assert.Equal(t, test.expectedLocation, location.String(), test.schedule)
+	}
+}
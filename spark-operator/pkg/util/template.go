@@ -0,0 +1,145 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// RunTemplateData is the data the templates in the spec of a run of a ScheduledSparkApplication are expanded with.
+type RunTemplateData struct {
+	// ScheduledTime is the time the run is scheduled at, in the time zone of the schedule.
+	ScheduledTime time.Time
+	// ActualTime is the time the run is actually created at, in the time zone of the schedule.
+	ActualTime time.Time
+	// RunName is the name of the SparkApplication or SparkPipeline of the run.
+	RunName string
+	// ScheduledAppName is the name of the ScheduledSparkApplication.
+	ScheduledAppName string
+}
+
+// runTemplateFuncs are the functions available to the templates in addition to the predefined ones.
+var runTemplateFuncs = template.FuncMap{
+	// formatTime formats a time with a Go layout, e.g., {{formatTime "2006/01/02" .ScheduledTime}}.
+	"formatTime": func(layout string, t time.Time) string { return t.Format(layout) },
+	// date formats a time as a date, e.g., 2019-05-01.
+	"date": func(t time.Time) string { return t.Format("2006-01-02") },
+	// compactDate formats a time as a date without separators, e.g., 20190501.
+	"compactDate": func(t time.Time) string { return t.Format("20060102") },
+	"addDays":     func(days int, t time.Time) time.Time { return t.AddDate(0, 0, days) },
+	"addHours":    func(hours int, t time.Time) time.Time { return t.Add(time.Duration(hours) * time.Hour) },
+	"utc":         func(t time.Time) time.Time { return t.UTC() },
+}
+
+// RunTemplateError is an error parsing or expanding a template in the spec of a run.
+type RunTemplateError struct {
+	// Field is the field holding the template, e.g., arguments[0] or sparkConf[spark.eventLog.dir].
+	Field string
+	// Template is the template.
+	Template string
+	Err      error
+}
+
+func (e *RunTemplateError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+// ValidateRunTemplates checks that the templates in the given spec can be parsed and expanded. Fields and functions
+// are only resolved when templates are executed, so the templates are expanded with zero data, the output of which is
+// discarded.
+func ValidateRunTemplates(spec *v1beta1.SparkApplicationSpec) error {
+	return forEachRunTemplate(spec, func(text string) (string, error) {
+		tmpl, err := parseRunTemplate(text)
+		if err != nil {
+			return text, err
+		}
+		return text, tmpl.Execute(ioutil.Discard, RunTemplateData{})
+	})
+}
+
+// ExpandRunTemplates expands the templates in the spec of a run of a ScheduledSparkApplication in place. Templates
+// are Go templates in the arguments, the Spark and Hadoop configuration properties, and the environment variables
+// and labels of the driver and executors.
+func ExpandRunTemplates(spec *v1beta1.SparkApplicationSpec, data RunTemplateData) error {
+	return forEachRunTemplate(spec, func(text string) (string, error) {
+		tmpl, err := parseRunTemplate(text)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	})
+}
+
+func parseRunTemplate(text string) (*template.Template, error) {
+	return template.New("").Option("missingkey=error").Funcs(runTemplateFuncs).Parse(text)
+}
+
+// forEachRunTemplate replaces every string in the spec that may hold a template with the result of calling fn on it.
+// The spec is left untouched where the result is the same string, so specs can be validated without being copied.
+func forEachRunTemplate(spec *v1beta1.SparkApplicationSpec, fn func(text string) (string, error)) error {
+	for i, arg := range spec.Arguments {
+		expanded, err := fn(arg)
+		if err != nil {
+			return &RunTemplateError{Field: fmt.Sprintf("arguments[%d]", i), Template: arg, Err: err}
+		}
+		if expanded != arg {
+			spec.Arguments[i] = expanded
+		}
+	}
+
+	maps := []struct {
+		field  string
+		values map[string]string
+	}{
+		{"sparkConf", spec.SparkConf},
+		{"hadoopConf", spec.HadoopConf},
+		{"driver.envVars", spec.Driver.EnvVars},
+		{"driver.labels", spec.Driver.Labels},
+		{"executor.envVars", spec.Executor.EnvVars},
+		{"executor.labels", spec.Executor.Labels},
+	}
+	for _, m := range maps {
+		// Keys are visited in order so errors are reported consistently.
+		keys := make([]string, 0, len(m.values))
+		for key := range m.values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := m.values[key]
+			expanded, err := fn(value)
+			if err != nil {
+				return &RunTemplateError{Field: fmt.Sprintf("%s[%s]", m.field, key), Template: value, Err: err}
+			}
+			if expanded != value {
+				m.values[key] = expanded
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestExpandRunTemplates(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := RunTemplateData{
+		ScheduledTime:    time.Date(2019, time.May, 1, 2, 0, 0, 0, location),
+		ActualTime:       time.Date(2019, time.May, 1, 2, 0, 5, 0, location),
+		RunName:          "foo-1556690400000000000",
+		ScheduledAppName: "foo",
+	}
+
+	spec := &v1beta1.SparkApplicationSpec{
+		Arguments: []string{"--date={{date .ScheduledTime}}", "--previous={{date (addDays -1 .ScheduledTime)}}", "plain"},
+		SparkConf: map[string]string{
+			"spark.eventLog.dir": "gs://bucket/{{.ScheduledAppName}}/{{formatTime \"2006/01/02\" .ScheduledTime}}",
+		},
+		HadoopConf: map[string]string{"fs.default.name": "hdfs://{{.RunName}}"},
+		Driver: v1beta1.DriverSpec{
+			SparkPodSpec: v1beta1.SparkPodSpec{
+				EnvVars: map[string]string{"STARTED_AT": "{{.ActualTime.Format \"15:04:05\"}}"},
+				Labels:  map[string]string{"run-date": "{{compactDate .ScheduledTime}}"},
+			},
+		},
+		Executor: v1beta1.ExecutorSpec{
+			SparkPodSpec: v1beta1.SparkPodSpec{
+				EnvVars: map[string]string{"SCHEDULED_AT_UTC": "{{formatTime \"15:04\" (utc .ScheduledTime)}}"},
+				Labels:  map[string]string{"run-hour": "{{formatTime \"15\" (addHours 1 .ScheduledTime)}}"},
+			},
+		},
+	}
+	assert.Nil(t, ValidateRunTemplates(spec))
+	assert.Nil(t, ExpandRunTemplates(spec, data))
+
+	assert.Equal(t, []string{"--date=2019-05-01", "--previous=2019-04-30", "plain"}, spec.Arguments)
+	assert.Equal(t, "gs://bucket/foo/2019/05/01", spec.SparkConf["spark.eventLog.dir"])
+	assert.Equal(t, "hdfs://foo-1556690400000000000", spec.HadoopConf["fs.default.name"])
+	assert.Equal(t, "02:00:05", spec.Driver.EnvVars["STARTED_AT"])
+	assert.Equal(t, "20190501", spec.Driver.Labels["run-date"])
+	assert.Equal(t, "06:00", spec.Executor.EnvVars["SCHEDULED_AT_UTC"])
+	assert.Equal(t, "03", spec.Executor.Labels["run-hour"])
+}
+
+func TestExpandRunTemplates_Errors(t *testing.T) {
+	type testcase struct {
+		name          string
+		spec          v1beta1.SparkApplicationSpec
+		expectedField string
+	}
+
+	testcases := []testcase{
+		{
+			name:          "unterminated action",
+			spec:          v1beta1.SparkApplicationSpec{Arguments: []string{"ok", "{{date .ScheduledTime"}},
+			expectedField: "arguments[1]",
+		},
+		{
+			name:          "unknown function",
+			spec:          v1beta1.SparkApplicationSpec{SparkConf: map[string]string{"spark.foo": "{{yesterday}}"}},
+			expectedField: "sparkConf[spark.foo]",
+		},
+		{
+			name:          "unknown variable",
+			spec:          v1beta1.SparkApplicationSpec{HadoopConf: map[string]string{"foo": "{{.LogicalDate}}"}},
+			expectedField: "hadoopConf[foo]",
+		},
+		{
+			name:          "misspelled variable",
+			spec:          v1beta1.SparkApplicationSpec{Arguments: []string{"--date={{date .RunDate}}"}},
+			expectedField: "arguments[0]",
+		},
+		{
+			name: "wrong argument type",
+			spec: v1beta1.SparkApplicationSpec{
+				Driver: v1beta1.DriverSpec{
+					SparkPodSpec: v1beta1.SparkPodSpec{EnvVars: map[string]string{"FOO": "{{date .RunName}}"}},
+				},
+			},
+			expectedField: "driver.envVars[FOO]",
+		},
+	}
+
+	for _, test := range testcases {
+		err := ValidateRunTemplates(&test.spec)
+		if assert.Error(t, err, test.name) {
+			assert.Equal(t, test.expectedField, err.(*RunTemplateError).Field, test.name)
+		}
+
+		err = ExpandRunTemplates(&test.spec, RunTemplateData{ScheduledTime: time.Now()})
+		if assert.Error(t, err, test.name) {
+			assert.Equal(t, test.expectedField, err.(*RunTemplateError).Field, test.name)
+		}
+	}
+}
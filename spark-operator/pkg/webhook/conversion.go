@@ -0,0 +1,227 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+
+	"github.com/golang/glog"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	crdv1alpha1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1alpha1"
+	crdv1beta1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const conversionPath = "/convert"
+
+var (
+	sparkApplicationKind          = reflect.TypeOf(crdv1beta1.SparkApplication{}).Name()
+	scheduledSparkApplicationKind = reflect.TypeOf(crdv1beta1.ScheduledSparkApplication{}).Name()
+)
+
+// GetCRDConversion returns the conversion configuration of the CRDs that makes the API server call the
+// conversion endpoint of the webhook server.
+func GetCRDConversion() (*apiextensionsv1beta1.CustomResourceConversion, error) {
+	caCert, err := readCertFile(userConfig.caCert)
+	if err != nil {
+		return nil, err
+	}
+	path := conversionPath
+	return &apiextensionsv1beta1.CustomResourceConversion{
+		Strategy: apiextensionsv1beta1.WebhookConverter,
+		WebhookClientConfig: &apiextensionsv1beta1.WebhookClientConfig{
+			Service: &apiextensionsv1beta1.ServiceReference{
+				Namespace: userConfig.webhookServiceNamespace,
+				Name:      userConfig.webhookServiceName,
+				Path:      &path,
+			},
+			CABundle: caCert,
+		},
+	}, nil
+}
+
+func (wh *WebHook) serveConversion(w http.ResponseWriter, r *http.Request) {
+	glog.V(2).Info("Serving conversion request")
+	var body []byte
+	if r.Body != nil {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read the request body", http.StatusInternalServerError)
+			return
+		}
+		body = data
+	}
+
+	if len(body) == 0 {
+		http.Error(w, "empty request body", http.StatusBadRequest)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		http.Error(w, "invalid Content-Type, expected `application/json`", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	review := &apiextensionsv1beta1.ConversionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("failed to decode the ConversionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response := apiextensionsv1beta1.ConversionReview{
+		TypeMeta: review.TypeMeta,
+		Response: convertObjects(review.Request),
+	}
+	resp, err := json.Marshal(response)
+	if err != nil {
+		glog.Errorf("failed to marshal the ConversionReview response: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := w.Write(resp); err != nil {
+		glog.Errorf("failed to write response body: %v", err)
+	}
+}
+
+// convertObjects converts the objects in a conversion request to the desired API version. The response fails
+// as a whole if any of the objects cannot be converted.
+func convertObjects(request *apiextensionsv1beta1.ConversionRequest) *apiextensionsv1beta1.ConversionResponse {
+	response := &apiextensionsv1beta1.ConversionResponse{UID: request.UID}
+	for _, object := range request.Objects {
+		converted, err := convertObject(object.Raw, request.DesiredAPIVersion)
+		if err != nil {
+			glog.Errorf("failed to convert object to %s: %v", request.DesiredAPIVersion, err)
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			return response
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	response.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return response
+}
+
+func convertObject(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the type of the object: %v", err)
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	alphaVersion := crdv1alpha1.SchemeGroupVersion.String()
+	betaVersion := crdv1beta1.SchemeGroupVersion.String()
+	var converted interface{}
+	switch {
+	case typeMeta.Kind == sparkApplicationKind && typeMeta.APIVersion == alphaVersion && desiredAPIVersion == betaVersion:
+		in := &crdv1alpha1.SparkApplication{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, err
+		}
+		out := &crdv1beta1.SparkApplication{TypeMeta: metav1.TypeMeta{APIVersion: betaVersion, Kind: typeMeta.Kind}}
+		if err := in.ConvertTo(out); err != nil {
+			return nil, err
+		}
+		converted = out
+	case typeMeta.Kind == sparkApplicationKind && typeMeta.APIVersion == betaVersion && desiredAPIVersion == alphaVersion:
+		in := &crdv1beta1.SparkApplication{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, err
+		}
+		out := &crdv1alpha1.SparkApplication{TypeMeta: metav1.TypeMeta{APIVersion: alphaVersion, Kind: typeMeta.Kind}}
+		if err := out.ConvertFrom(in); err != nil {
+			return nil, err
+		}
+		converted = out
+	case typeMeta.Kind == scheduledSparkApplicationKind && typeMeta.APIVersion == alphaVersion && desiredAPIVersion == betaVersion:
+		in := &crdv1alpha1.ScheduledSparkApplication{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, err
+		}
+		out := &crdv1beta1.ScheduledSparkApplication{TypeMeta: metav1.TypeMeta{APIVersion: betaVersion, Kind: typeMeta.Kind}}
+		if err := in.ConvertTo(out); err != nil {
+			return nil, err
+		}
+		converted = out
+	case typeMeta.Kind == scheduledSparkApplicationKind && typeMeta.APIVersion == betaVersion && desiredAPIVersion == alphaVersion:
+		in := &crdv1beta1.ScheduledSparkApplication{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, err
+		}
+		out := &crdv1alpha1.ScheduledSparkApplication{TypeMeta: metav1.TypeMeta{APIVersion: alphaVersion, Kind: typeMeta.Kind}}
+		if err := out.ConvertFrom(in); err != nil {
+			return nil, err
+		}
+		converted = out
+	default:
+		return nil, fmt.Errorf("unsupported conversion of %s %s to %s", typeMeta.APIVersion, typeMeta.Kind, desiredAPIVersion)
+	}
+
+	return json.Marshal(converted)
+}
+
+// decodeSparkApplication decodes a SparkApplication of the version of the given resource in an admission request,
+// and converts it to v1beta1 if needed.
+func decodeSparkApplication(raw []byte, resource metav1.GroupVersionResource) (*crdv1beta1.SparkApplication, error) {
+	app := &crdv1beta1.SparkApplication{}
+	if resource.Version != crdv1alpha1.Version {
+		if err := json.Unmarshal(raw, app); err != nil {
+			return nil, err
+		}
+		return app, nil
+	}
+
+	in := &crdv1alpha1.SparkApplication{}
+	if err := json.Unmarshal(raw, in); err != nil {
+		return nil, err
+	}
+	if err := in.ConvertTo(app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// decodeScheduledSparkApplication decodes a ScheduledSparkApplication of the version of the given resource in an
+// admission request, and converts it to v1beta1 if needed.
+func decodeScheduledSparkApplication(
+	raw []byte,
+	resource metav1.GroupVersionResource) (*crdv1beta1.ScheduledSparkApplication, error) {
+	app := &crdv1beta1.ScheduledSparkApplication{}
+	if resource.Version != crdv1alpha1.Version {
+		if err := json.Unmarshal(raw, app); err != nil {
+			return nil, err
+		}
+		return app, nil
+	}
+
+	in := &crdv1alpha1.ScheduledSparkApplication{}
+	if err := json.Unmarshal(raw, in); err != nil {
+		return nil, err
+	}
+	if err := in.ConvertTo(app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	crdv1alpha1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1alpha1"
+	crdv1beta1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestConvertObjects(t *testing.T) {
+	image := "spark:2.4.0"
+	beta := &crdv1beta1.SparkApplication{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: crdv1beta1.SchemeGroupVersion.String(),
+			Kind:       sparkApplicationKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: crdv1beta1.SparkApplicationSpec{
+			Type:         crdv1beta1.ScalaApplicationType,
+			SparkVersion: "2.4.0",
+			Image:        &image,
+		},
+	}
+	raw, err := json.Marshal(beta)
+	assert.Nil(t, err)
+
+	// Convert to v1alpha1.
+	response := convertObjects(&apiextensionsv1beta1.ConversionRequest{
+		UID:               "uid",
+		DesiredAPIVersion: crdv1alpha1.SchemeGroupVersion.String(),
+		Objects:           []runtime.RawExtension{{Raw: raw}},
+	})
+	assert.Equal(t, metav1.StatusSuccess, response.Result.Status)
+	assert.Equal(t, "uid", string(response.UID))
+	assert.Equal(t, 1, len(response.ConvertedObjects))
+	alpha := &crdv1alpha1.SparkApplication{}
+	assert.Nil(t, json.Unmarshal(response.ConvertedObjects[0].Raw, alpha))
+	assert.Equal(t, crdv1alpha1.SchemeGroupVersion.String(), alpha.APIVersion)
+	assert.Equal(t, sparkApplicationKind, alpha.Kind)
+	assert.Equal(t, image, *alpha.Spec.Image)
+	assert.Contains(t, alpha.Annotations, crdv1alpha1.ConversionDataAnnotation)
+
+	// Convert back to v1beta1.
+	response = convertObjects(&apiextensionsv1beta1.ConversionRequest{
+		DesiredAPIVersion: crdv1beta1.SchemeGroupVersion.String(),
+		Objects:           []runtime.RawExtension{{Raw: response.ConvertedObjects[0].Raw}},
+	})
+	assert.Equal(t, metav1.StatusSuccess, response.Result.Status)
+	converted := &crdv1beta1.SparkApplication{}
+	assert.Nil(t, json.Unmarshal(response.ConvertedObjects[0].Raw, converted))
+	assert.Equal(t, beta, converted)
+
+	// Objects already in the desired version are returned as they are.
+	response = convertObjects(&apiextensionsv1beta1.ConversionRequest{
+		DesiredAPIVersion: crdv1beta1.SchemeGroupVersion.String(),
+		Objects:           []runtime.RawExtension{{Raw: raw}},
+	})
+	assert.Equal(t, metav1.StatusSuccess, response.Result.Status)
+	assert.Equal(t, raw, response.ConvertedObjects[0].Raw)
+
+	// Unknown kinds fail the whole request.
+	response = convertObjects(&apiextensionsv1beta1.ConversionRequest{
+		DesiredAPIVersion: crdv1alpha1.SchemeGroupVersion.String(),
+		Objects: []runtime.RawExtension{
+			{Raw: raw},
+			{Raw: []byte(`{"apiVersion":"sparkoperator.k8s.io/v1beta1","kind":"Foo"}`)},
+		},
+	})
+	assert.Equal(t, metav1.StatusFailure, response.Result.Status)
+	assert.Nil(t, response.ConvertedObjects)
+}
@@ -27,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/batchscheduler/coscheduling"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
@@ -42,8 +43,12 @@ type patchOperation struct {
 	Value interface{} `json:"value,omitempty"`
 }
 
-func patchSparkPod(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
-	var patchOps []patchOperation
+func patchSparkPod(pod *corev1.Pod, app *v1beta1.SparkApplication) ([]patchOperation, error) {
+	// The pod template is applied first so the other patch operations are computed against the merged pod.
+	pod, patchOps, err := addPodTemplate(pod, app)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply the pod template: %v", err)
+	}
 
 	if util.IsDriverPod(pod) {
 		patchOps = append(patchOps, addOwnerReference(pod, app))
@@ -64,6 +69,11 @@ func patchSparkPod(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperat
 		patchOps = append(patchOps, *op)
 	}
 
+	op = addPodGroupLabel(pod, app)
+	if op != nil {
+		patchOps = append(patchOps, *op)
+	}
+
 	if pod.Spec.Affinity == nil {
 		op := addAffinity(pod, app)
 		if op != nil {
@@ -80,7 +90,7 @@ func patchSparkPod(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperat
 	if op != nil {
 		patchOps = append(patchOps, *op)
 	}
-	return patchOps
+	return patchOps, nil
 }
 
 func addOwnerReference(pod *corev1.Pod, app *v1beta1.SparkApplication) patchOperation {
@@ -214,17 +224,22 @@ func addGeneralConfigMaps(pod *corev1.Pod, app *v1beta1.SparkApplication) []patc
 
 	var patchOps []patchOperation
 	for _, namePath := range configMaps {
-		volumeName := namePath.Name + "-vol"
-		if len(volumeName) > maxNameLength {
-			volumeName = volumeName[0:maxNameLength]
-			glog.V(2).Infof("ConfigMap volume name is too long. Truncating to length %d. Result: %s.", maxNameLength, volumeName)
-		}
+		volumeName := getConfigMapVolumeName(namePath.Name)
 		patchOps = append(patchOps, addConfigMapVolume(pod, namePath.Name, volumeName))
 		patchOps = append(patchOps, addConfigMapVolumeMount(pod, volumeName, namePath.Path))
 	}
 	return patchOps
 }
 
+func getConfigMapVolumeName(configMapName string) string {
+	volumeName := configMapName + "-vol"
+	if len(volumeName) > maxNameLength {
+		volumeName = volumeName[0:maxNameLength]
+		glog.V(2).Infof("ConfigMap volume name is too long. Truncating to length %d. Result: %s.", maxNameLength, volumeName)
+	}
+	return volumeName
+}
+
 func addPrometheusConfigMap(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
 	// Skip if Prometheus Monitoring is not enabled or an in-container ConfigFile is used,
 	// in which cases a Prometheus ConfigMap won't be created.
@@ -345,9 +360,37 @@ func addSchedulerName(pod *corev1.Pod, app *v1beta1.SparkApplication) *patchOper
 	if schedulerName == nil || *schedulerName == "" {
 		return nil
 	}
+	if *schedulerName == coscheduling.GetPluginName() {
+		// The coscheduling plugin runs in a secondary scheduler, which can be named per pod.
+		name := coscheduling.DefaultSchedulerName
+		if util.IsDriverPod(pod) && app.Spec.Driver.SchedulerName != nil {
+			name = *app.Spec.Driver.SchedulerName
+		} else if util.IsExecutorPod(pod) && app.Spec.Executor.SchedulerName != nil {
+			name = *app.Spec.Executor.SchedulerName
+		}
+		schedulerName = &name
+	}
 	return &patchOperation{Op: "add", Path: "/spec/schedulerName", Value: *schedulerName}
 }
 
+// addPodGroupLabel adds the label the coscheduling plugin uses to find the PodGroup of a pod.
+func addPodGroupLabel(pod *corev1.Pod, app *v1beta1.SparkApplication) *patchOperation {
+	if app.Spec.BatchScheduler == nil || *app.Spec.BatchScheduler != coscheduling.GetPluginName() {
+		return nil
+	}
+	podGroupName := coscheduling.GetPodGroupName(app)
+	if len(pod.Labels) == 0 {
+		return &patchOperation{
+			Op:    "add",
+			Path:  "/metadata/labels",
+			Value: map[string]string{coscheduling.PodGroupLabel: podGroupName},
+		}
+	}
+	// The slash in the label key has to be escaped in the JSON pointer.
+	path := "/metadata/labels/" + strings.Replace(coscheduling.PodGroupLabel, "/", "~1", -1)
+	return &patchOperation{Op: "add", Path: path, Value: podGroupName}
+}
+
 func addToleration(pod *corev1.Pod, toleration corev1.Toleration) patchOperation {
 	path := "/spec/tolerations"
 	var value interface{}
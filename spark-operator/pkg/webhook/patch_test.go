@@ -29,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/batchscheduler/coscheduling"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
 )
 
@@ -587,6 +588,168 @@ func TestPatchSparkPod_SchedulerName(t *testing.T) {
 	assert.Equal(t, defaultScheduler, modifiedExecutorPod.Spec.SchedulerName)
 }
 
+func TestPatchSparkPod_PodGroupLabel(t *testing.T) {
+	batchScheduler := coscheduling.GetPluginName()
+	executorScheduler := "another_scheduler"
+
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test-patch-podgroup",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			BatchScheduler: &batchScheduler,
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					SchedulerName: &executorScheduler,
+				},
+			},
+		},
+	}
+
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  config.SparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+		},
+	}
+
+	modifiedDriverPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, coscheduling.GetPodGroupName(app), modifiedDriverPod.Labels[coscheduling.PodGroupLabel])
+	assert.Equal(t, coscheduling.DefaultSchedulerName, modifiedDriverPod.Spec.SchedulerName)
+
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  config.SparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+		},
+	}
+
+	modifiedExecutorPod, err := getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, coscheduling.GetPodGroupName(app), modifiedExecutorPod.Labels[coscheduling.PodGroupLabel])
+	assert.Equal(t, config.SparkExecutorRole, modifiedExecutorPod.Labels[config.SparkRoleLabel])
+	assert.Equal(t, executorScheduler, modifiedExecutorPod.Spec.SchedulerName)
+}
+
+func TestPatchSparkPod_PodTemplate(t *testing.T) {
+	priorityClassName := "high-priority"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test-patch-template",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Volumes: []corev1.Volume{{Name: "spark"}},
+			Driver: v1beta1.DriverSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					VolumeMounts: []corev1.VolumeMount{{Name: "spark", MountPath: "/mnt/spark"}},
+					Tolerations:  []corev1.Toleration{{Key: "Key", Operator: "Equal", Value: "Value", Effect: "NoSchedule"}},
+					Template: &corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"team":                "spark",
+								config.SparkRoleLabel: "other",
+							},
+						},
+						Spec: corev1.PodSpec{
+							PriorityClassName: priorityClassName,
+							InitContainers:    []corev1.Container{{Name: "init", Image: "busybox"}},
+							Volumes:           []corev1.Volume{{Name: "cache"}},
+							Containers: []corev1.Container{
+								{
+									Name:  config.SparkDriverContainerName,
+									Image: "ignored:latest",
+									Env:   []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  config.SparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+		},
+	}
+
+	modifiedDriverPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "spark", modifiedDriverPod.Labels["team"])
+	assert.Equal(t, config.SparkDriverRole, modifiedDriverPod.Labels[config.SparkRoleLabel])
+	assert.Equal(t, priorityClassName, modifiedDriverPod.Spec.PriorityClassName)
+	assert.Equal(t, 1, len(modifiedDriverPod.Spec.InitContainers))
+	assert.Equal(t, 1, len(modifiedDriverPod.Spec.Containers))
+	assert.Equal(t, "spark-driver:latest", modifiedDriverPod.Spec.Containers[0].Image)
+	assert.Equal(t, []corev1.EnvVar{{Name: "FOO", Value: "bar"}}, modifiedDriverPod.Spec.Containers[0].Env)
+	// The other patch operations are applied on top of the template.
+	assert.Equal(t, 2, len(modifiedDriverPod.Spec.Volumes))
+	assert.Equal(t, 1, len(modifiedDriverPod.Spec.Containers[0].VolumeMounts))
+	assert.Equal(t, 1, len(modifiedDriverPod.Spec.Tolerations))
+
+	// Only the fields set by the template are patched, rather than the whole spec.
+	_, templateOps, err := addPodTemplate(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make(map[string]bool)
+	for _, op := range templateOps {
+		paths[op.Path] = true
+	}
+	assert.Equal(t, map[string]bool{
+		"/metadata/labels/team":   true,
+		"/spec/priorityClassName": true,
+		"/spec/initContainers":    true,
+		"/spec/volumes":           true,
+		"/spec/containers/0/env":  true,
+	}, paths)
+}
+
 func TestPatchSparkPod_Sidecars(t *testing.T) {
 	app := &v1beta1.SparkApplication{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1109,7 +1272,10 @@ func TestPatchSparkPod_HostNetwork(t *testing.T) {
 }
 
 func getModifiedPod(pod *corev1.Pod, app *v1beta1.SparkApplication) (*corev1.Pod, error) {
-	patchOps := patchSparkPod(pod, app)
+	patchOps, err := patchSparkPod(pod, app)
+	if err != nil {
+		return nil, err
+	}
 	patchBytes, err := json.Marshal(patchOps)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,362 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// addPodTemplate merges the pod template of the driver or executor into the pod. It returns the patch operations
+// for the fields changed by the template, along with the merged pod the other patch operations are to be computed
+// against.
+func addPodTemplate(pod *corev1.Pod, app *v1beta1.SparkApplication) (*corev1.Pod, []patchOperation, error) {
+	var template *corev1.PodTemplateSpec
+	if util.IsDriverPod(pod) {
+		template = app.Spec.Driver.Template
+	} else if util.IsExecutorPod(pod) {
+		template = app.Spec.Executor.Template
+	}
+	if template == nil {
+		return pod, nil, nil
+	}
+
+	merged, err := mergePodTemplate(pod, template)
+	if err != nil {
+		return nil, nil, err
+	}
+	original, err := templatedFields(pod)
+	if err != nil {
+		return nil, nil, err
+	}
+	modified, err := templatedFields(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	return merged, diffPatchOperations("", original, modified), nil
+}
+
+// templatedFields returns the JSON representation of the labels, annotations and spec of the pod, i.e., the fields
+// a pod template is merged into.
+func templatedFields(pod *corev1.Pod) (interface{}, error) {
+	data, err := json.Marshal(corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: pod.Labels, Annotations: pod.Annotations},
+		Spec:       pod.Spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var fields interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// diffPatchOperations returns the patch operations turning the original JSON value at the given path into the
+// modified one, so only the fields that actually differ are patched. Objects are compared member by member. Elements
+// of arrays are matched by their merge key, i.e., their mount path or name, and compared one by one, while the
+// elements the template adds are inserted where the merge put them.
+func diffPatchOperations(path string, original, modified interface{}) []patchOperation {
+	if reflect.DeepEqual(original, modified) {
+		return nil
+	}
+
+	var ops []patchOperation
+	switch modifiedValue := modified.(type) {
+	case map[string]interface{}:
+		originalValue, ok := original.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, value := range modifiedValue {
+			memberPath := path + "/" + escapeJSONPointer(key)
+			if originalMember, ok := originalValue[key]; ok {
+				ops = append(ops, diffPatchOperations(memberPath, originalMember, value)...)
+			} else {
+				ops = append(ops, patchOperation{Op: "add", Path: memberPath, Value: value})
+			}
+		}
+		for key := range originalValue {
+			if _, ok := modifiedValue[key]; !ok {
+				ops = append(ops, patchOperation{Op: "remove", Path: path + "/" + escapeJSONPointer(key)})
+			}
+		}
+		return ops
+	case []interface{}:
+		originalValue, ok := original.([]interface{})
+		if !ok {
+			break
+		}
+		// The operations are applied in order, so the elements before index i are the ones of the modified array and
+		// the element at index i is the next element of the original array.
+		next := 0
+		for i, value := range modifiedValue {
+			elementPath := fmt.Sprintf("%s/%d", path, i)
+			if next < len(originalValue) && isSameElement(originalValue[next], value) {
+				ops = append(ops, diffPatchOperations(elementPath, originalValue[next], value)...)
+				next++
+			} else {
+				ops = append(ops, patchOperation{Op: "add", Path: elementPath, Value: value})
+			}
+		}
+		for i := next; i < len(originalValue); i++ {
+			ops = append(ops, patchOperation{Op: "remove", Path: fmt.Sprintf("%s/%d", path, len(modifiedValue))})
+		}
+		return ops
+	}
+	return []patchOperation{{Op: "replace", Path: path, Value: modified}}
+}
+
+// isSameElement tells if the given array elements are the same element, possibly with different fields.
+func isSameElement(original, modified interface{}) bool {
+	originalValue, ok := original.(map[string]interface{})
+	if !ok {
+		return reflect.DeepEqual(original, modified)
+	}
+	modifiedValue, ok := modified.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, mergeKey := range []string{"mountPath", "name"} {
+		if key, ok := modifiedValue[mergeKey]; ok {
+			return reflect.DeepEqual(originalValue[mergeKey], key)
+		}
+	}
+	return reflect.DeepEqual(original, modified)
+}
+
+// escapeJSONPointer escapes a reference token of a JSON pointer as defined in RFC6901.
+func escapeJSONPointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// mergePodTemplate strategically merges the template into a copy of the pod. Only the labels, annotations and spec of
+// the template are merged. The Spark container stays authoritative for its image, command, arguments, and resources.
+func mergePodTemplate(pod *corev1.Pod, template *corev1.PodTemplateSpec) (*corev1.Pod, error) {
+	original, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := marshalPodTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	mergedJSON, err := strategicpatch.StrategicMergePatch(original, patch, corev1.Pod{})
+	if err != nil {
+		return nil, err
+	}
+	mergedPod := &corev1.Pod{}
+	if err := json.Unmarshal(mergedJSON, mergedPod); err != nil {
+		return nil, err
+	}
+
+	// Labels and annotations already on the pod, e.g., the ones the operator relies on, are kept as they are.
+	merged := pod.DeepCopy()
+	merged.Labels = mergedPod.Labels
+	for key, value := range pod.Labels {
+		merged.Labels[key] = value
+	}
+	merged.Annotations = mergedPod.Annotations
+	for key, value := range pod.Annotations {
+		merged.Annotations[key] = value
+	}
+	merged.Spec = mergedPod.Spec
+	for _, sparkContainer := range pod.Spec.Containers {
+		if !isSparkContainer(&sparkContainer) {
+			continue
+		}
+		for i := range merged.Spec.Containers {
+			container := &merged.Spec.Containers[i]
+			if container.Name == sparkContainer.Name {
+				container.Image = sparkContainer.Image
+				container.Command = sparkContainer.Command
+				container.Args = sparkContainer.Args
+				container.Resources = sparkContainer.Resources
+			}
+		}
+	}
+	return merged, nil
+}
+
+// marshalPodTemplate serializes the template as a patch of a pod. Null values are dropped as they would delete the
+// corresponding fields of the pod, e.g., the containers if the template does not have any.
+func marshalPodTemplate(template *corev1.PodTemplateSpec) ([]byte, error) {
+	data, err := json.Marshal(corev1.Pod{
+		ObjectMeta: template.ObjectMeta,
+		Spec:       template.Spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, err
+	}
+	return json.Marshal(dropNulls(patch))
+}
+
+func dropNulls(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if item == nil {
+				delete(v, key)
+			} else {
+				v[key] = dropNulls(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = dropNulls(item)
+		}
+	}
+	return value
+}
+
+func isSparkContainer(container *corev1.Container) bool {
+	return container.Name == config.SparkDriverContainerName || container.Name == config.SparkExecutorContainerName
+}
+
+// validatePodTemplate rejects pod templates setting fields that are also set by other fields of the SparkPodSpec or
+// of the SparkApplicationSpec it belongs to.
+func validatePodTemplate(
+	spec *v1beta1.SparkPodSpec,
+	app *v1beta1.SparkApplicationSpec,
+	path *field.Path,
+	appPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if spec.Template == nil {
+		return errs
+	}
+
+	templatePath := path.Child("template")
+	conflict := func(templateField *field.Path, specField *field.Path) {
+		errs = append(errs, field.Forbidden(templateField,
+			fmt.Sprintf("conflicts with %s, only one of them can be set", specField)))
+	}
+
+	template := spec.Template
+	specPath := templatePath.Child("spec")
+	if template.Spec.Affinity != nil && spec.Affinity != nil {
+		conflict(specPath.Child("affinity"), path.Child("affinity"))
+	}
+	if len(template.Spec.Tolerations) > 0 && len(spec.Tolerations) > 0 {
+		conflict(specPath.Child("tolerations"), path.Child("tolerations"))
+	}
+	if template.Spec.SecurityContext != nil && spec.SecurityContenxt != nil {
+		conflict(specPath.Child("securityContext"), path.Child("securityContext"))
+	}
+	if template.Spec.SchedulerName != "" && spec.SchedulerName != nil {
+		conflict(specPath.Child("schedulerName"), path.Child("schedulerName"))
+	}
+	if template.Spec.HostNetwork && spec.HostNetwork != nil {
+		conflict(specPath.Child("hostNetwork"), path.Child("hostNetwork"))
+	}
+	if template.Spec.DNSConfig != nil && spec.DNSConfig != nil {
+		conflict(specPath.Child("dnsConfig"), path.Child("dnsConfig"))
+	}
+	if len(template.Spec.NodeSelector) > 0 && (len(spec.NodeSelector) > 0 || len(app.NodeSelector) > 0) {
+		conflict(specPath.Child("nodeSelector"), path.Child("nodeSelector"))
+	}
+	for i, container := range template.Spec.Containers {
+		for _, sidecar := range spec.Sidecars {
+			if container.Name == sidecar.Name {
+				conflict(specPath.Child("containers").Index(i), path.Child("sidecars"))
+			}
+		}
+	}
+
+	// Volumes are merged by name, so the volumes of the template must not share a name with the volumes of the
+	// application or the ones the ConfigMaps and secrets are mounted from.
+	volumeFields := make(map[string]*field.Path)
+	for i, volume := range app.Volumes {
+		volumeFields[volume.Name] = appPath.Child("volumes").Index(i)
+	}
+	for i, configMap := range spec.ConfigMaps {
+		volumeFields[getConfigMapVolumeName(configMap.Name)] = path.Child("configMaps").Index(i)
+	}
+	for i, secret := range spec.Secrets {
+		volumeFields[getSecretVolumeName(secret.Name)] = path.Child("secrets").Index(i)
+	}
+	for i, volume := range template.Spec.Volumes {
+		if specField, ok := volumeFields[volume.Name]; ok {
+			conflict(specPath.Child("volumes").Index(i), specField)
+		}
+	}
+
+	// Volume mounts and environment variables of the Spark container are merged by mount path and name.
+	mountPathFields := make(map[string]*field.Path)
+	for i, mount := range spec.VolumeMounts {
+		mountPathFields[mount.MountPath] = path.Child("volumeMounts").Index(i)
+	}
+	for i, configMap := range spec.ConfigMaps {
+		mountPathFields[configMap.Path] = path.Child("configMaps").Index(i)
+	}
+	for i, secret := range spec.Secrets {
+		mountPathFields[secret.Path] = path.Child("secrets").Index(i)
+	}
+	for i, container := range template.Spec.Containers {
+		if !isSparkContainer(&container) {
+			continue
+		}
+		containerPath := specPath.Child("containers").Index(i)
+		for j, mount := range container.VolumeMounts {
+			if specField, ok := mountPathFields[mount.MountPath]; ok {
+				conflict(containerPath.Child("volumeMounts").Index(j), specField)
+			}
+		}
+		for j, env := range container.Env {
+			if _, ok := spec.EnvVars[env.Name]; ok {
+				conflict(containerPath.Child("env").Index(j), path.Child("envVars").Key(env.Name))
+			}
+			if _, ok := spec.EnvSecretKeyRefs[env.Name]; ok {
+				conflict(containerPath.Child("env").Index(j), path.Child("envSecretKeyRefs").Key(env.Name))
+			}
+		}
+	}
+
+	metadataPath := templatePath.Child("metadata")
+	for key, value := range template.Labels {
+		if specValue, ok := spec.Labels[key]; ok && specValue != value {
+			conflict(metadataPath.Child("labels").Key(key), path.Child("labels"))
+		}
+	}
+	for key, value := range template.Annotations {
+		if specValue, ok := spec.Annotations[key]; ok && specValue != value {
+			conflict(metadataPath.Child("annotations").Key(key), path.Child("annotations"))
+		}
+	}
+
+	return errs
+}
+
+// getSecretVolumeName returns the name of the volume Spark mounts the secret with the given name from.
+func getSecretVolumeName(secretName string) string {
+	return fmt.Sprintf("%s-volume", secretName)
+}
@@ -1,16 +1,14 @@
 package resourceusage
 
 import (
-	"fmt"
 	so "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"math"
-	"regexp"
 	"strconv"
-	"strings"
 )
 
 // ...are you serious, Go?
@@ -69,53 +67,11 @@ func coresRequiredForSparkPod(spec so.SparkPodSpec, instances int64) (int64, err
 	return cpu * instances, nil
 }
 
-var javaStringSuffixes = map[string]int64{
-	"b":  1,
-	"kb": 1 << 10,
-	"k":  1 << 10,
-	"mb": 1 << 20,
-	"m":  1 << 20,
-	"gb": 1 << 30,
-	"g":  1 << 30,
-	"tb": 1 << 40,
-	"t":  1 << 40,
-	"pb": 1 << 50,
-	"p":  1 << 50,
-}
-
-var javaStringPattern = regexp.MustCompile(`([0-9]+)([a-z]+)?`)
-var javaFractionStringPattern = regexp.MustCompile(`([0-9]+\.[0-9]+)([a-z]+)?`)
-
-// Logic copied from https://github.com/apache/spark/blob/5264164a67df498b73facae207eda12ee133be7d/common/network-common/src/main/java/org/apache/spark/network/util/JavaUtils.java#L276
-func parseJavaMemoryString(str string) (int64, error) {
-	lower := strings.ToLower(str)
-	if matches := javaStringPattern.FindStringSubmatch(lower); matches != nil {
-		value, err := strconv.ParseInt(matches[1], 10, 64)
-		if err != nil {
-			return 0, err
-		}
-		suffix := matches[2]
-		if multiplier, present := javaStringSuffixes[suffix]; present {
-			return multiplier * value, nil
-		}
-	} else if matches = javaFractionStringPattern.FindStringSubmatch(lower); matches != nil {
-		value, err := strconv.ParseFloat(matches[1], 64)
-		if err != nil {
-			return 0, err
-		}
-		suffix := matches[2]
-		if multiplier, present := javaStringSuffixes[suffix]; present {
-			return int64(float64(multiplier) * value), nil
-		}
-	}
-	return 0, fmt.Errorf("could not parse string '%s' as a Java-style memory value. Examples: 100kb, 1.5mb, 1g", str)
-}
-
 // Logic copied from https://github.com/apache/spark/blob/c4bbfd177b4e7cb46f47b39df9fd71d2d9a12c6d/resource-managers/kubernetes/core/src/main/scala/org/apache/spark/deploy/k8s/features/BasicDriverFeatureStep.scala
 func memoryRequiredForSparkPod(spec so.SparkPodSpec, memoryOverheadFactor *string, appType so.SparkApplicationType, replicas int64) (int64, error) {
 	var memoryBytes int64
 	if spec.Memory != nil {
-		memory, err := parseJavaMemoryString(*spec.Memory)
+		memory, err := util.ParseJavaMemoryString(*spec.Memory)
 		if err != nil {
 			return 0, err
 		}
@@ -125,7 +81,7 @@ func memoryRequiredForSparkPod(spec so.SparkPodSpec, memoryOverheadFactor *strin
 	}
 	var memoryOverheadBytes int64
 	if spec.MemoryOverhead != nil {
-		overhead, err := parseJavaMemoryString(*spec.MemoryOverhead)
+		overhead, err := util.ParseJavaMemoryString(*spec.MemoryOverhead)
 		if err != nil {
 			return 0, err
 		}
@@ -158,10 +114,7 @@ func resourceUsage(spec so.SparkApplicationSpec) (ResourceList, error) {
 		return ResourceList{}, err
 	}
 
-	var instances int64 = 1
-	if spec.Executor.Instances != nil {
-		instances = int64(*spec.Executor.Instances)
-	}
+	instances := executorInstances(spec)
 	executorMemory, err := memoryRequiredForSparkPod(spec.Executor.SparkPodSpec, executorMemoryOverheadFactor, spec.Type, instances)
 	if err != nil {
 		return ResourceList{}, err
@@ -183,12 +136,40 @@ func resourceUsage(spec so.SparkApplicationSpec) (ResourceList, error) {
 	}, nil
 }
 
+// executorInstances returns the number of executors to charge against the quota. With dynamic allocation, this
+// is the upper bound of the number of executors if there is one, so the application can never exceed the quota.
+func executorInstances(spec so.SparkApplicationSpec) int64 {
+	var instances int64 = 1
+	if spec.Executor.Instances != nil {
+		instances = int64(*spec.Executor.Instances)
+	}
+	if spec.DynamicAllocation == nil || !spec.DynamicAllocation.Enabled {
+		return instances
+	}
+	if spec.DynamicAllocation.MaxExecutors != nil {
+		return int64(*spec.DynamicAllocation.MaxExecutors)
+	}
+	// Without an upper bound, charge for the executors requested up front.
+	if spec.DynamicAllocation.InitialExecutors != nil && int64(*spec.DynamicAllocation.InitialExecutors) > instances {
+		instances = int64(*spec.DynamicAllocation.InitialExecutors)
+	}
+	if spec.DynamicAllocation.MinExecutors != nil && int64(*spec.DynamicAllocation.MinExecutors) > instances {
+		instances = int64(*spec.DynamicAllocation.MinExecutors)
+	}
+	return instances
+}
+
 func sparkApplicationResourceUsage(sparkApp so.SparkApplication) (ResourceList, error) {
 	// A completed/failed SparkApplication consumes no resources
 	if !sparkApp.Status.TerminationTime.IsZero() || sparkApp.Status.AppState.State == so.FailedState || sparkApp.Status.AppState.State == so.CompletedState {
 		return ResourceList{}, nil
 	}
-	return resourceUsage(sparkApp.Spec)
+	// A suspended SparkApplication is not charged until it is resumed.
+	if sparkApp.Spec.Suspend != nil && *sparkApp.Spec.Suspend {
+		return ResourceList{}, nil
+	}
+	// Memory increased after failures of previous attempts is charged instead of the memory in the spec.
+	return resourceUsage(*sparkApp.EffectiveSpec())
 }
 
 func scheduledSparkApplicationResourceUsage(sparkApp so.ScheduledSparkApplication) (ResourceList, error) {
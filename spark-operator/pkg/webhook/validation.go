@@ -0,0 +1,374 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	crdv1beta1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// validateSparkApplications rejects SparkApplications with a spec that is known to fail. Updates that do not
+// change the spec, e.g., finalizer removals, are always admitted so existing objects are never stuck.
+func validateSparkApplications(review *admissionv1beta1.AdmissionReview) (*admissionv1beta1.AdmissionResponse, error) {
+	app, err := decodeSparkApplication(review.Request.Object.Raw, review.Request.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal a SparkApplication from the raw data in the admission request: %v", err)
+	}
+
+	if review.Request.Operation == admissionv1beta1.Update {
+		oldApp, err := decodeSparkApplication(review.Request.OldObject.Raw, review.Request.Resource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the old SparkApplication from the raw data in the admission request: %v", err)
+		}
+		if equality.Semantic.DeepEqual(oldApp.Spec, app.Spec) {
+			return &admissionv1beta1.AdmissionResponse{Allowed: true}, nil
+		}
+	}
+
+	return validationResponse(validateSparkApplicationSpec(&app.Spec, field.NewPath("spec"))), nil
+}
+
+// validateScheduledSparkApplications rejects ScheduledSparkApplications with a template that is known to fail, or
+// with a schedule in an unknown time zone.
+func validateScheduledSparkApplications(review *admissionv1beta1.AdmissionReview) (*admissionv1beta1.AdmissionResponse, error) {
+	app, err := decodeScheduledSparkApplication(review.Request.Object.Raw, review.Request.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal a ScheduledSparkApplication from the raw data in the admission request: %v", err)
+	}
+
+	var oldApp *crdv1beta1.ScheduledSparkApplication
+	if review.Request.Operation == admissionv1beta1.Update {
+		oldApp, err = decodeScheduledSparkApplication(review.Request.OldObject.Raw, review.Request.Resource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the old ScheduledSparkApplication from the raw data in the admission request: %v", err)
+		}
+	}
+
+	// Only the parts of the spec that are changed are validated, so existing objects can still be updated.
+	var errs field.ErrorList
+	if oldApp == nil || oldApp.Spec.Schedule != app.Spec.Schedule || oldApp.Spec.TimeZone != app.Spec.TimeZone {
+		if _, _, err := util.GetScheduleLocation(app.Spec.Schedule, app.Spec.TimeZone); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "timeZone"), app.Spec.TimeZone, err.Error()))
+		}
+	}
+	if oldApp == nil || !equality.Semantic.DeepEqual(oldApp.Spec.StartingDeadlineSeconds, app.Spec.StartingDeadlineSeconds) ||
+		!equality.Semantic.DeepEqual(oldApp.Spec.MissedRunPolicy, app.Spec.MissedRunPolicy) {
+		errs = append(errs, validateMissedRuns(&app.Spec, field.NewPath("spec"))...)
+	}
+	if app.Spec.Backfill != nil &&
+		(oldApp == nil || !equality.Semantic.DeepEqual(oldApp.Spec.Backfill, app.Spec.Backfill)) {
+		errs = append(errs, validateBackfill(app.Spec.Backfill, field.NewPath("spec", "backfill"))...)
+	}
+	if oldApp == nil || !equality.Semantic.DeepEqual(oldApp.Spec.Template, app.Spec.Template) {
+		errs = append(errs, validateSparkApplicationSpec(&app.Spec.Template, field.NewPath("spec", "template"))...)
+	}
+	// Templates in the spec of the runs are only parsed if they are to be expanded, and are validated again when
+	// their expansion is turned on.
+	expandTemplates := app.Spec.ExpandTemplates != nil && *app.Spec.ExpandTemplates
+	expansionChanged := oldApp == nil || !equality.Semantic.DeepEqual(oldApp.Spec.ExpandTemplates, app.Spec.ExpandTemplates)
+	if expandTemplates &&
+		(expansionChanged || !equality.Semantic.DeepEqual(oldApp.Spec.Template, app.Spec.Template)) {
+		errs = append(errs, validateRunTemplates(&app.Spec.Template, field.NewPath("spec", "template"))...)
+	}
+	if expandTemplates && app.Spec.PipelineTemplate != nil &&
+		(expansionChanged || !equality.Semantic.DeepEqual(oldApp.Spec.PipelineTemplate, app.Spec.PipelineTemplate)) {
+		stepsPath := field.NewPath("spec", "pipelineTemplate", "steps")
+		for i := range app.Spec.PipelineTemplate.Steps {
+			errs = append(errs, validateRunTemplates(&app.Spec.PipelineTemplate.Steps[i].Template,
+				stepsPath.Index(i).Child("template"))...)
+		}
+	}
+	return validationResponse(errs), nil
+}
+
+// validateRunTemplates validates the templates in the spec of the runs of a ScheduledSparkApplication.
+func validateRunTemplates(spec *crdv1beta1.SparkApplicationSpec, path *field.Path) field.ErrorList {
+	err := util.ValidateRunTemplates(spec)
+	if err == nil {
+		return nil
+	}
+	if templateErr, ok := err.(*util.RunTemplateError); ok {
+		return field.ErrorList{field.Invalid(path.Child(templateErr.Field), templateErr.Template, templateErr.Err.Error())}
+	}
+	return field.ErrorList{field.Invalid(path, "", err.Error())}
+}
+
+func validateMissedRuns(spec *crdv1beta1.ScheduledSparkApplicationSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if spec.StartingDeadlineSeconds != nil && *spec.StartingDeadlineSeconds <= 0 {
+		errs = append(errs, field.Invalid(path.Child("startingDeadlineSeconds"), *spec.StartingDeadlineSeconds,
+			"must be positive"))
+	}
+	if policy := spec.MissedRunPolicy; policy != nil {
+		policyPath := path.Child("missedRunPolicy")
+		switch policy.Type {
+		case crdv1beta1.MissedRunSkip, crdv1beta1.MissedRunRunOnce, crdv1beta1.MissedRunRunAll:
+		default:
+			errs = append(errs, field.NotSupported(policyPath.Child("type"), policy.Type, []string{
+				string(crdv1beta1.MissedRunSkip), string(crdv1beta1.MissedRunRunOnce), string(crdv1beta1.MissedRunRunAll)}))
+		}
+		if policy.MaxRuns != nil && *policy.MaxRuns <= 0 {
+			errs = append(errs, field.Invalid(policyPath.Child("maxRuns"), *policy.MaxRuns, "must be positive"))
+		}
+	}
+
+	return errs
+}
+
+func validateBackfill(backfill *crdv1beta1.BackfillSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if backfill.StartTime.IsZero() {
+		errs = append(errs, field.Required(path.Child("startTime"), ""))
+	}
+	if backfill.EndTime.IsZero() {
+		errs = append(errs, field.Required(path.Child("endTime"), ""))
+	} else if backfill.EndTime.Before(&backfill.StartTime) {
+		errs = append(errs, field.Invalid(path.Child("endTime"), backfill.EndTime.Format(time.RFC3339),
+			"must not be before startTime"))
+	}
+	if backfill.MaxParallel != nil && *backfill.MaxParallel <= 0 {
+		errs = append(errs, field.Invalid(path.Child("maxParallel"), *backfill.MaxParallel, "must be positive"))
+	}
+
+	return errs
+}
+
+func validationResponse(errs field.ErrorList) *admissionv1beta1.AdmissionResponse {
+	response := &admissionv1beta1.AdmissionResponse{Allowed: len(errs) == 0}
+	if len(errs) > 0 {
+		response.Result = &metav1.Status{
+			Message: errs.ToAggregate().Error(),
+			Code:    400,
+		}
+	}
+	return response
+}
+
+func validateSparkApplicationSpec(spec *crdv1beta1.SparkApplicationSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if spec.NodeSelector != nil && (spec.Driver.NodeSelector != nil || spec.Executor.NodeSelector != nil) {
+		errs = append(errs, field.Forbidden(path.Child("nodeSelector"),
+			"nodeSelector can be defined at the SparkApplication level or for the driver and executors, but not both"))
+	}
+
+	if spec.Mode == crdv1beta1.InClusterClientMode && (spec.Driver.PodName == nil || *spec.Driver.PodName == "") {
+		errs = append(errs, field.Required(path.Child("driver", "podName"),
+			fmt.Sprintf("the driver pod name is required in %s mode", crdv1beta1.InClusterClientMode)))
+	}
+
+	if spec.DynamicAllocation != nil {
+		errs = append(errs, validateDynamicAllocation(spec.DynamicAllocation, path.Child("dynamicAllocation"))...)
+	}
+
+	errs = append(errs, validateRestartPolicy(&spec.RestartPolicy, path.Child("restartPolicy"))...)
+
+	if spec.TimeToLiveBeforeRunning != nil && *spec.TimeToLiveBeforeRunning <= 0 {
+		errs = append(errs, field.Invalid(path.Child("timeToLiveBeforeRunning"), *spec.TimeToLiveBeforeRunning,
+			"must be positive"))
+	}
+	if spec.ActiveDeadlineSeconds != nil && *spec.ActiveDeadlineSeconds <= 0 {
+		errs = append(errs, field.Invalid(path.Child("activeDeadlineSeconds"), *spec.ActiveDeadlineSeconds,
+			"must be positive"))
+	}
+	if spec.TimeToLiveSecondsAfterFinished != nil && *spec.TimeToLiveSecondsAfterFinished < 0 {
+		errs = append(errs, field.Invalid(path.Child("timeToLiveSecondsAfterFinished"),
+			*spec.TimeToLiveSecondsAfterFinished, "must not be negative"))
+	}
+	if spec.UnrecoverablePendingPolicy != nil && spec.UnrecoverablePendingPolicy.FailAfterMinutes <= 0 {
+		errs = append(errs, field.Invalid(path.Child("unrecoverablePendingPolicy", "failAfterMinutes"),
+			spec.UnrecoverablePendingPolicy.FailAfterMinutes, "must be positive"))
+	}
+	if spec.CompactExecutorStatus != nil && spec.CompactExecutorStatus.MaxFailedExecutorDetails != nil &&
+		*spec.CompactExecutorStatus.MaxFailedExecutorDetails < 0 {
+		errs = append(errs, field.Invalid(path.Child("compactExecutorStatus", "maxFailedExecutorDetails"),
+			*spec.CompactExecutorStatus.MaxFailedExecutorDetails, "must not be negative"))
+	}
+
+	if spec.Executor.MaxFailures != nil && *spec.Executor.MaxFailures < 0 {
+		errs = append(errs, field.Invalid(path.Child("executor", "maxFailures"), *spec.Executor.MaxFailures,
+			"must not be negative"))
+	}
+	if spec.Executor.FailureWindowSeconds != nil {
+		if *spec.Executor.FailureWindowSeconds <= 0 {
+			errs = append(errs, field.Invalid(path.Child("executor", "failureWindowSeconds"),
+				*spec.Executor.FailureWindowSeconds, "must be positive"))
+		} else if spec.Executor.MaxFailures == nil {
+			errs = append(errs, field.Required(path.Child("executor", "maxFailures"),
+				"maxFailures is required with failureWindowSeconds"))
+		}
+	}
+
+	volumes := make(map[string]bool)
+	for _, volume := range spec.Volumes {
+		volumes[volume.Name] = true
+	}
+	errs = append(errs, validateSparkPodSpec(&spec.Driver.SparkPodSpec, volumes, path.Child("driver"))...)
+	errs = append(errs, validateSparkPodSpec(&spec.Executor.SparkPodSpec, volumes, path.Child("executor"))...)
+	errs = append(errs, validatePodTemplate(&spec.Driver.SparkPodSpec, spec, path.Child("driver"), path)...)
+	errs = append(errs, validatePodTemplate(&spec.Executor.SparkPodSpec, spec, path.Child("executor"), path)...)
+
+	return errs
+}
+
+func validateDynamicAllocation(dynamicAllocation *crdv1beta1.DynamicAllocation, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	bounds := []struct {
+		name  string
+		value *int32
+	}{
+		{"initialExecutors", dynamicAllocation.InitialExecutors},
+		{"minExecutors", dynamicAllocation.MinExecutors},
+		{"maxExecutors", dynamicAllocation.MaxExecutors},
+	}
+	for _, bound := range bounds {
+		if bound.value != nil && *bound.value < 0 {
+			errs = append(errs, field.Invalid(path.Child(bound.name), *bound.value, "must not be negative"))
+		}
+	}
+
+	min, max, initial := dynamicAllocation.MinExecutors, dynamicAllocation.MaxExecutors, dynamicAllocation.InitialExecutors
+	if min != nil && max != nil && *min > *max {
+		errs = append(errs, field.Invalid(path.Child("minExecutors"), *min,
+			"the minimum number of executors must not be greater than the maximum number of executors"))
+	}
+	if initial != nil && min != nil && *initial < *min {
+		errs = append(errs, field.Invalid(path.Child("initialExecutors"), *initial,
+			"the initial number of executors must not be less than the minimum number of executors"))
+	}
+	if initial != nil && max != nil && *initial > *max {
+		errs = append(errs, field.Invalid(path.Child("initialExecutors"), *initial,
+			"the initial number of executors must not be greater than the maximum number of executors"))
+	}
+
+	return errs
+}
+
+func validateRestartPolicy(restartPolicy *crdv1beta1.RestartPolicy, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if backoff := restartPolicy.Backoff; backoff != nil {
+		backoffPath := path.Child("backoff")
+		if backoff.InitialIntervalSeconds <= 0 {
+			errs = append(errs, field.Invalid(backoffPath.Child("initialIntervalSeconds"), backoff.InitialIntervalSeconds,
+				"the initial interval must be positive"))
+		}
+		if backoff.Multiplier != nil && *backoff.Multiplier < 1 {
+			errs = append(errs, field.Invalid(backoffPath.Child("multiplier"), *backoff.Multiplier,
+				"the multiplier must not be less than 1"))
+		}
+		if backoff.MaxIntervalSeconds != nil && *backoff.MaxIntervalSeconds < backoff.InitialIntervalSeconds {
+			errs = append(errs, field.Invalid(backoffPath.Child("maxIntervalSeconds"), *backoff.MaxIntervalSeconds,
+				"the maximum interval must not be less than the initial interval"))
+		}
+		if backoff.JitterFactor != nil && (*backoff.JitterFactor < 0 || *backoff.JitterFactor >= 1) {
+			errs = append(errs, field.Invalid(backoffPath.Child("jitterFactor"), *backoff.JitterFactor,
+				"the jitter factor must be in [0, 1)"))
+		}
+	}
+
+	if policy := restartPolicy.MemoryIncreaseOnOOM; policy != nil {
+		policyPath := path.Child("memoryIncreaseOnOOM")
+		if policy.Factor <= 1 {
+			errs = append(errs, field.Invalid(policyPath.Child("factor"), policy.Factor,
+				"the memory increase factor must be greater than 1"))
+		}
+		if policy.MaxMemory != nil {
+			if _, err := util.ParseJavaMemoryString(*policy.MaxMemory); err != nil {
+				errs = append(errs, field.Invalid(policyPath.Child("maxMemory"), *policy.MaxMemory, err.Error()))
+			}
+		}
+	}
+
+	for i, rule := range restartPolicy.FailureRules {
+		rulePath := path.Child("failureRules").Index(i)
+		if len(rule.Reasons) == 0 && len(rule.ExitCodes) == 0 {
+			errs = append(errs, field.Required(rulePath, "at least one reason or exit code range is required"))
+		}
+		for j, codes := range rule.ExitCodes {
+			if codes.Max != nil && *codes.Max < codes.Min {
+				errs = append(errs, field.Invalid(rulePath.Child("exitCodes").Index(j).Child("max"), *codes.Max,
+					"the maximum exit code must not be less than the minimum exit code"))
+			}
+		}
+		switch rule.Action {
+		case crdv1beta1.FailureActionNeverRetry:
+		case crdv1beta1.FailureActionRetry, crdv1beta1.FailureActionRetryWithMoreMemory:
+			if restartPolicy.OnFailureRetries == nil {
+				errs = append(errs, field.Required(path.Child("onFailureRetries"),
+					fmt.Sprintf("the number of retries is required by failure rules with action %s", rule.Action)))
+			}
+		default:
+			errs = append(errs, field.NotSupported(rulePath.Child("action"), rule.Action, []string{
+				string(crdv1beta1.FailureActionRetry),
+				string(crdv1beta1.FailureActionNeverRetry),
+				string(crdv1beta1.FailureActionRetryWithMoreMemory),
+			}))
+		}
+		if rule.MemoryIncreaseFactor != nil {
+			if rule.Action != crdv1beta1.FailureActionRetryWithMoreMemory {
+				errs = append(errs, field.Forbidden(rulePath.Child("memoryIncreaseFactor"),
+					fmt.Sprintf("only supported by the action %s", crdv1beta1.FailureActionRetryWithMoreMemory)))
+			} else if *rule.MemoryIncreaseFactor <= 1 {
+				errs = append(errs, field.Invalid(rulePath.Child("memoryIncreaseFactor"), *rule.MemoryIncreaseFactor,
+					"the memory increase factor must be greater than 1"))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateSparkPodSpec(spec *crdv1beta1.SparkPodSpec, volumes map[string]bool, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if spec.Memory != nil {
+		if _, err := util.ParseJavaMemoryString(*spec.Memory); err != nil {
+			errs = append(errs, field.Invalid(path.Child("memory"), *spec.Memory, err.Error()))
+		}
+	}
+
+	for i, mount := range spec.VolumeMounts {
+		if !volumes[mount.Name] {
+			errs = append(errs, field.NotFound(path.Child("volumeMounts").Index(i).Child("name"), mount.Name))
+		}
+	}
+
+	if spec.GPU != nil {
+		if spec.GPU.Name == "" {
+			errs = append(errs, field.Required(path.Child("gpu", "name"),
+				"the GPU resource name, e.g., nvidia.com/gpu, is required"))
+		}
+		if spec.GPU.Quantity <= 0 {
+			errs = append(errs, field.Invalid(path.Child("gpu", "quantity"), spec.GPU.Quantity,
+				"the GPU quantity must be positive"))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,657 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	spov1alpha1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1alpha1"
+	spov1beta1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func newValidationReview(t *testing.T, operation admissionv1beta1.Operation, app, oldApp *spov1beta1.SparkApplication) *admissionv1beta1.AdmissionReview {
+	raw, err := json.Marshal(app)
+	assert.Nil(t, err)
+	review := &admissionv1beta1.AdmissionReview{
+		Request: &admissionv1beta1.AdmissionRequest{
+			Resource:  sparkApplicationResource,
+			Operation: operation,
+			Object:    runtime.RawExtension{Raw: raw},
+			Namespace: "default",
+		},
+	}
+	if oldApp != nil {
+		oldRaw, err := json.Marshal(oldApp)
+		assert.Nil(t, err)
+		review.Request.OldObject = runtime.RawExtension{Raw: oldRaw}
+	}
+	return review
+}
+
+func TestValidateSparkApplications(t *testing.T) {
+	memory := "512m"
+	badMemory := "lots"
+	podName := "foo-driver"
+	one := int32(1)
+	two := int32(2)
+	five := int32(5)
+	multiplier := 2.0
+	zero := int64(0)
+	negative := int64(-1)
+	negativeFailures := int32(-1)
+	window := int64(600)
+
+	type testcase struct {
+		name           string
+		spec           spov1beta1.SparkApplicationSpec
+		expectedErrors []string
+	}
+
+	testcases := []testcase{
+		{
+			name: "valid",
+			spec: spov1beta1.SparkApplicationSpec{
+				Mode:    spov1beta1.InClusterClientMode,
+				Volumes: []corev1.Volume{{Name: "spark"}},
+				Driver: spov1beta1.DriverSpec{
+					SparkPodSpec: spov1beta1.SparkPodSpec{
+						Memory:       &memory,
+						VolumeMounts: []corev1.VolumeMount{{Name: "spark", MountPath: "/mnt/spark"}},
+						GPU:          &spov1beta1.GPUSpec{Name: "nvidia.com/gpu", Quantity: 1},
+					},
+					PodName: &podName,
+				},
+			},
+		},
+		{
+			name: "invalid memory",
+			spec: spov1beta1.SparkApplicationSpec{
+				Executor: spov1beta1.ExecutorSpec{SparkPodSpec: spov1beta1.SparkPodSpec{Memory: &badMemory}},
+			},
+			expectedErrors: []string{"spec.executor.memory"},
+		},
+		{
+			name:           "in-cluster-client mode without driver pod name",
+			spec:           spov1beta1.SparkApplicationSpec{Mode: spov1beta1.InClusterClientMode},
+			expectedErrors: []string{"spec.driver.podName"},
+		},
+		{
+			name: "missing volume",
+			spec: spov1beta1.SparkApplicationSpec{
+				Volumes: []corev1.Volume{{Name: "spark"}},
+				Executor: spov1beta1.ExecutorSpec{
+					SparkPodSpec: spov1beta1.SparkPodSpec{
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "spark", MountPath: "/mnt/spark"},
+							{Name: "data", MountPath: "/mnt/data"},
+						},
+					},
+				},
+			},
+			expectedErrors: []string{"spec.executor.volumeMounts[1].name"},
+		},
+		{
+			name: "zero GPU quantity",
+			spec: spov1beta1.SparkApplicationSpec{
+				Driver: spov1beta1.DriverSpec{
+					SparkPodSpec: spov1beta1.SparkPodSpec{GPU: &spov1beta1.GPUSpec{Name: "nvidia.com/gpu"}},
+				},
+			},
+			expectedErrors: []string{"spec.driver.gpu.quantity"},
+		},
+		{
+			name: "conflicting node selectors",
+			spec: spov1beta1.SparkApplicationSpec{
+				NodeSelector: map[string]string{"foo": "bar"},
+				Driver: spov1beta1.DriverSpec{
+					SparkPodSpec: spov1beta1.SparkPodSpec{NodeSelector: map[string]string{"foo": "bar"}},
+				},
+			},
+			expectedErrors: []string{"spec.nodeSelector"},
+		},
+		{
+			name: "pod template conflicting with explicit fields",
+			spec: spov1beta1.SparkApplicationSpec{
+				Executor: spov1beta1.ExecutorSpec{
+					SparkPodSpec: spov1beta1.SparkPodSpec{
+						Tolerations: []corev1.Toleration{{Key: "foo"}},
+						Labels:      map[string]string{"team": "spark"},
+						Template: &corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "ml"}},
+							Spec: corev1.PodSpec{
+								PriorityClassName: "high-priority",
+								Tolerations:       []corev1.Toleration{{Key: "bar"}},
+							},
+						},
+					},
+				},
+			},
+			expectedErrors: []string{"spec.executor.template.spec.tolerations", "spec.executor.template.metadata.labels[team]"},
+		},
+		{
+			name: "pod template conflicting with volumes and environment variables",
+			spec: spov1beta1.SparkApplicationSpec{
+				Volumes: []corev1.Volume{{Name: "data"}},
+				Driver: spov1beta1.DriverSpec{
+					SparkPodSpec: spov1beta1.SparkPodSpec{
+						ConfigMaps:       []spov1beta1.NamePath{{Name: "conf", Path: "/etc/conf"}},
+						Secrets:          []spov1beta1.SecretInfo{{Name: "creds", Path: "/etc/creds"}},
+						EnvVars:          map[string]string{"FOO": "foo"},
+						EnvSecretKeyRefs: map[string]spov1beta1.NameKey{"BAR": {Name: "creds", Key: "bar"}},
+						Template: &corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Volumes: []corev1.Volume{{Name: "data"}, {Name: "conf-vol"}, {Name: "creds-volume"}, {Name: "cache"}},
+								Containers: []corev1.Container{{
+									Name:         config.SparkDriverContainerName,
+									VolumeMounts: []corev1.VolumeMount{{Name: "cache", MountPath: "/etc/creds"}},
+									Env:          []corev1.EnvVar{{Name: "FOO", Value: "bar"}, {Name: "BAR", Value: "foo"}},
+								}},
+							},
+						},
+					},
+				},
+			},
+			expectedErrors: []string{
+				"spec.driver.template.spec.volumes[0]",
+				"spec.driver.template.spec.volumes[1]",
+				"spec.driver.template.spec.volumes[2]",
+				"spec.driver.template.spec.containers[0].volumeMounts[0]",
+				"spec.driver.template.spec.containers[0].env[0]",
+				"spec.driver.template.spec.containers[0].env[1]",
+			},
+		},
+		{
+			name: "pod template with separate volumes and environment variables",
+			spec: spov1beta1.SparkApplicationSpec{
+				Volumes: []corev1.Volume{{Name: "data"}},
+				Executor: spov1beta1.ExecutorSpec{
+					SparkPodSpec: spov1beta1.SparkPodSpec{
+						VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+						EnvVars:      map[string]string{"FOO": "foo"},
+						Template: &corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Volumes: []corev1.Volume{{Name: "cache"}},
+								Containers: []corev1.Container{
+									{
+										Name:         config.SparkExecutorContainerName,
+										VolumeMounts: []corev1.VolumeMount{{Name: "cache", MountPath: "/cache"}},
+										Env:          []corev1.EnvVar{{Name: "BAR", Value: "bar"}},
+									},
+									{Name: "sidecar", Env: []corev1.EnvVar{{Name: "FOO", Value: "bar"}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "valid dynamic allocation",
+			spec: spov1beta1.SparkApplicationSpec{
+				DynamicAllocation: &spov1beta1.DynamicAllocation{
+					Enabled:          true,
+					InitialExecutors: &two,
+					MinExecutors:     &one,
+					MaxExecutors:     &five,
+				},
+			},
+		},
+		{
+			name: "dynamic allocation with min greater than max",
+			spec: spov1beta1.SparkApplicationSpec{
+				DynamicAllocation: &spov1beta1.DynamicAllocation{
+					Enabled:      true,
+					MinExecutors: &five,
+					MaxExecutors: &two,
+				},
+			},
+			expectedErrors: []string{"spec.dynamicAllocation.minExecutors"},
+		},
+		{
+			name: "dynamic allocation with initial out of bounds",
+			spec: spov1beta1.SparkApplicationSpec{
+				DynamicAllocation: &spov1beta1.DynamicAllocation{
+					Enabled:          true,
+					InitialExecutors: &five,
+					MinExecutors:     &one,
+					MaxExecutors:     &two,
+				},
+			},
+			expectedErrors: []string{"spec.dynamicAllocation.initialExecutors"},
+		},
+		{
+			name: "non-positive deadlines",
+			spec: spov1beta1.SparkApplicationSpec{
+				TimeToLiveBeforeRunning: &zero,
+				ActiveDeadlineSeconds:   &negative,
+			},
+			expectedErrors: []string{"spec.timeToLiveBeforeRunning", "spec.activeDeadlineSeconds"},
+		},
+		{
+			name: "zero TTL after finished",
+			spec: spov1beta1.SparkApplicationSpec{TimeToLiveSecondsAfterFinished: &zero},
+		},
+		{
+			name:           "negative TTL after finished",
+			spec:           spov1beta1.SparkApplicationSpec{TimeToLiveSecondsAfterFinished: &negative},
+			expectedErrors: []string{"spec.timeToLiveSecondsAfterFinished"},
+		},
+		{
+			name: "valid unrecoverable pending policy",
+			spec: spov1beta1.SparkApplicationSpec{
+				UnrecoverablePendingPolicy: &spov1beta1.UnrecoverablePendingPolicy{FailAfterMinutes: 5},
+			},
+		},
+		{
+			name: "non-positive unrecoverable pending timeout",
+			spec: spov1beta1.SparkApplicationSpec{
+				UnrecoverablePendingPolicy: &spov1beta1.UnrecoverablePendingPolicy{Reasons: []string{"ErrImagePull"}},
+			},
+			expectedErrors: []string{"spec.unrecoverablePendingPolicy.failAfterMinutes"},
+		},
+		{
+			name: "valid compact executor status",
+			spec: spov1beta1.SparkApplicationSpec{
+				CompactExecutorStatus: &spov1beta1.CompactExecutorStatus{MaxFailedExecutorDetails: &one},
+			},
+		},
+		{
+			name: "negative failed executor details",
+			spec: spov1beta1.SparkApplicationSpec{
+				CompactExecutorStatus: &spov1beta1.CompactExecutorStatus{MaxFailedExecutorDetails: &negativeFailures},
+			},
+			expectedErrors: []string{"spec.compactExecutorStatus.maxFailedExecutorDetails"},
+		},
+		{
+			name: "valid executor failure budget",
+			spec: spov1beta1.SparkApplicationSpec{
+				Executor: spov1beta1.ExecutorSpec{MaxFailures: &one, FailureWindowSeconds: &window},
+			},
+		},
+		{
+			name: "invalid executor failure budget",
+			spec: spov1beta1.SparkApplicationSpec{
+				Executor: spov1beta1.ExecutorSpec{MaxFailures: &negativeFailures, FailureWindowSeconds: &zero},
+			},
+			expectedErrors: []string{"spec.executor.maxFailures", "spec.executor.failureWindowSeconds"},
+		},
+		{
+			name:           "executor failure window without budget",
+			spec:           spov1beta1.SparkApplicationSpec{Executor: spov1beta1.ExecutorSpec{FailureWindowSeconds: &window}},
+			expectedErrors: []string{"spec.executor.maxFailures"},
+		},
+		{
+			name: "valid restart policy",
+			spec: spov1beta1.SparkApplicationSpec{
+				RestartPolicy: spov1beta1.RestartPolicy{
+					Type:                spov1beta1.Never,
+					OnFailureRetries:    &two,
+					Backoff:             &spov1beta1.BackoffPolicy{InitialIntervalSeconds: 10, Multiplier: &multiplier},
+					MemoryIncreaseOnOOM: &spov1beta1.MemoryIncreasePolicy{Factor: multiplier, MaxMemory: &memory},
+					FailureRules: []spov1beta1.FailureRule{
+						{Reasons: []string{"OOMKilled"}, Action: spov1beta1.FailureActionRetryWithMoreMemory, MemoryIncreaseFactor: &multiplier},
+						{ExitCodes: []spov1beta1.ExitCodeRange{{Min: 1, Max: &five}}, Action: spov1beta1.FailureActionNeverRetry},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid restart policy",
+			spec: spov1beta1.SparkApplicationSpec{
+				RestartPolicy: spov1beta1.RestartPolicy{
+					Type:                spov1beta1.OnFailure,
+					Backoff:             &spov1beta1.BackoffPolicy{InitialIntervalSeconds: 10, JitterFactor: &multiplier},
+					MemoryIncreaseOnOOM: &spov1beta1.MemoryIncreasePolicy{Factor: 1, MaxMemory: &badMemory},
+					FailureRules: []spov1beta1.FailureRule{
+						{Reasons: []string{"Evicted"}, Action: spov1beta1.FailureActionRetry},
+						{Action: spov1beta1.FailureActionNeverRetry, MemoryIncreaseFactor: &multiplier},
+						{ExitCodes: []spov1beta1.ExitCodeRange{{Min: 5, Max: &one}}, Action: "Ignore"},
+					},
+				},
+			},
+			expectedErrors: []string{
+				"spec.restartPolicy.backoff.jitterFactor",
+				"spec.restartPolicy.memoryIncreaseOnOOM.factor",
+				"spec.restartPolicy.memoryIncreaseOnOOM.maxMemory",
+				"spec.restartPolicy.onFailureRetries",
+				"spec.restartPolicy.failureRules[1]",
+				"spec.restartPolicy.failureRules[1].memoryIncreaseFactor",
+				"spec.restartPolicy.failureRules[2].exitCodes[0].max",
+				"spec.restartPolicy.failureRules[2].action",
+			},
+		},
+	}
+
+	for _, test := range testcases {
+		app := &spov1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec:       test.spec,
+		}
+		response, err := validateSparkApplications(newValidationReview(t, admissionv1beta1.Create, app, nil))
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, len(test.expectedErrors) == 0, response.Allowed, test.name)
+		for _, expected := range test.expectedErrors {
+			assert.True(t, strings.Contains(response.Result.Message, expected), "%s: %s", test.name, response.Result.Message)
+		}
+	}
+}
+
+func TestValidateSparkApplicationsUpdate(t *testing.T) {
+	badMemory := "lots"
+	oldApp := &spov1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: spov1beta1.SparkApplicationSpec{
+			Driver: spov1beta1.DriverSpec{SparkPodSpec: spov1beta1.SparkPodSpec{Memory: &badMemory}},
+		},
+	}
+
+	// Updates not changing the spec are admitted even if the spec is invalid.
+	app := oldApp.DeepCopy()
+	app.Finalizers = []string{"foo"}
+	response, err := validateSparkApplications(newValidationReview(t, admissionv1beta1.Update, app, oldApp))
+	assert.Nil(t, err)
+	assert.True(t, response.Allowed)
+
+	app.Spec.Arguments = []string{"1000"}
+	response, err = validateSparkApplications(newValidationReview(t, admissionv1beta1.Update, app, oldApp))
+	assert.Nil(t, err)
+	assert.False(t, response.Allowed)
+}
+
+func TestValidateScheduledSparkApplications(t *testing.T) {
+	badMemory := "lots"
+	deadline := int64(300)
+	zeroDeadline := int64(0)
+	maxRuns := int32(3)
+	zeroMaxRuns := int32(0)
+	expandTemplates := true
+
+	type testcase struct {
+		name           string
+		spec           spov1beta1.ScheduledSparkApplicationSpec
+		expectedErrors []string
+	}
+
+	testcases := []testcase{
+		{
+			name: "no time zone",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{Schedule: "0 2 * * *"},
+		},
+		{
+			name: "time zone",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{Schedule: "0 2 * * *", TimeZone: "America/New_York"},
+		},
+		{
+			name: "time zone prefix",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{Schedule: "CRON_TZ=Europe/Berlin 0 2 * * *"},
+		},
+		{
+			name: "matching time zone and prefix",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule: "TZ=Europe/Berlin 0 2 * * *",
+				TimeZone: "Europe/Berlin",
+			},
+		},
+		{
+			name:           "unknown time zone",
+			spec:           spov1beta1.ScheduledSparkApplicationSpec{Schedule: "0 2 * * *", TimeZone: "Mars/Olympus_Mons"},
+			expectedErrors: []string{"spec.timeZone"},
+		},
+		{
+			name:           "unknown time zone prefix",
+			spec:           spov1beta1.ScheduledSparkApplicationSpec{Schedule: "CRON_TZ=Mars/Olympus_Mons 0 2 * * *"},
+			expectedErrors: []string{"spec.timeZone"},
+		},
+		{
+			name: "conflicting time zone and prefix",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule: "CRON_TZ=Europe/Berlin 0 2 * * *",
+				TimeZone: "America/New_York",
+			},
+			expectedErrors: []string{"spec.timeZone"},
+		},
+		{
+			name: "missed runs",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule:                "0 2 * * *",
+				StartingDeadlineSeconds: &deadline,
+				MissedRunPolicy:         &spov1beta1.MissedRunPolicy{Type: spov1beta1.MissedRunRunAll, MaxRuns: &maxRuns},
+			},
+		},
+		{
+			name: "invalid missed runs",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule:                "0 2 * * *",
+				StartingDeadlineSeconds: &zeroDeadline,
+				MissedRunPolicy:         &spov1beta1.MissedRunPolicy{Type: "RunTwice", MaxRuns: &zeroMaxRuns},
+			},
+			expectedErrors: []string{
+				"spec.startingDeadlineSeconds",
+				"spec.missedRunPolicy.type",
+				"spec.missedRunPolicy.maxRuns",
+			},
+		},
+		{
+			name: "invalid template",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule: "0 2 * * *",
+				Template: spov1beta1.SparkApplicationSpec{
+					Driver: spov1beta1.DriverSpec{SparkPodSpec: spov1beta1.SparkPodSpec{Memory: &badMemory}},
+				},
+			},
+			expectedErrors: []string{"spec.template.driver.memory"},
+		},
+		{
+			name: "backfill",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule: "0 2 * * *",
+				Backfill: &spov1beta1.BackfillSpec{
+					StartTime:   metav1.NewTime(time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)),
+					EndTime:     metav1.NewTime(time.Date(2019, 5, 14, 0, 0, 0, 0, time.UTC)),
+					MaxParallel: &maxRuns,
+				},
+			},
+		},
+		{
+			name: "invalid backfill",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule: "0 2 * * *",
+				Backfill: &spov1beta1.BackfillSpec{
+					StartTime:   metav1.NewTime(time.Date(2019, 5, 14, 0, 0, 0, 0, time.UTC)),
+					EndTime:     metav1.NewTime(time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)),
+					MaxParallel: &zeroMaxRuns,
+				},
+			},
+			expectedErrors: []string{"spec.backfill.endTime", "spec.backfill.maxParallel"},
+		},
+		{
+			name: "backfill without time range",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule: "0 2 * * *",
+				Backfill: &spov1beta1.BackfillSpec{},
+			},
+			expectedErrors: []string{"spec.backfill.startTime", "spec.backfill.endTime"},
+		},
+		{
+			name: "templated run",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule: "0 2 * * *",
+				Template: spov1beta1.SparkApplicationSpec{
+					Arguments: []string{"--date={{date .ScheduledTime}}"},
+				},
+				ExpandTemplates: &expandTemplates,
+			},
+		},
+		{
+			name: "literal braces without template expansion",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule: "0 2 * * *",
+				Template: spov1beta1.SparkApplicationSpec{
+					SparkConf: map[string]string{"spark.executor.extraJavaOptions": "-Dapp={{APP_ID}}"},
+				},
+			},
+		},
+		{
+			name: "invalid run templates",
+			spec: spov1beta1.ScheduledSparkApplicationSpec{
+				Schedule: "0 2 * * *",
+				Template: spov1beta1.SparkApplicationSpec{
+					SparkConf: map[string]string{"spark.foo": "{{yesterday}}"},
+				},
+				PipelineTemplate: &spov1beta1.SparkPipelineSpec{
+					Steps: []spov1beta1.PipelineStep{
+						{Name: "extract"},
+						{
+							Name:     "load",
+							Template: spov1beta1.SparkApplicationSpec{Arguments: []string{"{{date .ScheduledTime"}},
+						},
+					},
+				},
+				ExpandTemplates: &expandTemplates,
+			},
+			expectedErrors: []string{
+				"spec.template.sparkConf[spark.foo]",
+				"spec.pipelineTemplate.steps[1].template.arguments[0]",
+			},
+		},
+	}
+
+	for _, test := range testcases {
+		app := &spov1beta1.ScheduledSparkApplication{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec:       test.spec,
+		}
+		response, err := validateScheduledSparkApplications(newScheduledValidationReview(t, admissionv1beta1.Create, app, nil))
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, len(test.expectedErrors) == 0, response.Allowed, test.name)
+		for _, expected := range test.expectedErrors {
+			assert.True(t, strings.Contains(response.Result.Message, expected), "%s: %s", test.name, response.Result.Message)
+		}
+	}
+}
+
+func TestValidateScheduledSparkApplicationsUpdate(t *testing.T) {
+	oldApp := &spov1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       spov1beta1.ScheduledSparkApplicationSpec{Schedule: "0 2 * * *", TimeZone: "Mars/Olympus_Mons"},
+	}
+
+	// Updates not changing the schedule are admitted even if its time zone is unknown.
+	app := oldApp.DeepCopy()
+	suspend := true
+	app.Spec.Suspend = &suspend
+	response, err := validateScheduledSparkApplications(newScheduledValidationReview(t, admissionv1beta1.Update, app, oldApp))
+	assert.Nil(t, err)
+	assert.True(t, response.Allowed)
+
+	app.Spec.Schedule = "0 3 * * *"
+	response, err = validateScheduledSparkApplications(newScheduledValidationReview(t, admissionv1beta1.Update, app, oldApp))
+	assert.Nil(t, err)
+	assert.False(t, response.Allowed)
+}
+
+func TestValidateV1alpha1Objects(t *testing.T) {
+	badMemory := "lots"
+	podSpec := spov1alpha1.SparkPodSpec{Memory: &badMemory}
+
+	// v1alpha1 objects are converted to v1beta1 and validated the same way.
+	app := &spov1alpha1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       spov1alpha1.SparkApplicationSpec{Driver: spov1alpha1.DriverSpec{SparkPodSpec: podSpec}},
+	}
+	review := newV1alpha1ValidationReview(t, v1alpha1SparkApplicationResource, admissionv1beta1.Create, app, nil)
+	response, err := validateSparkApplications(review)
+	assert.Nil(t, err)
+	assert.False(t, response.Allowed)
+	assert.True(t, strings.Contains(response.Result.Message, "spec.driver.memory"), response.Result.Message)
+
+	updated := app.DeepCopy()
+	updated.Finalizers = []string{"foo"}
+	review = newV1alpha1ValidationReview(t, v1alpha1SparkApplicationResource, admissionv1beta1.Update, updated, app)
+	response, err = validateSparkApplications(review)
+	assert.Nil(t, err)
+	assert.True(t, response.Allowed)
+
+	scheduledApp := &spov1alpha1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: spov1alpha1.ScheduledSparkApplicationSpec{
+			Schedule: "0 2 * * *",
+			Template: spov1alpha1.SparkApplicationSpec{Driver: spov1alpha1.DriverSpec{SparkPodSpec: podSpec}},
+		},
+	}
+	review = newV1alpha1ValidationReview(t, v1alpha1ScheduledSparkApplicationResource, admissionv1beta1.Create,
+		scheduledApp, nil)
+	response, err = validateScheduledSparkApplications(review)
+	assert.Nil(t, err)
+	assert.False(t, response.Allowed)
+	assert.True(t, strings.Contains(response.Result.Message, "spec.template.driver.memory"), response.Result.Message)
+}
+
+func newV1alpha1ValidationReview(
+	t *testing.T,
+	resource metav1.GroupVersionResource,
+	operation admissionv1beta1.Operation,
+	app, oldApp interface{}) *admissionv1beta1.AdmissionReview {
+	raw, err := json.Marshal(app)
+	assert.Nil(t, err)
+	review := &admissionv1beta1.AdmissionReview{
+		Request: &admissionv1beta1.AdmissionRequest{
+			Resource:  resource,
+			Operation: operation,
+			Object:    runtime.RawExtension{Raw: raw},
+			Namespace: "default",
+		},
+	}
+	if oldApp != nil {
+		oldRaw, err := json.Marshal(oldApp)
+		assert.Nil(t, err)
+		review.Request.OldObject = runtime.RawExtension{Raw: oldRaw}
+	}
+	return review
+}
+
+func newScheduledValidationReview(
+	t *testing.T,
+	operation admissionv1beta1.Operation,
+	app, oldApp *spov1beta1.ScheduledSparkApplication) *admissionv1beta1.AdmissionReview {
+	raw, err := json.Marshal(app)
+	assert.Nil(t, err)
+	review := &admissionv1beta1.AdmissionReview{
+		Request: &admissionv1beta1.AdmissionRequest{
+			Resource:  scheduledSparkApplicationResource,
+			Operation: operation,
+			Object:    runtime.RawExtension{Raw: raw},
+			Namespace: "default",
+		},
+	}
+	if oldApp != nil {
+		oldRaw, err := json.Marshal(oldApp)
+		assert.Nil(t, err)
+		review.Request.OldObject = runtime.RawExtension{Raw: oldRaw}
+	}
+	return review
+}
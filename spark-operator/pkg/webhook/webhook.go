@@ -40,6 +40,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	crdapi "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io"
+	crdv1alpha1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1alpha1"
 	crdv1beta1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	crinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
 	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
@@ -49,8 +50,10 @@ import (
 )
 
 const (
-	webhookName      = "webhook.sparkoperator.k8s.io"
-	quotaWebhookName = "quotaenforcer.sparkoperator.k8s.io"
+	webhookName           = "webhook.sparkoperator.k8s.io"
+	quotaWebhookName      = "quotaenforcer.sparkoperator.k8s.io"
+	validationWebhookName = "validation.sparkoperator.k8s.io"
+	validationPath        = "/validate"
 )
 
 var podResource = metav1.GroupVersionResource{
@@ -71,6 +74,20 @@ var scheduledSparkApplicationResource = metav1.GroupVersionResource{
 	Resource: "scheduledsparkapplications",
 }
 
+// The v1alpha1 resources are served if the conversion webhook is enabled. Objects of them are converted to v1beta1
+// before being admitted, as the admission API in use has no way to ask the API server to do so.
+var v1alpha1SparkApplicationResource = metav1.GroupVersionResource{
+	Group:    crdapi.GroupName,
+	Version:  crdv1alpha1.Version,
+	Resource: sparkApplicationResource.Resource,
+}
+
+var v1alpha1ScheduledSparkApplicationResource = metav1.GroupVersionResource{
+	Group:    crdapi.GroupName,
+	Version:  crdv1alpha1.Version,
+	Resource: scheduledSparkApplicationResource.Resource,
+}
+
 // WebHook encapsulates things needed to run the webhook.
 type WebHook struct {
 	clientset                      kubernetes.Interface
@@ -79,6 +96,7 @@ type WebHook struct {
 	server                         *http.Server
 	certProvider                   *certProvider
 	serviceRef                     *v1beta1.ServiceReference
+	validationServiceRef           *v1beta1.ServiceReference
 	failurePolicy                  v1beta1.FailurePolicyType
 	selector                       *metav1.LabelSelector
 	sparkJobNamespace              string
@@ -142,12 +160,19 @@ func New(
 		Name:      userConfig.webhookServiceName,
 		Path:      &path,
 	}
+	validatePath := validationPath
+	validationServiceRef := &v1beta1.ServiceReference{
+		Namespace: userConfig.webhookServiceNamespace,
+		Name:      userConfig.webhookServiceName,
+		Path:      &validatePath,
+	}
 	hook := &WebHook{
 		clientset:                      clientset,
 		informerFactory:                informerFactory,
 		lister:                         informerFactory.Sparkoperator().V1beta1().SparkApplications().Lister(),
 		certProvider:                   cert,
 		serviceRef:                     serviceRef,
+		validationServiceRef:           validationServiceRef,
 		sparkJobNamespace:              jobNamespace,
 		deregisterOnExit:               deregisterOnExit,
 		failurePolicy:                  arv1beta1.Ignore,
@@ -174,6 +199,8 @@ func New(
 
 	mux := http.NewServeMux()
 	mux.HandleFunc(path, hook.serve)
+	mux.HandleFunc(validationPath, hook.serveValidation)
+	mux.HandleFunc(conversionPath, hook.serveConversion)
 	hook.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", userConfig.webhookPort),
 		Handler: mux,
@@ -241,54 +268,95 @@ func (wh *WebHook) Stop() error {
 
 func (wh *WebHook) serve(w http.ResponseWriter, r *http.Request) {
 	glog.V(2).Info("Serving admission request")
+	review, ok := readAdmissionReview(w, r)
+	if !ok {
+		return
+	}
+	var whErr error
+	var reviewResponse *admissionv1beta1.AdmissionResponse
+	switch review.Request.Resource {
+	case podResource:
+		reviewResponse, whErr = mutatePods(review, wh.lister, wh.sparkJobNamespace)
+	case sparkApplicationResource, v1alpha1SparkApplicationResource:
+		if !wh.enableResourceQuotaEnforcement {
+			unexpectedResourceType(w, review.Request.Resource.String())
+			return
+		}
+		reviewResponse, whErr = admitSparkApplications(review, wh.resourceQuotaEnforcer)
+	case scheduledSparkApplicationResource, v1alpha1ScheduledSparkApplicationResource:
+		if !wh.enableResourceQuotaEnforcement {
+			unexpectedResourceType(w, review.Request.Resource.String())
+			return
+		}
+		reviewResponse, whErr = admitScheduledSparkApplications(review, wh.resourceQuotaEnforcer)
+	default:
+		unexpectedResourceType(w, review.Request.Resource.String())
+		return
+	}
+	writeAdmissionResponse(w, review, reviewResponse, whErr)
+}
+
+func (wh *WebHook) serveValidation(w http.ResponseWriter, r *http.Request) {
+	glog.V(2).Info("Serving validation request")
+	review, ok := readAdmissionReview(w, r)
+	if !ok {
+		return
+	}
+	var whErr error
+	var reviewResponse *admissionv1beta1.AdmissionResponse
+	switch review.Request.Resource {
+	case sparkApplicationResource, v1alpha1SparkApplicationResource:
+		reviewResponse, whErr = validateSparkApplications(review)
+	case scheduledSparkApplicationResource, v1alpha1ScheduledSparkApplicationResource:
+		reviewResponse, whErr = validateScheduledSparkApplications(review)
+	default:
+		unexpectedResourceType(w, review.Request.Resource.String())
+		return
+	}
+	writeAdmissionResponse(w, review, reviewResponse, whErr)
+}
+
+// readAdmissionReview decodes the AdmissionReview in the request. An error response is written if it fails.
+func readAdmissionReview(w http.ResponseWriter, r *http.Request) (*admissionv1beta1.AdmissionReview, bool) {
 	var body []byte
 	if r.Body != nil {
 		data, err := ioutil.ReadAll(r.Body)
 		if err != nil {
 			internalError(w, fmt.Errorf("failed to read the request body"))
-			return
+			return nil, false
 		}
 		body = data
 	}
 
 	if len(body) == 0 {
 		denyRequest(w, "empty request body", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	contentType := r.Header.Get("Content-Type")
 	if contentType != "application/json" {
 		denyRequest(w, "invalid Content-Type, expected `application/json`", http.StatusUnsupportedMediaType)
-		return
+		return nil, false
 	}
 
 	review := &admissionv1beta1.AdmissionReview{}
 	deserializer := codecs.UniversalDeserializer()
 	if _, _, err := deserializer.Decode(body, nil, review); err != nil {
 		internalError(w, err)
-		return
+		return nil, false
 	}
-	var whErr error
-	var reviewResponse *admissionv1beta1.AdmissionResponse
-	switch review.Request.Resource {
-	case podResource:
-		reviewResponse, whErr = mutatePods(review, wh.lister, wh.sparkJobNamespace)
-	case sparkApplicationResource:
-		if !wh.enableResourceQuotaEnforcement {
-			unexpectedResourceType(w, review.Request.Resource.String())
-			return
-		}
-		reviewResponse, whErr = admitSparkApplications(review, wh.resourceQuotaEnforcer)
-	case scheduledSparkApplicationResource:
-		if !wh.enableResourceQuotaEnforcement {
-			unexpectedResourceType(w, review.Request.Resource.String())
-			return
-		}
-		reviewResponse, whErr = admitScheduledSparkApplications(review, wh.resourceQuotaEnforcer)
-	default:
-		unexpectedResourceType(w, review.Request.Resource.String())
-		return
+	if review.Request == nil {
+		denyRequest(w, "missing admission request", http.StatusBadRequest)
+		return nil, false
 	}
+	return review, true
+}
+
+func writeAdmissionResponse(
+	w http.ResponseWriter,
+	review *admissionv1beta1.AdmissionReview,
+	reviewResponse *admissionv1beta1.AdmissionResponse,
+	whErr error) {
 	if whErr != nil {
 		internalError(w, whErr)
 		return
@@ -297,9 +365,7 @@ func (wh *WebHook) serve(w http.ResponseWriter, r *http.Request) {
 	response := admissionv1beta1.AdmissionReview{}
 	if reviewResponse != nil {
 		response.Response = reviewResponse
-		if review.Request != nil {
-			response.Response.UID = review.Request.UID
-		}
+		response.Response.UID = review.Request.UID
 	}
 
 	resp, err := json.Marshal(response)
@@ -364,12 +430,12 @@ func (wh *WebHook) selfRegistration(webhookConfigName string) error {
 		},
 	}
 
-	validatingRules := []v1beta1.RuleWithOperations{
+	sparkApplicationRules := []v1beta1.RuleWithOperations{
 		{
 			Operations: []v1beta1.OperationType{v1beta1.Create, v1beta1.Update},
 			Rule: v1beta1.Rule{
 				APIGroups:   []string{crdapi.GroupName},
-				APIVersions: []string{crdv1beta1.Version},
+				APIVersions: []string{crdv1beta1.Version, crdv1alpha1.Version},
 				Resources:   []string{sparkApplicationResource.Resource, scheduledSparkApplicationResource.Resource},
 			},
 		},
@@ -386,11 +452,11 @@ func (wh *WebHook) selfRegistration(webhookConfigName string) error {
 		NamespaceSelector: wh.selector,
 	}
 
-	validatingWebhook := v1beta1.Webhook{
-		Name:  quotaWebhookName,
-		Rules: validatingRules,
+	validationWebhook := v1beta1.Webhook{
+		Name:  validationWebhookName,
+		Rules: sparkApplicationRules,
 		ClientConfig: v1beta1.WebhookClientConfig{
-			Service:  wh.serviceRef,
+			Service:  wh.validationServiceRef,
 			CABundle: caCert,
 		},
 		FailurePolicy:     &wh.failurePolicy,
@@ -398,7 +464,20 @@ func (wh *WebHook) selfRegistration(webhookConfigName string) error {
 	}
 
 	mutatingWebhooks := []v1beta1.Webhook{mutatingWebhook}
-	validatingWebhooks := []v1beta1.Webhook{validatingWebhook}
+	validatingWebhooks := []v1beta1.Webhook{validationWebhook}
+	if wh.enableResourceQuotaEnforcement {
+		quotaWebhook := v1beta1.Webhook{
+			Name:  quotaWebhookName,
+			Rules: sparkApplicationRules,
+			ClientConfig: v1beta1.WebhookClientConfig{
+				Service:  wh.serviceRef,
+				CABundle: caCert,
+			},
+			FailurePolicy:     &wh.failurePolicy,
+			NamespaceSelector: wh.selector,
+		}
+		validatingWebhooks = append(validatingWebhooks, quotaWebhook)
+	}
 
 	mutatingExisting, mutatingGetErr := mwcClient.Get(webhookConfigName, metav1.GetOptions{})
 	if mutatingGetErr != nil {
@@ -427,32 +506,30 @@ func (wh *WebHook) selfRegistration(webhookConfigName string) error {
 		}
 	}
 
-	if wh.enableResourceQuotaEnforcement {
-		validatingExisting, validatingGetErr := vwcClient.Get(webhookConfigName, metav1.GetOptions{})
-		if validatingGetErr != nil {
-			if !errors.IsNotFound(validatingGetErr) {
-				return validatingGetErr
-			}
-			// Create case.
-			glog.Info("Creating a ValidatingWebhookConfiguration for the SparkApplication resource quota enforcement webhook")
-			webhookConfig := &v1beta1.ValidatingWebhookConfiguration{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: webhookConfigName,
-				},
-				Webhooks: validatingWebhooks,
-			}
-			if _, err := vwcClient.Create(webhookConfig); err != nil {
-				return err
-			}
+	validatingExisting, validatingGetErr := vwcClient.Get(webhookConfigName, metav1.GetOptions{})
+	if validatingGetErr != nil {
+		if !errors.IsNotFound(validatingGetErr) {
+			return validatingGetErr
+		}
+		// Create case.
+		glog.Info("Creating a ValidatingWebhookConfiguration for the SparkApplication validation webhooks")
+		webhookConfig := &v1beta1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: webhookConfigName,
+			},
+			Webhooks: validatingWebhooks,
+		}
+		if _, err := vwcClient.Create(webhookConfig); err != nil {
+			return err
+		}
 
-		} else {
-			// Update case.
-			glog.Info("Updating existing ValidatingWebhookConfiguration for the SparkApplication resource quota enforcement webhook")
-			if !equality.Semantic.DeepEqual(validatingWebhooks, validatingExisting.Webhooks) {
-				validatingExisting.Webhooks = validatingWebhooks
-				if _, err := vwcClient.Update(validatingExisting); err != nil {
-					return err
-				}
+	} else {
+		// Update case.
+		glog.Info("Updating existing ValidatingWebhookConfiguration for the SparkApplication validation webhooks")
+		if !equality.Semantic.DeepEqual(validatingWebhooks, validatingExisting.Webhooks) {
+			validatingExisting.Webhooks = validatingWebhooks
+			if _, err := vwcClient.Update(validatingExisting); err != nil {
+				return err
 			}
 		}
 	}
@@ -462,23 +539,20 @@ func (wh *WebHook) selfRegistration(webhookConfigName string) error {
 func (wh *WebHook) selfDeregistration(webhookConfigName string) error {
 	mutatingConfigs := wh.clientset.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
 	validatingConfigs := wh.clientset.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
-	if wh.enableResourceQuotaEnforcement {
-		err := validatingConfigs.Delete(webhookConfigName, metav1.NewDeleteOptions(0))
-		if err != nil {
-			return err
-		}
+	err := validatingConfigs.Delete(webhookConfigName, metav1.NewDeleteOptions(0))
+	if err != nil {
+		return err
 	}
 	return mutatingConfigs.Delete(webhookConfigName, metav1.NewDeleteOptions(0))
 }
 
 func admitSparkApplications(review *admissionv1beta1.AdmissionReview, enforcer resourceusage.ResourceQuotaEnforcer) (*admissionv1beta1.AdmissionResponse, error) {
-	if review.Request.Resource != sparkApplicationResource {
+	if review.Request.Resource != sparkApplicationResource && review.Request.Resource != v1alpha1SparkApplicationResource {
 		return nil, fmt.Errorf("expected resource to be %s, got %s", sparkApplicationResource, review.Request.Resource)
 	}
 
-	raw := review.Request.Object.Raw
-	app := &crdv1beta1.SparkApplication{}
-	if err := json.Unmarshal(raw, app); err != nil {
+	app, err := decodeSparkApplication(review.Request.Object.Raw, review.Request.Resource)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal a SparkApplication from the raw data in the admission request: %v", err)
 	}
 
@@ -497,13 +571,13 @@ func admitSparkApplications(review *admissionv1beta1.AdmissionReview, enforcer r
 }
 
 func admitScheduledSparkApplications(review *admissionv1beta1.AdmissionReview, enforcer resourceusage.ResourceQuotaEnforcer) (*admissionv1beta1.AdmissionResponse, error) {
-	if review.Request.Resource != scheduledSparkApplicationResource {
+	if review.Request.Resource != scheduledSparkApplicationResource &&
+		review.Request.Resource != v1alpha1ScheduledSparkApplicationResource {
 		return nil, fmt.Errorf("expected resource to be %s, got %s", scheduledSparkApplicationResource, review.Request.Resource)
 	}
 
-	raw := review.Request.Object.Raw
-	app := &crdv1beta1.ScheduledSparkApplication{}
-	if err := json.Unmarshal(raw, app); err != nil {
+	app, err := decodeScheduledSparkApplication(review.Request.Object.Raw, review.Request.Resource)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal a ScheduledSparkApplication from the raw data in the admission request: %v", err)
 	}
 
@@ -548,7 +622,17 @@ func mutatePods(
 		return nil, fmt.Errorf("failed to get SparkApplication %s/%s: %v", review.Request.Namespace, appName, err)
 	}
 
-	patchOps := patchSparkPod(pod, app)
+	patchOps, err := patchSparkPod(pod, app)
+	if err != nil {
+		// The pod is denied rather than created without the customizations the application asks for.
+		glog.Errorf("Failed to patch pod %s in namespace %s: %v", pod.Name, review.Request.Namespace, err)
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Message: err.Error(),
+			Code:    400,
+		}
+		return response, nil
+	}
 	if len(patchOps) > 0 {
 		glog.V(2).Infof("Pod %s in namespace %s is subject to mutation", pod.GetObjectMeta().GetName(), review.Request.Namespace)
 		patchBytes, err := json.Marshal(patchOps)
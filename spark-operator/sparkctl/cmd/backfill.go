@@ -0,0 +1,168 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+var BackfillStart string
+var BackfillEnd string
+var BackfillMaxParallel int32
+var CancelBackfill bool
+
+var backfillCmd = &cobra.Command{
+	Use:   "backfill <name>",
+	Short: "Backfill a ScheduledSparkApplication over a past time range",
+	Long: `Request runs of a ScheduledSparkApplication with a given name for the times in a past time range its
+schedule was due at, cancel the backfill, or check its progress if neither is requested`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "must specify a ScheduledSparkApplication name")
+			return
+		}
+
+		if (BackfillStart == "") != (BackfillEnd == "") {
+			fmt.Fprintln(os.Stderr, "must specify both --start and --end")
+			return
+		}
+
+		if CancelBackfill && BackfillStart != "" {
+			fmt.Fprintln(os.Stderr, "--cancel cannot be used with --start and --end")
+			return
+		}
+
+		crdClientset, err := getSparkApplicationClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
+			return
+		}
+
+		if err := doBackfill(args[0], crdClientset); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to backfill ScheduledSparkApplication %s: %v\n", args[0], err)
+		}
+	},
+}
+
+func init() {
+	backfillCmd.Flags().StringVar(&BackfillStart, "start", "",
+		"the start of the time range to backfill in RFC3339 format, inclusive")
+	backfillCmd.Flags().StringVar(&BackfillEnd, "end", "",
+		"the end of the time range to backfill in RFC3339 format, inclusive")
+	backfillCmd.Flags().Int32Var(&BackfillMaxParallel, "max-parallel", 1,
+		"the maximum number of runs of the backfill running at the same time")
+	backfillCmd.Flags().BoolVar(&CancelBackfill, "cancel", false,
+		"whether to cancel the backfill, leaving the runs that are already created running")
+}
+
+func doBackfill(name string, crdClientset crdclientset.Interface) error {
+	sapp, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get ScheduledSparkApplication %s: %v", name, err)
+	}
+
+	switch {
+	case CancelBackfill:
+		sapp.Spec.Backfill = nil
+		if _, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(Namespace).Update(sapp); err != nil {
+			return err
+		}
+		fmt.Printf("backfill of ScheduledSparkApplication \"%s\" cancelled\n", name)
+	case BackfillStart != "":
+		backfill, err := newBackfillSpec(BackfillStart, BackfillEnd, BackfillMaxParallel)
+		if err != nil {
+			return err
+		}
+		sapp.Spec.Backfill = backfill
+		if _, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(Namespace).Update(sapp); err != nil {
+			return err
+		}
+		fmt.Printf("backfill of ScheduledSparkApplication \"%s\" from %s to %s requested\n", name, BackfillStart,
+			BackfillEnd)
+	default:
+		printBackfillStatus(sapp)
+	}
+
+	return nil
+}
+
+func newBackfillSpec(start string, end string, maxParallel int32) (*v1beta1.BackfillSpec, error) {
+	startTime, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse start time %s: %v", start, err)
+	}
+	endTime, err := time.Parse(time.RFC3339, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse end time %s: %v", end, err)
+	}
+	if endTime.Before(startTime) {
+		return nil, fmt.Errorf("end time %s is before start time %s", end, start)
+	}
+	if maxParallel <= 0 {
+		return nil, fmt.Errorf("the maximum number of parallel runs must be positive")
+	}
+	// The request time makes the backfill start again if the same time range was backfilled before.
+	return &v1beta1.BackfillSpec{
+		StartTime:   metav1.NewTime(startTime),
+		EndTime:     metav1.NewTime(endTime),
+		MaxParallel: &maxParallel,
+		RequestTime: metav1.Now(),
+	}, nil
+}
+
+func printBackfillStatus(sapp *v1beta1.ScheduledSparkApplication) {
+	backfill := sapp.Status.Backfill
+	if backfill == nil {
+		if sapp.Spec.Backfill != nil {
+			fmt.Println("backfill requested but not started yet")
+		} else {
+			fmt.Println("no backfill requested")
+		}
+		return
+	}
+
+	fmt.Println("backfill state:")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"State", "Start Time", "End Time", "Total Runs", "Created", "Completed", "Failed",
+		"Running", "Next Run Time"})
+	nextRunTime := "N.A."
+	if !backfill.NextRunTime.IsZero() {
+		nextRunTime = backfill.NextRunTime.Format(time.RFC3339)
+	}
+	table.Append([]string{
+		string(backfill.State),
+		backfill.StartTime.Format(time.RFC3339),
+		backfill.EndTime.Format(time.RFC3339),
+		fmt.Sprintf("%d", backfill.TotalRuns),
+		fmt.Sprintf("%d", backfill.CreatedRuns),
+		fmt.Sprintf("%d", backfill.CompletedRuns),
+		fmt.Sprintf("%d", backfill.FailedRuns),
+		fmt.Sprintf("%d", len(backfill.ActiveRunNames)),
+		nextRunTime,
+	})
+	table.Render()
+}
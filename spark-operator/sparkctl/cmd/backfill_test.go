@@ -0,0 +1,42 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBackfillSpec(t *testing.T) {
+	backfill, err := newBackfillSpec("2019-05-01T00:00:00Z", "2019-05-14T00:00:00-04:00", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC).Equal(backfill.StartTime.Time))
+	assert.True(t, time.Date(2019, 5, 14, 4, 0, 0, 0, time.UTC).Equal(backfill.EndTime.Time))
+	assert.Equal(t, int32(3), *backfill.MaxParallel)
+	assert.False(t, backfill.RequestTime.IsZero())
+
+	_, err = newBackfillSpec("2019-05-01", "2019-05-14T00:00:00Z", 1)
+	assert.Error(t, err)
+	_, err = newBackfillSpec("2019-05-14T00:00:00Z", "2019-05-01T00:00:00Z", 1)
+	assert.Error(t, err)
+	_, err = newBackfillSpec("2019-05-01T00:00:00Z", "2019-05-14T00:00:00Z", 0)
+	assert.Error(t, err)
+}
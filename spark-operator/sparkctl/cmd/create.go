@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"time"
 	"unicode/utf8"
 
 	"github.com/google/go-cloud/blob"
@@ -37,6 +38,8 @@ import (
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
 const bufferSize = 1024
@@ -48,6 +51,7 @@ var UploadToRegion string
 var Public bool
 var Override bool
 var From string
+var RunTime string
 
 var createCmd = &cobra.Command{
 	Use:   "create <yaml file>",
@@ -64,6 +68,11 @@ var createCmd = &cobra.Command{
 			return
 		}
 
+		if RunTime != "" && From == "" {
+			fmt.Fprintln(os.Stderr, "--run-time can only be used with --from")
+			return
+		}
+
 		kubeClient, err := getKubeClient()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "failed to get Kubernetes client: %v\n", err)
@@ -101,6 +110,8 @@ func init() {
 		"whether to override remote files with the same names")
 	createCmd.Flags().StringVarP(&From, "from", "f", "",
 		"the name of ScheduledSparkApplication from which a forced SparkApplication run is created")
+	createCmd.Flags().StringVar(&RunTime, "run-time", "",
+		"the time in RFC3339 format the forced run is scheduled at, defaulting to the current time")
 }
 
 func createFromYaml(yamlFile string, kubeClient clientset.Interface, crdClient crdclientset.Interface) error {
@@ -122,10 +133,45 @@ func createFromScheduledSparkApplication(name string, kubeClient clientset.Inter
 		return fmt.Errorf("failed to get ScheduledSparkApplication %s: %v", From, err)
 	}
 
+	now := time.Now()
+	runTime := now
+	if RunTime != "" {
+		if runTime, err = time.Parse(time.RFC3339, RunTime); err != nil {
+			return fmt.Errorf("failed to parse run time %s: %v", RunTime, err)
+		}
+	}
+
+	app, err := newRunFromScheduledSparkApplication(sapp, name, runTime, now)
+	if err != nil {
+		return err
+	}
+
+	if err := createSparkApplication(app, kubeClient, crdClient); err != nil {
+		return fmt.Errorf("failed to create SparkApplication %s: %v", app.Name, err)
+	}
+
+	return nil
+}
+
+// newRunFromScheduledSparkApplication returns a SparkApplication for a run of the ScheduledSparkApplication scheduled
+// at runTime and created at now, with the templates in its spec expanded like the operator does for scheduled runs.
+func newRunFromScheduledSparkApplication(
+	sapp *v1beta1.ScheduledSparkApplication,
+	name string,
+	runTime time.Time,
+	now time.Time) (*v1beta1.SparkApplication, error) {
+	_, location, err := util.GetScheduleLocation(sapp.Spec.Schedule, sapp.Spec.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the time zone of ScheduledSparkApplication %s: %v", sapp.Name, err)
+	}
+
 	app := &v1beta1.SparkApplication{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: Namespace,
 			Name:      name,
+			Annotations: map[string]string{
+				config.ScheduledTimeAnnotation: runTime.UTC().Format(time.RFC3339),
+			},
 			OwnerReferences: []metav1.OwnerReference{
 				{
 					APIVersion: v1beta1.SchemeGroupVersion.String(),
@@ -138,11 +184,19 @@ func createFromScheduledSparkApplication(name string, kubeClient clientset.Inter
 		Spec: *sapp.Spec.Template.DeepCopy(),
 	}
 
-	if err := createSparkApplication(app, kubeClient, crdClient); err != nil {
-		return fmt.Errorf("failed to create SparkApplication %s: %v", app.Name, err)
+	if sapp.Spec.ExpandTemplates != nil && *sapp.Spec.ExpandTemplates {
+		data := util.RunTemplateData{
+			ScheduledTime:    runTime.In(location),
+			ActualTime:       now.In(location),
+			RunName:          name,
+			ScheduledAppName: sapp.Name,
+		}
+		if err := util.ExpandRunTemplates(&app.Spec, data); err != nil {
+			return nil, fmt.Errorf("failed to expand the templates of ScheduledSparkApplication %s: %v", sapp.Name, err)
+		}
 	}
 
-	return nil
+	return app, nil
 }
 
 func createSparkApplication(app *v1beta1.SparkApplication, kubeClient clientset.Interface, crdClient crdclientset.Interface) error {
@@ -19,10 +19,13 @@ package cmd
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
 )
 
 func TestIsLocalFile(t *testing.T) {
@@ -279,6 +282,45 @@ func TestLoadFromYAML(t *testing.T) {
 	assert.Equal(t, int(*app.Spec.Executor.Instances), 1)
 }
 
+func TestNewRunFromScheduledSparkApplication(t *testing.T) {
+	expandTemplates := true
+	sapp := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "daily", Namespace: "default"},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Schedule: "0 1 * * *",
+			TimeZone: "America/New_York",
+			Template: v1beta1.SparkApplicationSpec{
+				Arguments: []string{"--date={{date .ScheduledTime}}", "--run={{.RunName}}"},
+				SparkConf: map[string]string{"spark.app.name": "{{.ScheduledAppName}}"},
+			},
+			ExpandTemplates: &expandTemplates,
+		},
+	}
+
+	runTime := time.Date(2019, 5, 2, 5, 0, 0, 0, time.UTC)
+	app, err := newRunFromScheduledSparkApplication(sapp, "daily-backfill", runTime, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"--date=2019-05-02", "--run=daily-backfill"}, app.Spec.Arguments)
+	assert.Equal(t, "daily", app.Spec.SparkConf["spark.app.name"])
+	assert.Equal(t, "2019-05-02T05:00:00Z", app.Annotations[config.ScheduledTimeAnnotation])
+	assert.Equal(t, "daily", app.OwnerReferences[0].Name)
+	assert.Equal(t, "--date={{date .ScheduledTime}}", sapp.Spec.Template.Arguments[0])
+
+	sapp.Spec.Template.Arguments = []string{"{{.LogicalDate}}"}
+	_, err = newRunFromScheduledSparkApplication(sapp, "daily-backfill", runTime, time.Now())
+	assert.Error(t, err)
+
+	// Values are used as they are unless template expansion is enabled.
+	expandTemplates = false
+	app, err = newRunFromScheduledSparkApplication(sapp, "daily-backfill", runTime, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"{{.LogicalDate}}"}, app.Spec.Arguments)
+}
+
 func TestHandleHadoopConfiguration(t *testing.T) {
 	configMap, err := buildHadoopConfigMap("test", "testdata/hadoop-conf")
 	if err != nil {
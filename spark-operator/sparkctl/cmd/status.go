@@ -19,6 +19,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -63,7 +65,7 @@ func doStatus(name string, crdClientset crdclientset.Interface) error {
 func printStatus(app *v1beta1.SparkApplication) {
 	fmt.Println("application state:")
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"State", "Submission Age", "Completion Age", "Driver Pod", "Driver UI", "SubmissionAttempts", "ExecutionAttempts"})
+	table.SetHeader([]string{"State", "Submission Age", "Completion Age", "Driver Pod", "Driver UI", "SubmissionAttempts", "ExecutionAttempts", "Next Retry"})
 	table.Append([]string{
 		string(app.Status.AppState.State),
 		getSinceTime(app.Status.LastSubmissionAttemptTime),
@@ -72,20 +74,78 @@ func printStatus(app *v1beta1.SparkApplication) {
 		formatNotAvailable(app.Status.DriverInfo.WebUIAddress),
 		fmt.Sprintf("%v", app.Status.SubmissionAttempts),
 		fmt.Sprintf("%v", app.Status.ExecutionAttempts),
+		getUntilTime(app.Status.NextRetryTime),
 	})
 	table.Render()
 
 	if len(app.Status.ExecutorState) > 0 {
 		fmt.Println("executor state:")
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Executor Pod", "State"})
-		for executorPod, state := range app.Status.ExecutorState {
-			table.Append([]string{executorPod, string(state)})
+		table.SetHeader([]string{"Executor Pod", "Executor ID", "State", "Node", "Pod IP", "Start Age", "Termination Age",
+			"Exit Code", "Termination Reason", "Restarts"})
+		for _, executorPod := range getSortedExecutorPods(app) {
+			table.Append(getExecutorRow(app, executorPod))
 		}
 		table.Render()
+		if app.Status.OmittedExecutors > 0 {
+			fmt.Printf("details of %d terminated executors omitted\n", app.Status.OmittedExecutors)
+		}
 	}
 
 	if app.Status.AppState.ErrorMessage != "" {
 		fmt.Printf("\napplication error message: %s\n", app.Status.AppState.ErrorMessage)
 	}
 }
+
+// getSortedExecutorPods returns the names of the executor pods of the application ordered by executor ID, followed
+// by the executors the details of which are not recorded ordered by name.
+func getSortedExecutorPods(app *v1beta1.SparkApplication) []string {
+	var pods []string
+	for executorPod := range app.Status.ExecutorState {
+		pods = append(pods, executorPod)
+	}
+	executorID := func(executorPod string) (int, bool) {
+		info, ok := app.Status.Executors[executorPod]
+		if !ok {
+			return 0, false
+		}
+		id, err := strconv.Atoi(info.ExecutorID)
+		return id, err == nil
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		a, aOK := executorID(pods[i])
+		b, bOK := executorID(pods[j])
+		if aOK != bOK {
+			return aOK
+		}
+		if aOK && a != b {
+			return a < b
+		}
+		return pods[i] < pods[j]
+	})
+	return pods
+}
+
+func getExecutorRow(app *v1beta1.SparkApplication, executorPod string) []string {
+	info, ok := app.Status.Executors[executorPod]
+	if !ok {
+		return []string{executorPod, "N.A.", string(app.Status.ExecutorState[executorPod]), "N.A.", "N.A.", "N.A.",
+			"N.A.", "N.A.", "N.A.", "N.A."}
+	}
+	exitCode := "N.A."
+	if info.ExitCode != nil {
+		exitCode = fmt.Sprintf("%d", *info.ExitCode)
+	}
+	return []string{
+		executorPod,
+		formatNotAvailable(info.ExecutorID),
+		string(app.Status.ExecutorState[executorPod]),
+		formatNotAvailable(info.NodeName),
+		formatNotAvailable(info.PodIP),
+		getSinceTime(info.StartTime),
+		getSinceTime(info.TerminationTime),
+		exitCode,
+		formatNotAvailable(info.TerminationReason),
+		fmt.Sprintf("%d", info.RestartCount),
+	}
+}
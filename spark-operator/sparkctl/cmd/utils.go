@@ -31,6 +31,18 @@ func getSinceTime(timestamp metav1.Time) string {
 	return duration.ShortHumanDuration(time.Since(timestamp.Time))
 }
 
+func getUntilTime(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "N.A."
+	}
+
+	until := time.Until(timestamp.Time)
+	if until < 0 {
+		until = 0
+	}
+	return duration.ShortHumanDuration(until)
+}
+
 func formatNotAvailable(info string) string {
 	if info == "" {
 		return "N.A."